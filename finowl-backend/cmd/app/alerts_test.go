@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"finowl-backend/pkg/alerts"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var alertRuleRowColumns = []string{
+	"id", "name", "rule_type", "ticker", "category", "threshold", "tier", "window_seconds", "cooldown_seconds",
+	"webhook_url", "secret", "enabled", "created_at", "updated_at",
+}
+
+func TestRequireAdmin(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusTeapot) }
+
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		expected      int
+	}{
+		{"admin API disabled", "", "Bearer anything", http.StatusServiceUnavailable},
+		{"missing token", "t0ken", "", http.StatusUnauthorized},
+		{"wrong token", "t0ken", "Bearer nope", http.StatusUnauthorized},
+		{"valid token", "t0ken", "Bearer t0ken", http.StatusTeapot},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &server{adminToken: tt.token}
+			req := httptest.NewRequest("GET", "/api/v0/alerts/rules", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+
+			server.requireAdmin(handler)(w, req)
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
+
+func TestCreateAlertRuleHandler(t *testing.T) {
+	server, mock := createTestServer(t)
+	defer server.db.Close()
+
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("INSERT INTO alert_rules").
+		WithArgs("AIXBT pump", alerts.ScoreRise, "AIXBT", "", 50.0, 0, 3600, defaultAlertCooldown,
+			"https://example.com/hook", sqlmock.AnyArg(), true).
+		WillReturnRows(sqlmock.NewRows(alertRuleRowColumns).AddRow(
+			1, "AIXBT pump", "score_rise", "AIXBT", "", 50.0, 0, 3600, defaultAlertCooldown,
+			"https://example.com/hook", "generated", true, now, now))
+
+	body := `{"name": "AIXBT pump", "type": "score_rise", "ticker": "AIXBT", "threshold": 50, "window_seconds": 3600,
+		"webhook_url": "https://example.com/hook"}`
+	req := httptest.NewRequest("POST", "/api/v0/alerts/rules", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	server.createAlertRuleHandler(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, float64(1), resp["id"])
+	assert.Equal(t, "generated", resp["secret"])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateAlertRuleHandlerValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"malformed", `{"name": `},
+		{"unknown field", `{"name": "x", "type": "category_entered", "category": "High Alpha", "webhook_url": "https://example.com", "color": "red"}`},
+		{"unknown category", `{"name": "x", "type": "category_entered", "category": "Moon", "webhook_url": "https://example.com"}`},
+		{"missing webhook", `{"name": "x", "type": "category_entered", "category": "High Alpha"}`},
+		{"short secret", `{"name": "x", "type": "category_entered", "category": "High Alpha", "webhook_url": "https://example.com", "secret": "abc"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, mock := createTestServer(t)
+			defer server.db.Close()
+
+			req := httptest.NewRequest("POST", "/api/v0/alerts/rules", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			server.createAlertRuleHandler(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), `"error"`)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetAlertRuleHandlerHidesSecret(t *testing.T) {
+	server, mock := createTestServer(t)
+	defer server.db.Close()
+
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT id, name, rule_type").WithArgs(int64(4)).
+		WillReturnRows(sqlmock.NewRows(alertRuleRowColumns).AddRow(
+			4, "alpha", "category_entered", "", "High Alpha", 0.0, 0, 0, 3600,
+			"https://example.com/hook", "s3cret-s3cret-s3cret", true, now, now))
+
+	req := httptest.NewRequest("GET", "/api/v0/alerts/rules/4", nil)
+	req.SetPathValue("id", "4")
+	w := httptest.NewRecorder()
+
+	server.getAlertRuleHandler(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "s3cret")
+
+	var rule alerts.Rule
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rule))
+	assert.Equal(t, "High Alpha", rule.Category)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteAlertRuleHandlerNotFound(t *testing.T) {
+	server, mock := createTestServer(t)
+	defer server.db.Close()
+
+	mock.ExpectExec("DELETE FROM alert_rules").WithArgs(int64(9)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	req := httptest.NewRequest("DELETE", "/api/v0/alerts/rules/9", nil)
+	req.SetPathValue("id", "9")
+	w := httptest.NewRecorder()
+
+	server.deleteAlertRuleHandler(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAlertDeliveriesHandler(t *testing.T) {
+	server, mock := createTestServer(t)
+	defer server.db.Close()
+
+	created := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	delivered := created.Add(2 * time.Second)
+	mock.ExpectQuery("SELECT id, rule_id, ticker, payload").WithArgs(int64(4), defaultAlertDeliveries).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "rule_id", "ticker", "payload", "status", "attempts", "response_status", "last_error", "created_at", "delivered_at",
+		}).
+			AddRow(2, 4, "AIXBT", []byte(`{"ticker_symbol": "AIXBT"}`), "delivered", 2, 200, "", created, delivered).
+			AddRow(1, 4, "ETH", []byte(`{"ticker_symbol": "ETH"}`), "failed", 5, 503, "webhook responded 503", created, nil))
+
+	req := httptest.NewRequest("GET", "/api/v0/alerts/rules/4/deliveries", nil)
+	req.SetPathValue("id", "4")
+	w := httptest.NewRecorder()
+
+	server.getAlertDeliveriesHandler(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp getAlertDeliveriesHandlerResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Deliveries, 2)
+	assert.Equal(t, alerts.DeliveryDelivered, resp.Deliveries[0].Status)
+	require.NotNil(t, resp.Deliveries[0].DeliveredAt)
+	assert.Nil(t, resp.Deliveries[1].DeliveredAt)
+	assert.JSONEq(t, `{"ticker_symbol": "ETH"}`, string(resp.Deliveries[1].Payload))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
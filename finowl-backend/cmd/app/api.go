@@ -7,12 +7,14 @@ import (
 	"errors"
 	"finowl-backend/ai"
 	"finowl-backend/pkg/analyzer"
+	"finowl-backend/pkg/events"
 	"finowl-backend/pkg/mindshare"
 	"finowl-backend/pkg/ticker"
 	"fmt"
 	"log"
 	"log/slog"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 )
@@ -37,7 +39,7 @@ func corsMiddleware(next http.Handler) http.Handler {
 
 		if allowedOrigins[origin] {
 			w.Header().Set("Access-Control-Allow-Origin", origin)
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 			w.Header().Set("Access-Control-Allow-Credentials", "true")
 		}
@@ -51,11 +53,36 @@ func corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// tweetSummarizer produces an AI summary of a batch of tweets
+type tweetSummarizer interface {
+	AnalyzeTweets(ctx context.Context, prompt, tweets string) (string, error)
+}
+
+// summaryStore stores generated summaries, satisfied by *storer.Storer
+type summaryStore interface {
+	InsertSummary(summary *mindshare.Summary) error
+}
+
 type server struct {
 	db *sql.DB
 
-	aiClient *ai.AI
-	aiPrompt string
+	aiClient  tweetSummarizer
+	aiPrompt  string
+	aiPrompts map[string]string // category -> summary prompt
+
+	categories map[string]bool // configured channel categories, the only ones filters accept
+
+	summaryInterval time.Duration
+	summaries       summaryStore
+
+	events *events.Broker // live ticker updates, nil disables /stream
+
+	adminToken string // bearer token of the admin API, empty disables it
+
+	ingester    tweetIngester // stores ingested posts, nil disables /ingest
+	ingestToken string        // bearer token of the ingest API, empty disables it
+
+	deadLetters deadLetterQueue // messages that could not be stored, nil disables /dead-letters
 }
 
 type serverConfig struct {
@@ -67,8 +94,21 @@ type serverConfig struct {
 	sslmode    string
 
 	aiPrompt             string
+	aiPrompts            map[string]string
 	aiAPIKey             string
 	aiGenSummaryInterval time.Duration
+	summaries            summaryStore
+
+	categories map[string]bool
+
+	events *events.Broker
+
+	adminToken string
+
+	ingester    tweetIngester
+	ingestToken string
+
+	deadLetters deadLetterQueue
 }
 
 type getTickersHandlerResponse struct {
@@ -78,12 +118,36 @@ type getTickersHandlerResponse struct {
 	PageSize     int             `json:"page_size"`
 }
 
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
 type getSummaryHandlerResponse struct {
 	Summary *mindshare.Summary `json:"summary"`
 	Total   int                `json:"total"`
 }
 
-const maxPageSize = 1024
+const (
+	maxPageSize = 1024
+
+	// maxSummaryAttempts bounds how often the model is asked to produce a
+	// summary with all section markers before it is stored unparsed
+	maxSummaryAttempts = 3
+
+	// summarySettleDelay keeps the tweets stored in the last moments out of a
+	// summary window, so that a transaction still committing when the window
+	// closes is not skipped by the next one
+	summarySettleDelay = time.Minute
+
+	// maxSummaryTweets bounds the tweets of one summary so that its prompt fits
+	// the model's context. Larger windows, after an import or a downtime, are
+	// split into several summaries.
+	maxSummaryTweets = 300
+
+	// maxSummariesPerRun bounds the summaries generated per category and run,
+	// the rest of a large window is summarized by the next runs
+	maxSummariesPerRun = 10
+)
 
 var (
 	errNewServer         = errors.New("failed to create new server")
@@ -92,6 +156,9 @@ var (
 	errGetSummary        = errors.New("failed to retrieve summary")
 	errGetSummariesCount = errors.New("failed to retrieve summaries count")
 	errGetMentions       = errors.New("failed to retrieve mentions")
+	errGetTickerHistory  = errors.New("failed to retrieve ticker history")
+	errGetTicker         = errors.New("failed to retrieve ticker")
+	errGenerateSummary   = errors.New("failed to generate summary")
 )
 
 // Common helper function to process ticker rows and reduce code duplication
@@ -121,6 +188,18 @@ func processTickers(rows *sql.Rows) ([]ticker.Ticker, error) {
 	return tickers, nil
 }
 
+// writeJSONError replies with status and a JSON body describing the error
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	body, _ := json.Marshal(&errorResponse{Error: message})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if _, err := w.Write(body); err != nil {
+		slog.Error(err.Error())
+	}
+}
+
 // isValidTickerSymbol checks if a ticker symbol is valid (not a monetary value)
 func isValidTickerSymbol(symbol string) bool {
 	// Add dollar sign prefix to reuse existing monetary detection logic
@@ -140,65 +219,216 @@ func newServer(cfg serverConfig) (*server, error) {
 	}
 
 	return &server{
-		db:       db,
-		aiClient: ai.NewDeepSeekAI(cfg.aiAPIKey),
-		aiPrompt: cfg.aiPrompt,
+		db:        db,
+		aiClient:  ai.NewDeepSeekAI(cfg.aiAPIKey),
+		aiPrompt:  cfg.aiPrompt,
+		aiPrompts: cfg.aiPrompts,
+
+		categories: cfg.categories,
+
+		summaryInterval: cfg.aiGenSummaryInterval,
+		summaries:       cfg.summaries,
+
+		events: cfg.events,
+
+		adminToken: cfg.adminToken,
+
+		ingester:    cfg.ingester,
+		ingestToken: cfg.ingestToken,
+
+		deadLetters: cfg.deadLetters,
 	}, nil
 }
 
-func (s *server) generateSummary() error {
-	type tweet struct {
-		id      string
-		author  string
-		content string
+// generateSummaries produces one summary per configured category. Without
+// category prompts a single summary over all tweets is generated from aiPrompt.
+func (s *server) generateSummaries() error {
+	if len(s.aiPrompts) == 0 {
+		return s.generateSummary("", s.aiPrompt)
+	}
+
+	categories := make([]string, 0, len(s.aiPrompts))
+	for category := range s.aiPrompts {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	var errs []error
+	for _, category := range categories {
+		// aiPrompt (prompt.txt) defines the sectioned output format every summary must follow
+		prompt := s.aiPrompts[category] + "\n\n" + s.aiPrompt
+		if err := s.generateSummary(category, prompt); err != nil {
+			errs = append(errs, fmt.Errorf("category %s: %w", category, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// summaryCursor is where the next summary window of a category begins: after
+// the tweet lastTweetID stored at windowStart, or after every tweet stored at
+// windowStart when lastTweetID is not valid
+type summaryCursor struct {
+	windowStart time.Time
+	lastTweetID sql.NullString
+}
+
+// summaryWindowStart returns where the next summary window of a category
+// begins: the end of the previous summary's window, or one generation
+// interval before windowEnd when the category has never been summarized.
+func (s *server) summaryWindowStart(category string, windowEnd time.Time) (summaryCursor, error) {
+	var cursor summaryCursor
+	err := s.db.QueryRow(queryGetLastSummaryWindowEnd, category).Scan(&cursor.windowStart, &cursor.lastTweetID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return summaryCursor{windowStart: windowEnd.Add(-s.summaryInterval)}, nil
+	}
+	if err != nil {
+		return summaryCursor{}, fmt.Errorf("%w: %w", errGenerateSummary, err)
 	}
+	return cursor, nil
+}
 
-	log.Println("Started summary generation")
+// summaryTweet is a tweet to summarize
+type summaryTweet struct {
+	id         string
+	author     string
+	content    string
+	insertedAt time.Time
+}
 
-	// Query to get the latest 150 tweets
-	rows, err := s.db.Query(queryGetLatestTweets)
+// summaryTweets returns the first maxSummaryTweets tweets of a category
+// stored after cursor and until windowEnd, in insertion order
+func (s *server) summaryTweets(category string, cursor summaryCursor, windowEnd time.Time) ([]summaryTweet, error) {
+	rows, err := s.db.Query(queryGetTweetsInWindow, category, cursor.windowStart, cursor.lastTweetID, windowEnd, maxSummaryTweets)
 	if err != nil {
-		return fmt.Errorf("%w: %w", errGetTickers, err)
+		return nil, fmt.Errorf("%w: %w", errGenerateSummary, err)
 	}
 	defer rows.Close() // Ensure rows are closed after processing
 
-	tweets := strings.Builder{}
-	var firstTweetID, lastTweetID string
-	tweetCount := 0
-
+	var tweets []summaryTweet
 	for rows.Next() {
-		var t tweet
-		if err := rows.Scan(&t.id, &t.author, &t.content); err != nil {
-			return fmt.Errorf("%w: %w", errGetTickers, err)
+		var t summaryTweet
+		if err := rows.Scan(&t.id, &t.author, &t.content, &t.insertedAt); err != nil {
+			return nil, fmt.Errorf("%w: %w", errGenerateSummary, err)
+		}
+		tweets = append(tweets, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", errGenerateSummary, err)
+	}
+	return tweets, nil
+}
+
+// generateSummary summarizes every tweet of a category stored since the
+// previous summary of that category, whenever it was posted. An empty
+// category summarizes tweets of every category. Nothing is generated when no
+// new tweets arrived. A window of more than maxSummaryTweets tweets is split
+// into several summaries, each one ending at the last tweet it covers.
+func (s *server) generateSummary(category, prompt string) error {
+	log.Printf("Started summary generation (category: %q)", category)
+
+	windowEnd := time.Now().UTC().Add(-summarySettleDelay)
+	cursor, err := s.summaryWindowStart(category, windowEnd)
+	if err != nil {
+		return err
+	}
+
+	for run := 0; run < maxSummariesPerRun; run++ {
+		tweets, err := s.summaryTweets(category, cursor, windowEnd)
+		if err != nil {
+			return err
+		}
+		if len(tweets) == 0 {
+			if run == 0 {
+				log.Printf("No new tweets since %s (category: %q), skipping summary", cursor.windowStart.Format(time.RFC3339), category)
+			}
+			return nil
 		}
 
-		// Log the first tweet ID
-		if tweetCount == 0 {
-			firstTweetID = t.id
+		// A full batch leaves tweets for the next summary, which starts after the last one
+		last := tweets[len(tweets)-1]
+		end := windowEnd
+		if len(tweets) == maxSummaryTweets {
+			end = last.insertedAt
 		}
-		lastTweetID = t.id // Update last tweet ID on each iteration
 
-		tweets.WriteString(t.author)
-		tweets.WriteString(": ")
-		tweets.WriteString(t.content)
-		tweetCount++
+		if err := s.summarizeTweets(category, prompt, tweets, cursor.windowStart, end); err != nil {
+			return err
+		}
+		if len(tweets) < maxSummaryTweets {
+			break
+		}
+		cursor = summaryCursor{windowStart: end, lastTweetID: sql.NullString{String: last.id, Valid: true}}
+	}
+
+	log.Printf("Finished summary generation (category: %q)", category)
+
+	return nil
+}
+
+// summarizeTweets has the model summarize tweets and stores the summary of
+// the window they were stored in
+func (s *server) summarizeTweets(category, prompt string, tweets []summaryTweet, windowStart, windowEnd time.Time) error {
+	var b strings.Builder
+	for _, t := range tweets {
+		b.WriteString(t.author)
+		b.WriteString(": ")
+		b.WriteString(t.content)
+		b.WriteString("\n")
 	}
 
+	firstTweetID, lastTweetID := tweets[0].id, tweets[len(tweets)-1].id
+
 	// Log the number of tweets processed and the first and last tweet IDs
-	log.Printf("Processed %d tweets. First tweet ID: %s, Last tweet ID: %s", tweetCount, firstTweetID, lastTweetID)
+	log.Printf("Processed %d tweets. First tweet ID: %s, Last tweet ID: %s", len(tweets), firstTweetID, lastTweetID)
 
-	summary, err := s.aiClient.AnalyzeTweets(context.Background(), s.aiPrompt, tweets.String())
+	summary, sections, err := s.analyzeTweetsWithSections(context.Background(), prompt, b.String())
 	if err != nil {
-		return fmt.Errorf("%w: %w", errGetTickers, err)
+		return fmt.Errorf("%w: %w", errGenerateSummary, err)
 	}
 
-	if _, err := s.db.Exec(queryInsertSummary, time.Now(), summary); err != nil {
-		return fmt.Errorf("%w: %w", errGetTickers, err)
+	if err := s.summaries.InsertSummary(&mindshare.Summary{
+		Time:         time.Now().UTC(),
+		Content:      summary,
+		Category:     category,
+		FirstTweetID: firstTweetID,
+		LastTweetID:  lastTweetID,
+		TweetCount:   len(tweets),
+		WindowStart:  &windowStart,
+		WindowEnd:    &windowEnd,
+		Sections:     sections,
+	}); err != nil {
+		return fmt.Errorf("%w: %w", errGenerateSummary, err)
 	}
+	return nil
+}
 
-	log.Println("Finished summary generation")
+// analyzeTweetsWithSections asks the model for a summary until its answer
+// contains every section marker, giving up after maxSummaryAttempts. The last
+// answer is then returned with nil sections so the raw markdown is not lost.
+func (s *server) analyzeTweetsWithSections(ctx context.Context, prompt, tweets string) (string, *mindshare.SummarySections, error) {
+	var summary string
+	attemptPrompt := prompt
+
+	for attempt := 1; attempt <= maxSummaryAttempts; attempt++ {
+		var err error
+		summary, err = s.aiClient.AnalyzeTweets(ctx, attemptPrompt, tweets)
+		if err != nil {
+			return "", nil, err
+		}
 
-	return nil
+		sections, err := mindshare.ParseSummarySections(summary)
+		if err == nil {
+			return summary, sections, nil
+		}
+
+		log.Printf("Summary attempt %d/%d rejected: %v", attempt, maxSummaryAttempts, err)
+		attemptPrompt = prompt + "\n\nYour previous answer was rejected (" + err.Error() +
+			"). Wrap every section in its exact BEGIN and END marker."
+	}
+
+	log.Printf("Storing summary without sections after %d attempts", maxSummaryAttempts)
+	return summary, nil, nil
 }
 
 func RunAPIServer(cfg serverConfig) {
@@ -210,18 +440,34 @@ func RunAPIServer(cfg serverConfig) {
 	}
 
 	http.Handle("GET /api/v0/tickers", corsMiddleware(logMiddleware(http.HandlerFunc(server.getTickersHandler))))
+	http.Handle("GET /api/v0/tickers/{symbol}", corsMiddleware(logMiddleware(http.HandlerFunc(server.getTickerDetailHandler))))
+	http.Handle("GET /api/v0/tickers/{symbol}/history", corsMiddleware(logMiddleware(http.HandlerFunc(server.getTickerHistoryHandler))))
 	http.Handle("GET /api/v0/summary", corsMiddleware(logMiddleware(http.HandlerFunc(server.getSummaryHandler))))
 	http.Handle("GET /api/v0/fresh-mentions", corsMiddleware(logMiddleware(http.HandlerFunc(server.getFreshMentionsHandler))))
 	http.Handle("GET /api/v0/recent-momentum", corsMiddleware(logMiddleware(http.HandlerFunc(server.getRecentMomentumHandler))))
 	http.Handle("GET /api/v0/revived-interest", corsMiddleware(logMiddleware(http.HandlerFunc(server.getRevivedInterestHandler))))
+	http.Handle("GET /api/v0/stream", corsMiddleware(logMiddleware(http.HandlerFunc(server.getStreamHandler))))
 	http.Handle("GET /api/v0/generic-discovery", corsMiddleware(logMiddleware(http.HandlerFunc(server.getGenericDiscoveryHandler))))
 
+	// Admin API
+	http.Handle("GET /api/v0/alerts/rules", corsMiddleware(logMiddleware(server.requireAdmin(server.getAlertRulesHandler))))
+	http.Handle("POST /api/v0/alerts/rules", corsMiddleware(logMiddleware(server.requireAdmin(server.createAlertRuleHandler))))
+	http.Handle("GET /api/v0/alerts/rules/{id}", corsMiddleware(logMiddleware(server.requireAdmin(server.getAlertRuleHandler))))
+	http.Handle("PUT /api/v0/alerts/rules/{id}", corsMiddleware(logMiddleware(server.requireAdmin(server.updateAlertRuleHandler))))
+	http.Handle("DELETE /api/v0/alerts/rules/{id}", corsMiddleware(logMiddleware(server.requireAdmin(server.deleteAlertRuleHandler))))
+	http.Handle("GET /api/v0/alerts/rules/{id}/deliveries", corsMiddleware(logMiddleware(server.requireAdmin(server.getAlertDeliveriesHandler))))
+	http.Handle("GET /api/v0/dead-letters", corsMiddleware(logMiddleware(server.requireAdmin(server.getDeadLettersHandler))))
+	http.Handle("POST /api/v0/dead-letters/{id}/replay", corsMiddleware(logMiddleware(server.requireAdmin(server.replayDeadLetterHandler))))
+
+	// Ingest API
+	http.Handle("POST /api/v0/ingest", corsMiddleware(logMiddleware(server.requireIngestToken(server.ingestHandler))))
+
 	go func() {
 		ticker := time.NewTicker(cfg.aiGenSummaryInterval)
 		defer ticker.Stop()
 
 		for {
-			if err := server.generateSummary(); err != nil {
+			if err := server.generateSummaries(); err != nil {
 				log.Printf("Error generating summary: %v", err)
 			} else {
 				log.Println("Summary generated successfully.")
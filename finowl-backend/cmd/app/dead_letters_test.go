@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"finowl-backend/pkg/deadletter"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryDeadLetters lists its entries and replays them successfully
+type memoryDeadLetters struct {
+	entries []deadletter.Entry
+	listed  deadletter.Status
+	limit   int
+}
+
+func (q *memoryDeadLetters) List(status deadletter.Status, limit int) ([]deadletter.Entry, error) {
+	q.listed, q.limit = status, limit
+	return q.entries, nil
+}
+
+func (q *memoryDeadLetters) Replay(id int64) (*deadletter.Entry, error) {
+	for i := range q.entries {
+		e := &q.entries[i]
+		if e.ID != id {
+			continue
+		}
+		if e.Status == deadletter.StatusResolved {
+			return e, fmt.Errorf("%w: %d", deadletter.ErrResolved, id)
+		}
+		e.Attempts++
+		e.Status = deadletter.StatusResolved
+		return e, nil
+	}
+	return nil, fmt.Errorf("%w: %d", deadletter.ErrNotFound, id)
+}
+
+func sampleDeadLetters() *memoryDeadLetters {
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	return &memoryDeadLetters{entries: []deadletter.Entry{
+		{ID: 1, Source: "discord", MessageID: "1234", Payload: json.RawMessage(`{}`), Status: deadletter.StatusFailed,
+			Attempts: 8, LastError: "connection refused", CreatedAt: at, NextAttemptAt: at.Add(time.Hour)},
+		{ID: 2, Source: "discord", MessageID: "5678", Payload: json.RawMessage(`{}`), Status: deadletter.StatusResolved,
+			Attempts: 2, CreatedAt: at, NextAttemptAt: at},
+	}}
+}
+
+func TestGetDeadLettersHandler(t *testing.T) {
+	queue := sampleDeadLetters()
+	server := &server{deadLetters: queue}
+
+	req := httptest.NewRequest("GET", "/api/v0/dead-letters?status=failed&limit=10", nil)
+	w := httptest.NewRecorder()
+	server.getDeadLettersHandler(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, deadletter.StatusFailed, queue.listed)
+	assert.Equal(t, 10, queue.limit)
+
+	var resp getDeadLettersHandlerResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.DeadLetters, 2)
+	assert.Equal(t, "connection refused", resp.DeadLetters[0].LastError)
+
+	for _, query := range []string{"status=lost", "limit=0", "limit=501"} {
+		w := httptest.NewRecorder()
+		server.getDeadLettersHandler(w, httptest.NewRequest("GET", "/api/v0/dead-letters?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestReplayDeadLetterHandler(t *testing.T) {
+	tests := []struct {
+		id       string
+		expected int
+	}{
+		{"1", http.StatusOK},
+		{"2", http.StatusConflict},
+		{"3", http.StatusNotFound},
+		{"abc", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			server := &server{deadLetters: sampleDeadLetters()}
+
+			req := httptest.NewRequest("POST", "/api/v0/dead-letters/"+tt.id+"/replay", nil)
+			req.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+			server.replayDeadLetterHandler(w, req)
+
+			require.Equal(t, tt.expected, w.Code)
+			if tt.expected == http.StatusOK {
+				var e deadletter.Entry
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &e))
+				assert.Equal(t, deadletter.StatusResolved, e.Status)
+				assert.Equal(t, 9, e.Attempts)
+			}
+		})
+	}
+}
+
+func TestDeadLettersAuthentication(t *testing.T) {
+	server := &server{deadLetters: sampleDeadLetters(), adminToken: "admin", ingestToken: "t0ken"}
+
+	for authorization, expected := range map[string]int{
+		"Bearer admin": http.StatusOK,
+		"Bearer t0ken": http.StatusUnauthorized,
+		"":             http.StatusUnauthorized,
+	} {
+		req := httptest.NewRequest("GET", "/api/v0/dead-letters", nil)
+		req.Header.Set("Authorization", authorization)
+		w := httptest.NewRecorder()
+
+		server.requireAdmin(server.getDeadLettersHandler)(w, req)
+		assert.Equal(t, expected, w.Code, authorization)
+	}
+
+	// Without the queue
+	server.deadLetters = nil
+	w := httptest.NewRecorder()
+	server.getDeadLettersHandler(w, httptest.NewRequest("GET", "/api/v0/dead-letters", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"time"
+
+	"finowl-backend/pkg/mindshare"
+)
+
+const (
+	minDiscoveryWindow = time.Hour
+	maxDiscoveryWindow = 90 * 24 * time.Hour
+	maxDormancy        = 365 * 24 * time.Hour
+)
+
+// discoveryParams holds the validated filters and page of a discovery request
+type discoveryParams struct {
+	window   time.Duration // how far back the discovery looks
+	dormancy time.Duration // minimum age of a revived ticker, zero when unsupported
+	category string        // channel category, empty for all
+	minScore float64
+	tier     int // influencer tier that must have mentioned the ticker, 0 for any
+
+	page  int
+	limit int
+}
+
+// parseDiscoveryParams validates the discovery query parameters, falling back to defaults.
+// A zero default dormancy means the endpoint does not support the parameter.
+func (s *server) parseDiscoveryParams(query url.Values, defaults discoveryParams) (discoveryParams, error) {
+	params := defaults
+	var err error
+
+	if params.window, err = parseDurationParam("window", query.Get("window"), defaults.window, minDiscoveryWindow, maxDiscoveryWindow); err != nil {
+		return params, err
+	}
+
+	if defaults.dormancy == 0 {
+		if query.Get("dormancy") != "" {
+			return params, fmt.Errorf("dormancy is not supported by this endpoint")
+		}
+	} else if params.dormancy, err = parseDurationParam("dormancy", query.Get("dormancy"), defaults.dormancy, minDiscoveryWindow, maxDormancy); err != nil {
+		return params, err
+	}
+
+	if params.category, err = s.parseCategoryParam(query.Get("category")); err != nil {
+		return params, err
+	}
+
+	if queryMinScore := query.Get("min_score"); queryMinScore != "" {
+		params.minScore, err = strconv.ParseFloat(queryMinScore, 64)
+		if err != nil || math.IsNaN(params.minScore) || math.IsInf(params.minScore, 0) || params.minScore < 0 {
+			return params, fmt.Errorf("invalid min_score %q", queryMinScore)
+		}
+	}
+
+	if queryTier := query.Get("tier"); queryTier != "" {
+		params.tier, err = strconv.Atoi(queryTier)
+		if _, ok := mindshare.Scoring.Tiers[params.tier]; err != nil || !ok {
+			return params, fmt.Errorf("invalid tier %q", queryTier)
+		}
+	}
+
+	if params.page, err = parseIntParam("page", query.Get("page"), 0, 0, math.MaxInt32); err != nil {
+		return params, err
+	}
+
+	// pageSize is accepted as the historical name of limit
+	if params.limit, err = parseIntParam("pageSize", query.Get("pageSize"), defaults.limit, 1, maxPageSize); err != nil {
+		return params, err
+	}
+	if params.limit, err = parseIntParam("limit", query.Get("limit"), params.limit, 1, maxPageSize); err != nil {
+		return params, err
+	}
+
+	return params, nil
+}
+
+// filterArgs returns the arguments of the discoveryFilters placeholders, prefixed by the window
+func (p discoveryParams) filterArgs() []any {
+	return []any{p.window.Seconds(), p.category, p.minScore, p.tier}
+}
+
+// parseCategoryParam validates a channel category: one of the configured categories, or empty for all
+func (s *server) parseCategoryParam(category string) (string, error) {
+	if category != "" && !s.categories[category] {
+		return "", fmt.Errorf("unknown category %q", category)
+	}
+	return category, nil
+}
+
+// totalPageCnt returns how many pages of pageSize items are needed for count items
+func totalPageCnt(count, pageSize int) int {
+	remaining := 0
+	if count%pageSize > 0 {
+		remaining = 1
+	}
+	return (count / pageSize) + remaining
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"finowl-backend/pkg/alerts"
+)
+
+const (
+	// defaultAlertCooldown applies when a rule is created without cooldown_seconds
+	defaultAlertCooldown = 3600
+
+	minAlertSecretLength = 16
+	maxAlertRuleBody     = 64 << 10
+
+	defaultAlertDeliveries = 50
+	maxAlertDeliveries     = 500
+)
+
+var (
+	errGetAlertRules      = errors.New("failed to retrieve alert rules")
+	errSaveAlertRule      = errors.New("failed to save alert rule")
+	errGetAlertDeliveries = errors.New("failed to retrieve alert deliveries")
+)
+
+// alertRuleRequest is the body of the create and update requests. Omitted
+// optional fields take their defaults: enabled, a one hour cooldown, and
+// on update the current secret.
+type alertRuleRequest struct {
+	Name            string          `json:"name"`
+	Type            alerts.RuleType `json:"type"`
+	Ticker          string          `json:"ticker"`
+	Category        string          `json:"category"`
+	Threshold       float64         `json:"threshold"`
+	Tier            int             `json:"tier"`
+	WindowSeconds   int             `json:"window_seconds"`
+	CooldownSeconds *int            `json:"cooldown_seconds"`
+	WebhookURL      string          `json:"webhook_url"`
+	Secret          string          `json:"secret"`
+	Enabled         *bool           `json:"enabled"`
+}
+
+// createAlertRuleResponse is the only response revealing the secret of a rule
+type createAlertRuleResponse struct {
+	alerts.Rule
+	Secret string `json:"secret"`
+}
+
+type getAlertRulesHandlerResponse struct {
+	Rules []alerts.Rule `json:"rules"`
+}
+
+type getAlertDeliveriesHandlerResponse struct {
+	Deliveries []alerts.Delivery `json:"deliveries"`
+}
+
+// requireAdmin only lets requests carrying the admin token through. The
+// admin API is disabled when no token is configured.
+func (s *server) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return requireToken("admin", s.adminToken, next)
+}
+
+// requireToken only lets requests carrying the bearer token of an API
+// through. The API is disabled when its token is empty.
+func requireToken(api, expected string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if expected == "" {
+			writeJSONError(w, http.StatusServiceUnavailable, api+" API is disabled")
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+			writeJSONError(w, http.StatusUnauthorized, "invalid "+api+" token")
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// decodeAlertRule parses and validates a rule from a request body
+func decodeAlertRule(r *http.Request) (alerts.Rule, string, error) {
+	var req alertRuleRequest
+
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxAlertRuleBody))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		return alerts.Rule{}, "", fmt.Errorf("invalid body: %w", err)
+	}
+
+	rule := alerts.Rule{
+		Name:            strings.TrimSpace(req.Name),
+		Type:            req.Type,
+		Ticker:          strings.TrimSpace(req.Ticker),
+		Category:        req.Category,
+		Threshold:       req.Threshold,
+		Tier:            req.Tier,
+		WindowSeconds:   req.WindowSeconds,
+		CooldownSeconds: defaultAlertCooldown,
+		WebhookURL:      req.WebhookURL,
+		Enabled:         true,
+	}
+	if req.CooldownSeconds != nil {
+		rule.CooldownSeconds = *req.CooldownSeconds
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+
+	if len(rule.Ticker) > 20 {
+		return rule, "", fmt.Errorf("invalid ticker %q", rule.Ticker)
+	}
+	if req.Secret != "" && len(req.Secret) < minAlertSecretLength {
+		return rule, "", fmt.Errorf("secret must be at least %d characters", minAlertSecretLength)
+	}
+
+	return rule, req.Secret, rule.Validate()
+}
+
+// newAlertSecret generates the signing secret of a rule created without one
+func newAlertSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// scanAlertRule reads a row of the alertRuleColumns
+func scanAlertRule(row interface{ Scan(...any) error }) (alerts.Rule, error) {
+	var r alerts.Rule
+	err := row.Scan(&r.ID, &r.Name, &r.Type, &r.Ticker, &r.Category, &r.Threshold, &r.Tier, &r.WindowSeconds,
+		&r.CooldownSeconds, &r.WebhookURL, &r.Secret, &r.Enabled, &r.CreatedAt, &r.UpdatedAt)
+	return r, err
+}
+
+// parseRuleID reads the rule ID path value
+func parseRuleID(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid rule id %q", r.PathValue("id"))
+	}
+	return id, nil
+}
+
+// writeJSON replies with status and v encoded as JSON
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if _, err := w.Write(body); err != nil {
+		slog.Error(err.Error())
+	}
+}
+
+func (s *server) getAlertRulesHandler(w http.ResponseWriter, r *http.Request) {
+	rows, err := s.db.Query(queryGetAlertRules)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, errGetAlertRules.Error())
+		slog.Error(err.Error())
+		return
+	}
+	defer rows.Close()
+
+	resp := getAlertRulesHandlerResponse{Rules: []alerts.Rule{}}
+	for rows.Next() {
+		rule, err := scanAlertRule(rows)
+		if err != nil {
+			writeJSONError(w, http.StatusInternalServerError, errGetAlertRules.Error())
+			slog.Error(err.Error())
+			return
+		}
+		resp.Rules = append(resp.Rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		writeJSONError(w, http.StatusInternalServerError, errGetAlertRules.Error())
+		slog.Error(err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &resp)
+}
+
+func (s *server) getAlertRuleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseRuleID(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rule, err := scanAlertRule(s.db.QueryRow(queryGetAlertRule, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		writeJSONError(w, http.StatusNotFound, fmt.Sprintf("alert rule %d not found", id))
+		return
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, errGetAlertRules.Error())
+		slog.Error(err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &rule)
+}
+
+func (s *server) createAlertRuleHandler(w http.ResponseWriter, r *http.Request) {
+	rule, secret, err := decodeAlertRule(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if secret == "" {
+		if secret, err = newAlertSecret(); err != nil {
+			writeJSONError(w, http.StatusInternalServerError, errSaveAlertRule.Error())
+			slog.Error(err.Error())
+			return
+		}
+	}
+
+	created, err := scanAlertRule(s.db.QueryRow(queryInsertAlertRule,
+		rule.Name, rule.Type, rule.Ticker, rule.Category, rule.Threshold, rule.Tier, rule.WindowSeconds,
+		rule.CooldownSeconds, rule.WebhookURL, secret, rule.Enabled))
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, errSaveAlertRule.Error())
+		slog.Error(err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, &createAlertRuleResponse{Rule: created, Secret: created.Secret})
+}
+
+func (s *server) updateAlertRuleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseRuleID(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rule, secret, err := decodeAlertRule(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	updated, err := scanAlertRule(s.db.QueryRow(queryUpdateAlertRule,
+		rule.Name, rule.Type, rule.Ticker, rule.Category, rule.Threshold, rule.Tier, rule.WindowSeconds,
+		rule.CooldownSeconds, rule.WebhookURL, secret, rule.Enabled, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		writeJSONError(w, http.StatusNotFound, fmt.Sprintf("alert rule %d not found", id))
+		return
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, errSaveAlertRule.Error())
+		slog.Error(err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &updated)
+}
+
+func (s *server) deleteAlertRuleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseRuleID(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := s.db.Exec(queryDeleteAlertRule, id)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, errSaveAlertRule.Error())
+		slog.Error(err.Error())
+		return
+	}
+	if deleted, err := result.RowsAffected(); err == nil && deleted == 0 {
+		writeJSONError(w, http.StatusNotFound, fmt.Sprintf("alert rule %d not found", id))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) getAlertDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseRuleID(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limit, err := parseIntParam("limit", r.URL.Query().Get("limit"), defaultAlertDeliveries, 1, maxAlertDeliveries)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rows, err := s.db.Query(queryGetAlertDeliveries, id, limit)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, errGetAlertDeliveries.Error())
+		slog.Error(err.Error())
+		return
+	}
+	defer rows.Close()
+
+	resp := getAlertDeliveriesHandlerResponse{Deliveries: []alerts.Delivery{}}
+	for rows.Next() {
+		var d alerts.Delivery
+		var payload []byte
+		var deliveredAt sql.NullTime
+		if err := rows.Scan(&d.ID, &d.RuleID, &d.Ticker, &payload, &d.Status, &d.Attempts,
+			&d.ResponseStatus, &d.LastError, &d.CreatedAt, &deliveredAt); err != nil {
+			writeJSONError(w, http.StatusInternalServerError, errGetAlertDeliveries.Error())
+			slog.Error(err.Error())
+			return
+		}
+		d.Payload = payload
+		if deliveredAt.Valid {
+			d.DeliveredAt = &deliveredAt.Time
+		}
+		resp.Deliveries = append(resp.Deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		writeJSONError(w, http.StatusInternalServerError, errGetAlertDeliveries.Error())
+		slog.Error(err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &resp)
+}
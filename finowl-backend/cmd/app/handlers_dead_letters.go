@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"finowl-backend/pkg/deadletter"
+)
+
+const (
+	defaultDeadLetters = 50
+	maxDeadLetters     = 500
+)
+
+var (
+	errGetDeadLetters   = errors.New("failed to retrieve dead letters")
+	errReplayDeadLetter = errors.New("failed to replay dead letter")
+)
+
+// deadLetterQueue lists and replays the messages that could not be stored,
+// satisfied by *deadletter.Queue
+type deadLetterQueue interface {
+	List(status deadletter.Status, limit int) ([]deadletter.Entry, error)
+	Replay(id int64) (*deadletter.Entry, error)
+}
+
+type getDeadLettersHandlerResponse struct {
+	DeadLetters []deadletter.Entry `json:"dead_letters"`
+}
+
+// parseDeadLetterStatus reads the optional status filter of the dead letter list
+func parseDeadLetterStatus(value string) (deadletter.Status, error) {
+	switch status := deadletter.Status(value); status {
+	case "", deadletter.StatusPending, deadletter.StatusResolved, deadletter.StatusFailed:
+		return status, nil
+	}
+	return "", fmt.Errorf("invalid status %q", value)
+}
+
+func (s *server) getDeadLettersHandler(w http.ResponseWriter, r *http.Request) {
+	if s.deadLetters == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "dead letter queue is disabled")
+		return
+	}
+
+	status, err := parseDeadLetterStatus(r.URL.Query().Get("status"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limit, err := parseIntParam("limit", r.URL.Query().Get("limit"), defaultDeadLetters, 1, maxDeadLetters)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entries, err := s.deadLetters.List(status, limit)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, errGetDeadLetters.Error())
+		slog.Error(err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &getDeadLettersHandlerResponse{DeadLetters: entries})
+}
+
+// replayDeadLetterHandler replays a dead letter at once and returns it with
+// the outcome: resolved, or the error of the attempt in last_error
+func (s *server) replayDeadLetterHandler(w http.ResponseWriter, r *http.Request) {
+	if s.deadLetters == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "dead letter queue is disabled")
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id < 1 {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("invalid dead letter id %q", r.PathValue("id")))
+		return
+	}
+
+	entry, err := s.deadLetters.Replay(id)
+	switch {
+	case errors.Is(err, deadletter.ErrNotFound):
+		writeJSONError(w, http.StatusNotFound, fmt.Sprintf("dead letter %d not found", id))
+	case errors.Is(err, deadletter.ErrResolved):
+		writeJSONError(w, http.StatusConflict, fmt.Sprintf("dead letter %d is already resolved", id))
+	case err != nil:
+		writeJSONError(w, http.StatusInternalServerError, errReplayDeadLetter.Error())
+		slog.Error(err.Error())
+	default:
+		writeJSON(w, http.StatusOK, entry)
+	}
+}
@@ -5,12 +5,16 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"finowl-backend/pkg/ticker"
 )
 
-func (s *server) getFreshMentions() ([]ticker.Ticker, error) {
-	rows, err := s.db.Query(queryFreshMentions)
+var freshMentionsDefaults = discoveryParams{window: 6 * time.Hour, limit: 20}
+
+func (s *server) getFreshMentions(params discoveryParams) ([]ticker.Ticker, error) {
+	args := append(params.filterArgs(), params.limit, params.limit*params.page)
+	rows, err := s.db.Query(queryFreshMentions, args...)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", errGetMentions, err)
 	}
@@ -18,8 +22,29 @@ func (s *server) getFreshMentions() ([]ticker.Ticker, error) {
 	return processTickers(rows)
 }
 
+func (s *server) getFreshMentionsCount(params discoveryParams) (int, error) {
+	count := 0
+	if err := s.db.QueryRow(queryFreshMentionsCount, params.filterArgs()...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("%w: %w", errGetMentions, err)
+	}
+	return count, nil
+}
+
 func (s *server) getFreshMentionsHandler(w http.ResponseWriter, r *http.Request) {
-	tickers, err := s.getFreshMentions()
+	params, err := s.parseDiscoveryParams(r.URL.Query(), freshMentionsDefaults)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tickers, err := s.getFreshMentions(params)
+	if err != nil {
+		slog.Error(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	tickersCnt, err := s.getFreshMentionsCount(params)
 	if err != nil {
 		slog.Error(err.Error())
 		w.WriteHeader(http.StatusInternalServerError)
@@ -28,14 +53,11 @@ func (s *server) getFreshMentionsHandler(w http.ResponseWriter, r *http.Request)
 
 	resp := getTickersHandlerResponse{
 		Tickers:      tickers,
-		TotalPageCnt: 1,
-		Page:         0,
-		PageSize:     len(tickers),
+		TotalPageCnt: totalPageCnt(tickersCnt, params.limit),
+		Page:         params.page,
+		PageSize:     params.limit,
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-
 	body, err := json.Marshal(&resp)
 	if err != nil {
 		slog.Error(err.Error())
@@ -43,6 +65,9 @@ func (s *server) getFreshMentionsHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
 	if _, err := w.Write(body); err != nil {
 		slog.Error(err.Error())
 		return
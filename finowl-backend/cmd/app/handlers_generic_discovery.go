@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
-	"strconv"
+	"time"
 
 	"finowl-backend/pkg/ticker"
 )
 
-func (s *server) getGenericDiscovery(page int, pageSize int, sort string, sortDir string) ([]ticker.Ticker, error) {
+var genericDiscoveryDefaults = discoveryParams{window: 3 * 24 * time.Hour, limit: 10}
+
+func (s *server) getGenericDiscovery(params discoveryParams, sort string, sortDir string) ([]ticker.Ticker, error) {
 	orderBy, err := func(sort string) (string, error) {
 		switch sort {
 		case "mindshare":
@@ -42,7 +44,8 @@ func (s *server) getGenericDiscovery(page int, pageSize int, sort string, sortDi
 	}
 
 	query := fmt.Sprintf(queryGenericDiscovery, orderBy, orderByDir)
-	rows, err := s.db.Query(query, pageSize, pageSize*page)
+	args := append(params.filterArgs(), params.limit, params.limit*params.page)
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", errGetMentions, err)
 	}
@@ -50,35 +53,22 @@ func (s *server) getGenericDiscovery(page int, pageSize int, sort string, sortDi
 	return processTickers(rows)
 }
 
-func (s *server) getGenericDiscoveryCount() (int, error) {
+func (s *server) getGenericDiscoveryCount(params discoveryParams) (int, error) {
 	count := 0
-	if err := s.db.QueryRow(queryGenericDiscoveryCount).Scan(&count); err != nil {
+	if err := s.db.QueryRow(queryGenericDiscoveryCount, params.filterArgs()...).Scan(&count); err != nil {
 		return 0, fmt.Errorf("%w: %w", errGetMentions, err)
 	}
 	return count, nil
 }
 
 func (s *server) getGenericDiscoveryHandler(w http.ResponseWriter, r *http.Request) {
-	var err error
-	page := 0
-	pageSize := 10
 	sort := "mindshare"
 	sortDir := "desc"
 
-	if queryPage := r.URL.Query().Get("page"); queryPage != "" {
-		page, err = strconv.Atoi(queryPage)
-		if err != nil || page < 0 {
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
-	}
-
-	if queryPageSize := r.URL.Query().Get("pageSize"); queryPageSize != "" {
-		pageSize, err = strconv.Atoi(queryPageSize)
-		if err != nil || pageSize <= 0 || pageSize > maxPageSize {
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
+	params, err := s.parseDiscoveryParams(r.URL.Query(), genericDiscoveryDefaults)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
 	if querySort := r.URL.Query().Get("sort"); querySort != "" {
@@ -89,14 +79,14 @@ func (s *server) getGenericDiscoveryHandler(w http.ResponseWriter, r *http.Reque
 		sortDir = querySortDir
 	}
 
-	tickers, err := s.getGenericDiscovery(page, pageSize, sort, sortDir)
+	tickers, err := s.getGenericDiscovery(params, sort, sortDir)
 	if err != nil {
 		slog.Error(err.Error())
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	tickersCnt, err := s.getGenericDiscoveryCount()
+	tickersCnt, err := s.getGenericDiscoveryCount(params)
 	if err != nil {
 		slog.Error(err.Error())
 		w.WriteHeader(http.StatusInternalServerError)
@@ -104,16 +94,10 @@ func (s *server) getGenericDiscoveryHandler(w http.ResponseWriter, r *http.Reque
 	}
 
 	resp := getTickersHandlerResponse{
-		Tickers: tickers,
-		TotalPageCnt: func() int {
-			remaining := 0
-			if tickersCnt%pageSize > 0 {
-				remaining = 1
-			}
-			return (tickersCnt / pageSize) + remaining
-		}(),
-		Page:     page,
-		PageSize: pageSize,
+		Tickers:      tickers,
+		TotalPageCnt: totalPageCnt(tickersCnt, params.limit),
+		Page:         params.page,
+		PageSize:     params.limit,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
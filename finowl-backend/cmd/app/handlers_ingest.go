@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"finowl-backend/pkg/ingest"
+)
+
+const (
+	maxIngestBody  = 4 << 20
+	maxIngestItems = 500
+)
+
+// tweetIngester stores posts from sources other than Discord, satisfied by
+// *ingest.Pipeline
+type tweetIngester interface {
+	Ingest(items []ingest.Item) []ingest.Result
+}
+
+type ingestRequest struct {
+	Items []ingest.Item `json:"items"`
+}
+
+type ingestHandlerResponse struct {
+	Results    []ingest.Result `json:"results"` // in the order of the items
+	Stored     int             `json:"stored"`
+	Invalid    int             `json:"invalid"`
+	Duplicates int             `json:"duplicates"`
+	Failed     int             `json:"failed"`
+}
+
+// requireIngestToken only lets requests carrying the ingest token through.
+// The ingest API is disabled when no token is configured.
+func (s *server) requireIngestToken(next http.HandlerFunc) http.HandlerFunc {
+	return requireToken("ingest", s.ingestToken, next)
+}
+
+// ingestHandler runs a batch of posts through the mindshare pipeline. A batch
+// with a malformed item is rejected as a whole, items failing to be stored
+// are reported in their result.
+func (s *server) ingestHandler(w http.ResponseWriter, r *http.Request) {
+	if s.ingester == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "ingest API is disabled")
+		return
+	}
+
+	items, err := decodeIngestRequest(r, time.Now())
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp := ingestHandlerResponse{Results: s.ingester.Ingest(items)}
+	for _, result := range resp.Results {
+		switch result.Status {
+		case ingest.StatusStored:
+			resp.Stored++
+		case ingest.StatusInvalid:
+			resp.Invalid++
+		case ingest.StatusDuplicate:
+			resp.Duplicates++
+		case ingest.StatusFailed:
+			resp.Failed++
+		}
+	}
+
+	writeJSON(w, http.StatusOK, &resp)
+}
+
+// decodeIngestRequest parses and validates the items of a request body
+func decodeIngestRequest(r *http.Request, now time.Time) ([]ingest.Item, error) {
+	var req ingestRequest
+
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxIngestBody))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		return nil, fmt.Errorf("invalid body: %w", err)
+	}
+
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("items are required")
+	}
+	if len(req.Items) > maxIngestItems {
+		return nil, fmt.Errorf("too many items, at most %d per request", maxIngestItems)
+	}
+
+	for i, item := range req.Items {
+		if err := item.Validate(now); err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+	}
+	return req.Items, nil
+}
@@ -5,37 +5,110 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"finowl-backend/pkg/ticker"
 )
 
-func (s *server) getRecentMomentum() ([]ticker.Ticker, error) {
-	rows, err := s.db.Query(queryRecentMomentum)
+const (
+	defaultMomentumWindow = 24 * time.Hour
+	minMomentumWindow     = time.Hour
+	maxMomentumWindow     = 30 * 24 * time.Hour
+
+	defaultMomentumLimit = 20
+	maxMomentumLimit     = 100
+)
+
+// momentumTicker is a ticker along with its mention velocity
+type momentumTicker struct {
+	ticker.Ticker
+	Momentum ticker.Momentum `json:"momentum"`
+}
+
+type getRecentMomentumHandlerResponse struct {
+	Tickers      []momentumTicker `json:"tickers"`
+	TotalPageCnt int              `json:"total_page_cnt"`
+	Page         int              `json:"page"`
+	PageSize     int              `json:"page_size"`
+	Window       string           `json:"window"`
+	Baseline     string           `json:"baseline"`
+}
+
+// getRecentMomentum ranks the tickers mentioned during the trailing window by
+// how their mention velocity compares with the preceding baseline window
+func (s *server) getRecentMomentum(window, baseline time.Duration, limit int) ([]momentumTicker, error) {
+	rows, err := s.db.Query(queryRecentMomentum, window.Seconds(), baseline.Seconds(), limit)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", errGetMentions, err)
 	}
+	defer rows.Close()
+
+	tickers := []momentumTicker{}
+	for rows.Next() {
+		var t momentumTicker
+		var mentionDetailsJSON string
+
+		if err := rows.Scan(
+			&t.TickerSymbol, &t.Category, &t.MindshareScore, &t.LastMentionedAt, &t.FirstMentionedAt, &mentionDetailsJSON,
+			&t.Momentum.Mentions, &t.Momentum.Influencers, &t.Momentum.PreviousMentions, &t.Momentum.PreviousInfluencers,
+			&t.Momentum.Velocity, &t.Momentum.PreviousVelocity, &t.Momentum.Acceleration, &t.Momentum.Score,
+		); err != nil {
+			return nil, fmt.Errorf("%w: %w", errGetMentions, err)
+		}
 
-	return processTickers(rows)
+		if err := json.Unmarshal([]byte(mentionDetailsJSON), &t.MentionDetails); err != nil {
+			return nil, fmt.Errorf("%w: %w", errGetMentions, err)
+		}
+
+		if isValidTickerSymbol(t.TickerSymbol) {
+			tickers = append(tickers, t)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", errGetMentions, err)
+	}
+
+	return tickers, nil
 }
 
 func (s *server) getRecentMomentumHandler(w http.ResponseWriter, r *http.Request) {
-	tickers, err := s.getRecentMomentum()
+	query := r.URL.Query()
+
+	window, err := parseDurationParam("window", query.Get("window"), defaultMomentumWindow, minMomentumWindow, maxMomentumWindow)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// The preceding window defaults to the size of the trailing one
+	baseline, err := parseDurationParam("baseline", query.Get("baseline"), window, minMomentumWindow, maxMomentumWindow)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limit, err := parseIntParam("limit", query.Get("limit"), defaultMomentumLimit, 1, maxMomentumLimit)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tickers, err := s.getRecentMomentum(window, baseline, limit)
 	if err != nil {
 		slog.Error(err.Error())
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	resp := getTickersHandlerResponse{
+	resp := getRecentMomentumHandlerResponse{
 		Tickers:      tickers,
 		TotalPageCnt: 1,
 		Page:         0,
 		PageSize:     len(tickers),
+		Window:       window.String(),
+		Baseline:     baseline.String(),
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-
 	body, err := json.Marshal(&resp)
 	if err != nil {
 		slog.Error(err.Error())
@@ -43,6 +116,9 @@ func (s *server) getRecentMomentumHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
 	if _, err := w.Write(body); err != nil {
 		slog.Error(err.Error())
 		return
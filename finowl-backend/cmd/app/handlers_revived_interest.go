@@ -5,12 +5,16 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"finowl-backend/pkg/ticker"
 )
 
-func (s *server) getRevivedInterest() ([]ticker.Ticker, error) {
-	rows, err := s.db.Query(queryRevivedInterest)
+var revivedInterestDefaults = discoveryParams{window: 12 * time.Hour, dormancy: 7 * 24 * time.Hour, limit: 20}
+
+func (s *server) getRevivedInterest(params discoveryParams) ([]ticker.Ticker, error) {
+	args := append(params.filterArgs(), params.dormancy.Seconds(), params.limit, params.limit*params.page)
+	rows, err := s.db.Query(queryRevivedInterest, args...)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", errGetMentions, err)
 	}
@@ -18,8 +22,30 @@ func (s *server) getRevivedInterest() ([]ticker.Ticker, error) {
 	return processTickers(rows)
 }
 
+func (s *server) getRevivedInterestCount(params discoveryParams) (int, error) {
+	count := 0
+	args := append(params.filterArgs(), params.dormancy.Seconds())
+	if err := s.db.QueryRow(queryRevivedInterestCount, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("%w: %w", errGetMentions, err)
+	}
+	return count, nil
+}
+
 func (s *server) getRevivedInterestHandler(w http.ResponseWriter, r *http.Request) {
-	tickers, err := s.getRevivedInterest()
+	params, err := s.parseDiscoveryParams(r.URL.Query(), revivedInterestDefaults)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tickers, err := s.getRevivedInterest(params)
+	if err != nil {
+		slog.Error(err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	tickersCnt, err := s.getRevivedInterestCount(params)
 	if err != nil {
 		slog.Error(err.Error())
 		w.WriteHeader(http.StatusInternalServerError)
@@ -28,14 +54,11 @@ func (s *server) getRevivedInterestHandler(w http.ResponseWriter, r *http.Reques
 
 	resp := getTickersHandlerResponse{
 		Tickers:      tickers,
-		TotalPageCnt: 1,
-		Page:         0,
-		PageSize:     len(tickers),
+		TotalPageCnt: totalPageCnt(tickersCnt, params.limit),
+		Page:         params.page,
+		PageSize:     params.limit,
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-
 	body, err := json.Marshal(&resp)
 	if err != nil {
 		slog.Error(err.Error())
@@ -43,6 +66,9 @@ func (s *server) getRevivedInterestHandler(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
 	if _, err := w.Write(body); err != nil {
 		slog.Error(err.Error())
 		return
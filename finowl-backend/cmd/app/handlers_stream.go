@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"finowl-backend/pkg/events"
+	"finowl-backend/pkg/mindshare"
+)
+
+const (
+	// streamClientBuffer is how many events a stream client may lag behind before it is evicted
+	streamClientBuffer = 256
+
+	// streamHeartbeat keeps idle connections from being closed by proxies
+	streamHeartbeat = 15 * time.Second
+
+	maxStreamTickers = 50
+)
+
+// parseStreamFilter validates the ticker and category filters of a stream request
+func (s *server) parseStreamFilter(tickers, category string) (events.Filter, error) {
+	var filter events.Filter
+
+	if tickers != "" {
+		for _, symbol := range strings.Split(tickers, ",") {
+			symbol = strings.TrimSpace(symbol)
+			if symbol == "" || len(symbol) > 20 {
+				return filter, fmt.Errorf("invalid ticker %q", symbol)
+			}
+			filter.Tickers = append(filter.Tickers, symbol)
+		}
+		if len(filter.Tickers) > maxStreamTickers {
+			return filter, fmt.Errorf("at most %d tickers can be streamed", maxStreamTickers)
+		}
+	}
+
+	// Either a mindshare category ("High Alpha") or a channel category ("EarlyAlpha")
+	for _, c := range mindshare.Scoring.Categories {
+		if category == c.Name {
+			filter.Category = category
+			return filter, nil
+		}
+	}
+
+	var err error
+	filter.Category, err = s.parseCategoryParam(category)
+	return filter, err
+}
+
+// writeStreamEvent writes an event in the server-sent events format
+func writeStreamEvent(w http.ResponseWriter, e events.Event) error {
+	data, err := json.Marshal(&e)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return err
+}
+
+func (s *server) getStreamHandler(w http.ResponseWriter, r *http.Request) {
+	if s.events == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "live updates are not available")
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSONError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	filter, err := s.parseStreamFilter(r.URL.Query().Get("ticker"), r.URL.Query().Get("category"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	sub := s.events.Subscribe(filter)
+	defer s.events.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+
+		case e, open := <-sub.Events():
+			if !open {
+				if s.events.Evicted(sub) {
+					slog.Warn("evicted slow stream client", "remote", r.RemoteAddr)
+					fmt.Fprint(w, "event: evicted\ndata: {\"error\": \"client too slow, reconnect to resume\"}\n\n")
+					flusher.Flush()
+				}
+				return
+			}
+
+			if err := writeStreamEvent(w, e); err != nil {
+				slog.Error(err.Error())
+				return
+			}
+			flusher.Flush()
+
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
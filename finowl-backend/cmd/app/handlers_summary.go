@@ -12,25 +12,70 @@ import (
 	"finowl-backend/pkg/mindshare"
 )
 
-func (s *server) getSummaryByID(id int) (*mindshare.Summary, error) {
+func (s *server) getSummaryByID(id int, category string) (*mindshare.Summary, error) {
 	summary := &mindshare.Summary{}
 
+	var row *sql.Row
 	if id == -1 {
-		if err := s.db.QueryRow(queryGetSummaryLatest).Scan(&summary.ID, &summary.Time, &summary.Content); err != nil {
-			return nil, fmt.Errorf("%w: %w", errGetSummary, err)
-		}
-		return summary, nil
+		row = s.db.QueryRow(queryGetSummaryLatest, category)
+	} else {
+		row = s.db.QueryRow(queryGetSummaryByID, id, category)
 	}
 
-	if err := s.db.QueryRow(queryGetSummaryByID, id).Scan(&summary.ID, &summary.Time, &summary.Content); err != nil {
+	var featuredTickers, keyInsights, marketSentiment sql.NullString
+	if err := row.Scan(
+		&summary.ID, &summary.Time, &summary.Content, &summary.Category,
+		&summary.FirstTweetID, &summary.LastTweetID,
+		&summary.TweetCount, &summary.WindowStart, &summary.WindowEnd,
+		&featuredTickers, &keyInsights, &marketSentiment,
+	); err != nil {
 		return nil, fmt.Errorf("%w: %w", errGetSummary, err)
 	}
+
+	// Summaries stored before parsing existed, or that never parsed, have no sections
+	if !featuredTickers.Valid {
+		return summary, nil
+	}
+
+	tickers, err := s.getSummaryTickers(summary.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary.Sections = &mindshare.SummarySections{
+		FeaturedTickers: featuredTickers.String,
+		KeyInsights:     keyInsights.String,
+		MarketSentiment: marketSentiment.String,
+		Tickers:         tickers,
+	}
 	return summary, nil
 }
 
-func (s *server) getSummaryCount() (int, error) {
+func (s *server) getSummaryTickers(summaryID int) ([]mindshare.SummaryTicker, error) {
+	rows, err := s.db.Query(queryGetSummaryTickers, summaryID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errGetSummary, err)
+	}
+	defer rows.Close()
+
+	tickers := []mindshare.SummaryTicker{}
+	for rows.Next() {
+		var t mindshare.SummaryTicker
+		if err := rows.Scan(&t.TickerSymbol, &t.ProjectName, &t.Description); err != nil {
+			return nil, fmt.Errorf("%w: %w", errGetSummary, err)
+		}
+		tickers = append(tickers, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", errGetSummary, err)
+	}
+
+	return tickers, nil
+}
+
+func (s *server) getSummaryCount(category string) (int, error) {
 	count := 0
-	if err := s.db.QueryRow(queryGetSummaryCount).Scan(&count); err != nil {
+	if err := s.db.QueryRow(queryGetSummaryCount, category).Scan(&count); err != nil {
 		return 0, fmt.Errorf("%w: %w", errGetSummariesCount, err)
 	}
 	return count, nil
@@ -50,7 +95,9 @@ func (s *server) getSummaryHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	summary, err := s.getSummaryByID(summaryID)
+	category := r.URL.Query().Get("category")
+
+	summary, err := s.getSummaryByID(summaryID, category)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			w.WriteHeader(http.StatusNotFound)
@@ -61,7 +108,7 @@ func (s *server) getSummaryHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	total, err := s.getSummaryCount()
+	total, err := s.getSummaryCount(category)
 	if err != nil {
 		slog.Error(err.Error())
 		w.WriteHeader(http.StatusInternalServerError)
@@ -20,8 +20,9 @@ func createTestServer(t *testing.T) (*server, sqlmock.Sqlmock) {
 	require.NoError(t, err)
 
 	server := &server{
-		db:       db,
-		aiPrompt: "test prompt",
+		db:         db,
+		aiPrompt:   "test prompt",
+		categories: map[string]bool{"EarlyAlpha": true, "MacroNews": true},
 	}
 
 	return server, mock
@@ -119,6 +120,31 @@ func TestGetTickersHandler(t *testing.T) {
 			expectedStatus: http.StatusOK,
 			expectedLen:    1,
 		},
+		{
+			name: "request filtered by channel category",
+			queryParams: map[string]string{
+				"category": "EarlyAlpha",
+			},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				sampleTickers := createSampleTickers()
+				mentionDetailsJSON, _ := json.Marshal(sampleTickers[0].MentionDetails)
+
+				rows := sqlmock.NewRows([]string{
+					"ticker_symbol", "category", "mindshare_score",
+					"last_mentioned_at", "first_mentioned_at", "mention_details",
+				}).AddRow(
+					sampleTickers[0].TickerSymbol, sampleTickers[0].Category, sampleTickers[0].MindshareScore,
+					sampleTickers[0].LastMentionedAt, sampleTickers[0].FirstMentionedAt, string(mentionDetailsJSON),
+				)
+				mock.ExpectQuery("SELECT ticker_symbol, category, mindshare_score").
+					WithArgs(10, 0, "EarlyAlpha").WillReturnRows(rows)
+
+				countRows := sqlmock.NewRows([]string{"count"}).AddRow(1)
+				mock.ExpectQuery("SELECT COUNT").WithArgs("EarlyAlpha").WillReturnRows(countRows)
+			},
+			expectedStatus: http.StatusOK,
+			expectedLen:    1,
+		},
 		{
 			name: "invalid page parameter",
 			queryParams: map[string]string{
@@ -198,10 +224,13 @@ func TestGetTickersHandler(t *testing.T) {
 
 func TestGetFreshMentionsHandler(t *testing.T) {
 	tests := []struct {
-		name           string
-		setupMock      func(sqlmock.Sqlmock)
-		expectedStatus int
-		expectedLen    int
+		name             string
+		queryParams      map[string]string
+		setupMock        func(sqlmock.Sqlmock)
+		expectedStatus   int
+		expectedLen      int
+		expectedPageCnt  int
+		expectedPageSize int
 	}{
 		{
 			name: "successful request",
@@ -219,10 +248,17 @@ func TestGetFreshMentionsHandler(t *testing.T) {
 						ticker.LastMentionedAt, ticker.FirstMentionedAt, string(mentionDetailsJSON),
 					)
 				}
-				mock.ExpectQuery("SELECT ticker_symbol, category, mindshare_score").WillReturnRows(rows)
+				// Defaults: last 6 hours, no filters, first page of 20
+				mock.ExpectQuery("SELECT ticker_symbol, category, mindshare_score").
+					WithArgs(21600.0, "", 0.0, 0, 20, 0).WillReturnRows(rows)
+
+				countRows := sqlmock.NewRows([]string{"count"}).AddRow(2)
+				mock.ExpectQuery("SELECT COUNT").WithArgs(21600.0, "", 0.0, 0).WillReturnRows(countRows)
 			},
-			expectedStatus: http.StatusOK,
-			expectedLen:    2,
+			expectedStatus:   http.StatusOK,
+			expectedLen:      2,
+			expectedPageCnt:  1,
+			expectedPageSize: 20,
 		},
 		{
 			name: "empty result set",
@@ -232,9 +268,94 @@ func TestGetFreshMentionsHandler(t *testing.T) {
 					"last_mentioned_at", "first_mentioned_at", "mention_details",
 				})
 				mock.ExpectQuery("SELECT ticker_symbol, category, mindshare_score").WillReturnRows(rows)
+
+				countRows := sqlmock.NewRows([]string{"count"}).AddRow(0)
+				mock.ExpectQuery("SELECT COUNT").WillReturnRows(countRows)
 			},
-			expectedStatus: http.StatusOK,
-			expectedLen:    0,
+			expectedStatus:   http.StatusOK,
+			expectedLen:      0,
+			expectedPageCnt:  0,
+			expectedPageSize: 20,
+		},
+		{
+			name: "filtered and paginated request",
+			queryParams: map[string]string{
+				"window":    "2d",
+				"category":  "EarlyAlpha",
+				"min_score": "250",
+				"tier":      "1",
+				"limit":     "5",
+				"page":      "1",
+			},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{
+					"ticker_symbol", "category", "mindshare_score",
+					"last_mentioned_at", "first_mentioned_at", "mention_details",
+				})
+				mock.ExpectQuery("SELECT ticker_symbol, category, mindshare_score").
+					WithArgs(172800.0, "EarlyAlpha", 250.0, 1, 5, 5).WillReturnRows(rows)
+
+				countRows := sqlmock.NewRows([]string{"count"}).AddRow(12)
+				mock.ExpectQuery("SELECT COUNT").WithArgs(172800.0, "EarlyAlpha", 250.0, 1).WillReturnRows(countRows)
+			},
+			expectedStatus:   http.StatusOK,
+			expectedLen:      0,
+			expectedPageCnt:  3,
+			expectedPageSize: 5,
+		},
+		{
+			name:           "invalid window",
+			queryParams:    map[string]string{"window": "forever"},
+			setupMock:      func(mock sqlmock.Sqlmock) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "dormancy is not supported",
+			queryParams:    map[string]string{"dormancy": "7d"},
+			setupMock:      func(mock sqlmock.Sqlmock) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown tier",
+			queryParams:    map[string]string{"tier": "7"},
+			setupMock:      func(mock sqlmock.Sqlmock) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "negative min_score",
+			queryParams:    map[string]string{"min_score": "-1"},
+			setupMock:      func(mock sqlmock.Sqlmock) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "NaN min_score",
+			queryParams:    map[string]string{"min_score": "NaN"},
+			setupMock:      func(mock sqlmock.Sqlmock) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "infinite min_score",
+			queryParams:    map[string]string{"min_score": "+Inf"},
+			setupMock:      func(mock sqlmock.Sqlmock) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid category",
+			queryParams:    map[string]string{"category": "Early Alpha'"},
+			setupMock:      func(mock sqlmock.Sqlmock) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unconfigured category",
+			queryParams:    map[string]string{"category": "PortfolioInsights"},
+			setupMock:      func(mock sqlmock.Sqlmock) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "limit too large",
+			queryParams:    map[string]string{"limit": "2000"},
+			setupMock:      func(mock sqlmock.Sqlmock) {},
+			expectedStatus: http.StatusBadRequest,
 		},
 	}
 
@@ -246,6 +367,12 @@ func TestGetFreshMentionsHandler(t *testing.T) {
 			tt.setupMock(mock)
 
 			req := httptest.NewRequest("GET", "/api/v0/fresh-mentions", nil)
+			q := req.URL.Query()
+			for key, value := range tt.queryParams {
+				q.Add(key, value)
+			}
+			req.URL.RawQuery = q.Encode()
+
 			rr := httptest.NewRecorder()
 
 			server.getFreshMentionsHandler(rr, req)
@@ -257,8 +384,8 @@ func TestGetFreshMentionsHandler(t *testing.T) {
 				err := json.Unmarshal(rr.Body.Bytes(), &response)
 				assert.NoError(t, err)
 				assert.Len(t, response.Tickers, tt.expectedLen)
-				assert.Equal(t, 1, response.TotalPageCnt)
-				assert.Equal(t, 0, response.Page)
+				assert.Equal(t, tt.expectedPageCnt, response.TotalPageCnt)
+				assert.Equal(t, tt.expectedPageSize, response.PageSize)
 				assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
 			}
 
@@ -267,6 +394,29 @@ func TestGetFreshMentionsHandler(t *testing.T) {
 	}
 }
 
+func TestGetRevivedInterestHandler(t *testing.T) {
+	server, mock := createTestServer(t)
+	defer server.db.Close()
+
+	rows := sqlmock.NewRows([]string{
+		"ticker_symbol", "category", "mindshare_score",
+		"last_mentioned_at", "first_mentioned_at", "mention_details",
+	})
+	// Attention in the last day on tickers first mentioned at least 30 days ago
+	mock.ExpectQuery("SELECT ticker_symbol, category, mindshare_score").
+		WithArgs(86400.0, "", 0.0, 0, 2592000.0, 20, 0).WillReturnRows(rows)
+	mock.ExpectQuery("SELECT COUNT").
+		WithArgs(86400.0, "", 0.0, 0, 2592000.0).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	req := httptest.NewRequest("GET", "/api/v0/revived-interest?window=24h&dormancy=30d", nil)
+	rr := httptest.NewRecorder()
+
+	server.getRevivedInterestHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestGetGenericDiscoveryHandler(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -285,7 +435,7 @@ func TestGetGenericDiscoveryHandler(t *testing.T) {
 				sampleTickers := createSampleTickers()
 				mentionDetailsJSON, _ := json.Marshal(sampleTickers[0].MentionDetails)
 
-				// Mock the main query
+				// Mock the main query, looking back 3 days by default
 				rows := sqlmock.NewRows([]string{
 					"ticker_symbol", "category", "mindshare_score",
 					"last_mentioned_at", "first_mentioned_at", "mention_details",
@@ -296,7 +446,8 @@ func TestGetGenericDiscoveryHandler(t *testing.T) {
 						ticker.LastMentionedAt, ticker.FirstMentionedAt, string(mentionDetailsJSON),
 					)
 				}
-				mock.ExpectQuery("SELECT ticker_symbol, category, mindshare_score").WillReturnRows(rows)
+				mock.ExpectQuery("SELECT ticker_symbol, category, mindshare_score").
+					WithArgs(259200.0, "", 0.0, 0, 5, 0).WillReturnRows(rows)
 
 				// Mock the count query
 				countRows := sqlmock.NewRows([]string{"count"}).AddRow(10)
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+
+	"finowl-backend/pkg/mindshare"
+	"finowl-backend/pkg/ticker"
+)
+
+// maxTickerSummaries bounds how many of the latest summaries featuring a ticker are returned
+const maxTickerSummaries = 50
+
+type getTickerDetailHandlerResponse struct {
+	Ticker    ticker.Ticker                `json:"ticker"`
+	Mentions  []ticker.Mention             `json:"mentions"`
+	Tiers     []ticker.TierBreakdown       `json:"tiers"`
+	Summaries []mindshare.SummaryReference `json:"summaries"`
+}
+
+// getTicker returns the ticker with the given symbol, or nil when it does not exist
+func (s *server) getTicker(symbol string) (*ticker.Ticker, error) {
+	rows, err := s.db.Query(queryGetTicker, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errGetTicker, err)
+	}
+
+	tickers, err := processTickers(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(tickers) == 0 {
+		return nil, nil
+	}
+
+	return &tickers[0], nil
+}
+
+// getTickerMentions returns every mention of a ticker, newest first
+func (s *server) getTickerMentions(symbol string) ([]ticker.Mention, error) {
+	rows, err := s.db.Query(queryGetTickerMentions, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errGetMentions, err)
+	}
+	defer rows.Close()
+
+	mentions := []ticker.Mention{}
+	for rows.Next() {
+		var m ticker.Mention
+		if err := rows.Scan(&m.Author, &m.Tier, &m.TweetLink, &m.Content, &m.Category, &m.TweetID, &m.MentionedAt); err != nil {
+			return nil, fmt.Errorf("%w: %w", errGetMentions, err)
+		}
+		mentions = append(mentions, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", errGetMentions, err)
+	}
+
+	return mentions, nil
+}
+
+// getTickerSummaries returns the latest summaries featuring a ticker
+func (s *server) getTickerSummaries(symbol string) ([]mindshare.SummaryReference, error) {
+	rows, err := s.db.Query(queryGetTickerSummaries, symbol, maxTickerSummaries)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errGetSummary, err)
+	}
+	defer rows.Close()
+
+	summaries := []mindshare.SummaryReference{}
+	for rows.Next() {
+		var ref mindshare.SummaryReference
+		if err := rows.Scan(&ref.SummaryID, &ref.Time, &ref.Category, &ref.ProjectName, &ref.Description); err != nil {
+			return nil, fmt.Errorf("%w: %w", errGetSummary, err)
+		}
+		summaries = append(summaries, ref)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", errGetSummary, err)
+	}
+
+	return summaries, nil
+}
+
+// tierBreakdown counts the distinct influencers and mentions of every tier, lowest tier first
+func tierBreakdown(mentions []ticker.Mention) []ticker.TierBreakdown {
+	tiers := make(map[int]*ticker.TierBreakdown)
+	authors := make(map[int]map[string]bool)
+
+	for _, m := range mentions {
+		if tiers[m.Tier] == nil {
+			tiers[m.Tier] = &ticker.TierBreakdown{Tier: m.Tier}
+			authors[m.Tier] = make(map[string]bool)
+		}
+		tiers[m.Tier].Mentions++
+		if !authors[m.Tier][m.Author] {
+			authors[m.Tier][m.Author] = true
+			tiers[m.Tier].Influencers++
+		}
+	}
+
+	breakdown := make([]ticker.TierBreakdown, 0, len(tiers))
+	for _, tier := range tiers {
+		breakdown = append(breakdown, *tier)
+	}
+	sort.Slice(breakdown, func(i, j int) bool { return breakdown[i].Tier < breakdown[j].Tier })
+
+	return breakdown
+}
+
+func (s *server) getTickerDetailHandler(w http.ResponseWriter, r *http.Request) {
+	symbol := parseTickerSymbol(r.PathValue("symbol"))
+	if symbol == "" {
+		writeJSONError(w, http.StatusBadRequest, "missing ticker symbol")
+		return
+	}
+
+	t, err := s.getTicker(symbol)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, errGetTicker.Error())
+		slog.Error(err.Error())
+		return
+	}
+	if t == nil {
+		writeJSONError(w, http.StatusNotFound, fmt.Sprintf("ticker %q not found", symbol))
+		return
+	}
+
+	mentions, err := s.getTickerMentions(symbol)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, errGetMentions.Error())
+		slog.Error(err.Error())
+		return
+	}
+
+	summaries, err := s.getTickerSummaries(symbol)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, errGetSummary.Error())
+		slog.Error(err.Error())
+		return
+	}
+
+	resp := getTickerDetailHandlerResponse{
+		Ticker:    *t,
+		Mentions:  mentions,
+		Tiers:     tierBreakdown(mentions),
+		Summaries: summaries,
+	}
+
+	body, err := json.Marshal(&resp)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := w.Write(body); err != nil {
+		slog.Error(err.Error())
+		return
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"finowl-backend/pkg/storer"
+	"finowl-backend/pkg/ticker"
+)
+
+// historyInterval describes one bucketing of the ticker history
+type historyInterval struct {
+	bucket       string        // bucket_interval of ticker_snapshots
+	defaultRange time.Duration // range returned when from is omitted
+	maxRange     time.Duration // widest range a single request may ask for
+}
+
+var historyIntervals = map[string]historyInterval{
+	"hourly": {bucket: storer.SnapshotHourly, defaultRange: 7 * 24 * time.Hour, maxRange: 31 * 24 * time.Hour},
+	"daily":  {bucket: storer.SnapshotDaily, defaultRange: 90 * 24 * time.Hour, maxRange: 366 * 24 * time.Hour},
+}
+
+type getTickerHistoryHandlerResponse struct {
+	TickerSymbol string            `json:"ticker_symbol"`
+	Interval     string            `json:"interval"`
+	From         time.Time         `json:"from"`
+	To           time.Time         `json:"to"`
+	History      []ticker.Snapshot `json:"history"`
+}
+
+func (s *server) getTickerHistory(symbol, bucket string, from, to time.Time) ([]ticker.Snapshot, error) {
+	rows, err := s.db.Query(queryGetTickerHistory, symbol, bucket, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errGetTickerHistory, err)
+	}
+	defer rows.Close()
+
+	history := []ticker.Snapshot{}
+	for rows.Next() {
+		var snapshot ticker.Snapshot
+		if err := rows.Scan(&snapshot.BucketStart, &snapshot.MindshareScore, &snapshot.Category,
+			&snapshot.MentionCount, &snapshot.InfluencerCount); err != nil {
+			return nil, fmt.Errorf("%w: %w", errGetTickerHistory, err)
+		}
+		history = append(history, snapshot)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", errGetTickerHistory, err)
+	}
+
+	return history, nil
+}
+
+func (s *server) getTickerHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	symbol := parseTickerSymbol(r.PathValue("symbol"))
+	if symbol == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	intervalName := "hourly"
+	if queryInterval := r.URL.Query().Get("interval"); queryInterval != "" {
+		intervalName = queryInterval
+	}
+	interval, ok := historyIntervals[intervalName]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	to := time.Now().UTC()
+	if queryTo := r.URL.Query().Get("to"); queryTo != "" {
+		parsed, err := time.Parse(time.RFC3339, queryTo)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		to = parsed.UTC()
+	}
+
+	from := to.Add(-interval.defaultRange)
+	if queryFrom := r.URL.Query().Get("from"); queryFrom != "" {
+		parsed, err := time.Parse(time.RFC3339, queryFrom)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		from = parsed.UTC()
+	}
+
+	if !from.Before(to) || to.Sub(from) > interval.maxRange {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	history, err := s.getTickerHistory(symbol, interval.bucket, from, to)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(err.Error())
+		return
+	}
+
+	resp := getTickerHistoryHandlerResponse{
+		TickerSymbol: symbol,
+		Interval:     intervalName,
+		From:         from,
+		To:           to,
+		History:      history,
+	}
+
+	body, err := json.Marshal(&resp)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		slog.Error(err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := w.Write(body); err != nil {
+		slog.Error(err.Error())
+		return
+	}
+}
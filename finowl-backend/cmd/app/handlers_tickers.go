@@ -10,7 +10,7 @@ import (
 	"finowl-backend/pkg/ticker"
 )
 
-func (s *server) getTickers(page int, pageSize int, sort string, sortDir string) ([]ticker.Ticker, error) {
+func (s *server) getTickers(page int, pageSize int, sort string, sortDir string, category string) ([]ticker.Ticker, error) {
 	orderBy, err := func(sort string) (string, error) {
 		switch sort {
 		case "last_mentioned":
@@ -42,7 +42,7 @@ func (s *server) getTickers(page int, pageSize int, sort string, sortDir string)
 	}
 
 	query := fmt.Sprintf(queryGetTickers, orderBy, orderByDir)
-	rows, err := s.db.Query(query, pageSize, pageSize*page)
+	rows, err := s.db.Query(query, pageSize, pageSize*page, category)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", errGetTickers, err)
 	}
@@ -50,9 +50,9 @@ func (s *server) getTickers(page int, pageSize int, sort string, sortDir string)
 	return processTickers(rows)
 }
 
-func (s *server) getTickersCount() (int, error) {
+func (s *server) getTickersCount(category string) (int, error) {
 	count := 0
-	if err := s.db.QueryRow(queryGetTickersCount).Scan(&count); err != nil {
+	if err := s.db.QueryRow(queryGetTickersCount, category).Scan(&count); err != nil {
 		return 0, fmt.Errorf("%w: %w", errGetTickersCount, err)
 	}
 
@@ -65,6 +65,7 @@ func (s *server) getTickersHandler(w http.ResponseWriter, r *http.Request) {
 	pageSize := 10
 	sort := "last_mentioned"
 	sortDir := "asc"
+	category := r.URL.Query().Get("category")
 
 	if queryPage := r.URL.Query().Get("page"); queryPage != "" {
 		page, err = strconv.Atoi(queryPage)
@@ -90,14 +91,14 @@ func (s *server) getTickersHandler(w http.ResponseWriter, r *http.Request) {
 		sortDir = querySortDir
 	}
 
-	tickers, err := s.getTickers(page, pageSize, sort, sortDir)
+	tickers, err := s.getTickers(page, pageSize, sort, sortDir, category)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		slog.Error(err.Error())
 		return
 	}
 
-	tickersCnt, err := s.getTickersCount()
+	tickersCnt, err := s.getTickersCount(category)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		slog.Error(err.Error())
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTickerHistoryHandler(t *testing.T) {
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(sqlmock.Sqlmock)
+		expectedStatus int
+		expectedLen    int
+	}{
+		{
+			name:  "hourly history",
+			query: "from=2025-03-01T00:00:00Z&to=2025-03-02T00:00:00Z",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"bucket_start", "mindshare_score", "category", "mention_count", "influencer_count"}).
+					AddRow(from, 120.5, "Trenches", 3, 2).
+					AddRow(from.Add(time.Hour), 310.0, "Alpha", 5, 4)
+				mock.ExpectQuery("SELECT bucket_start, mindshare_score, category, mention_count, influencer_count").
+					WithArgs("AIXBT", "hour", from, to).WillReturnRows(rows)
+			},
+			expectedStatus: http.StatusOK,
+			expectedLen:    2,
+		},
+		{
+			name:  "daily history defaults to the last 90 days",
+			query: "interval=daily&to=2025-03-02T00:00:00Z",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				rows := sqlmock.NewRows([]string{"bucket_start", "mindshare_score", "category", "mention_count", "influencer_count"})
+				mock.ExpectQuery("SELECT bucket_start").
+					WithArgs("AIXBT", "day", to.AddDate(0, 0, -90), to).WillReturnRows(rows)
+			},
+			expectedStatus: http.StatusOK,
+			expectedLen:    0,
+		},
+		{
+			name:           "unknown interval",
+			query:          "interval=weekly",
+			setupMock:      func(mock sqlmock.Sqlmock) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid from",
+			query:          "from=yesterday",
+			setupMock:      func(mock sqlmock.Sqlmock) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "from after to",
+			query:          "from=2025-03-02T00:00:00Z&to=2025-03-01T00:00:00Z",
+			setupMock:      func(mock sqlmock.Sqlmock) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "hourly range too wide",
+			query:          "from=2025-01-01T00:00:00Z&to=2025-03-01T00:00:00Z",
+			setupMock:      func(mock sqlmock.Sqlmock) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, mock := createTestServer(t)
+			defer server.db.Close()
+
+			tt.setupMock(mock)
+
+			req := httptest.NewRequest("GET", "/api/v0/tickers/AIXBT/history?"+tt.query, nil)
+			req.SetPathValue("symbol", "AIXBT")
+			w := httptest.NewRecorder()
+
+			server.getTickerHistoryHandler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var resp getTickerHistoryHandlerResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, "AIXBT", resp.TickerSymbol)
+				assert.Len(t, resp.History, tt.expectedLen)
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
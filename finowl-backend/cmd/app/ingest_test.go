@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"finowl-backend/pkg/ingest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingIngester records the ingested items and stores all valid ones
+type recordingIngester struct {
+	items []ingest.Item
+}
+
+func (i *recordingIngester) Ingest(items []ingest.Item) []ingest.Result {
+	i.items = append(i.items, items...)
+
+	results := make([]ingest.Result, len(items))
+	for n, item := range items {
+		if len(item.Content) < 10 {
+			results[n] = ingest.Result{Status: ingest.StatusInvalid}
+			continue
+		}
+		if n > 0 && item.URL != "" && item.URL == items[n-1].URL {
+			results[n] = ingest.Result{Status: ingest.StatusDuplicate}
+			continue
+		}
+		results[n] = ingest.Result{Status: ingest.StatusStored, TweetID: "tweet-" + item.Author}
+	}
+	return results
+}
+
+func TestIngestHandler(t *testing.T) {
+	ingester := &recordingIngester{}
+	server := &server{ingester: ingester, ingestToken: "t0ken"}
+
+	body := `{"items": [
+		{"author": "aixbt", "content": "$AIXBT mindshare keeps climbing", "timestamp": "2025-03-01T12:00:00Z",
+			"url": "https://x.com/aixbt_agent/status/1879491000000000000", "source": "telegram", "category": "EarlyAlpha"},
+		{"author": "aixbt_relay", "content": "$AIXBT mindshare keeps climbing", "source": "rss",
+			"url": "https://x.com/aixbt_agent/status/1879491000000000000"},
+		{"author": "degen", "content": "gm", "source": "rss"}
+	]}`
+	req := httptest.NewRequest("POST", "/api/v0/ingest", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer t0ken")
+	w := httptest.NewRecorder()
+
+	server.requireIngestToken(server.ingestHandler)(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp ingestHandlerResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 1, resp.Stored)
+	assert.Equal(t, 1, resp.Duplicates)
+	assert.Equal(t, 1, resp.Invalid)
+	assert.Equal(t, "tweet-aixbt", resp.Results[0].TweetID)
+	assert.Equal(t, ingest.StatusDuplicate, resp.Results[1].Status)
+	assert.Equal(t, ingest.StatusInvalid, resp.Results[2].Status)
+
+	require.Len(t, ingester.items, 3)
+	assert.Equal(t, "EarlyAlpha", ingester.items[0].Category)
+	assert.Equal(t, 2025, ingester.items[0].Timestamp.Year())
+}
+
+func TestIngestHandlerRejectsBatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{"malformed body", `{"items": [`, "invalid body"},
+		{"unknown field", `{"items": [{"author": "a", "content": "b", "source": "rss", "likes": 3}]}`, "invalid body"},
+		{"bad timestamp", `{"items": [{"author": "a", "content": "b", "source": "rss", "timestamp": "yesterday"}]}`, "invalid body"},
+		{"no items", `{"items": []}`, "items are required"},
+		{"invalid item", `{"items": [{"author": "a", "content": "b", "source": "rss"}, {"author": "a", "content": "b"}]}`, "item 1: invalid source"},
+		{"too many items", `{"items": [` + strings.Repeat(`{"author": "a", "content": "b", "source": "rss"},`, maxIngestItems) +
+			`{"author": "a", "content": "b", "source": "rss"}]}`, "too many items"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ingester := &recordingIngester{}
+			server := &server{ingester: ingester}
+
+			req := httptest.NewRequest("POST", "/api/v0/ingest", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			server.ingestHandler(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expected)
+			assert.Empty(t, ingester.items)
+		})
+	}
+}
+
+func TestIngestHandlerAuthentication(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		expected      int
+	}{
+		{"ingest API disabled", "", "Bearer anything", http.StatusServiceUnavailable},
+		{"admin token", "t0ken", "Bearer admin", http.StatusUnauthorized},
+		{"valid token", "t0ken", "Bearer t0ken", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &server{ingester: &recordingIngester{}, ingestToken: tt.token, adminToken: "admin"}
+			req := httptest.NewRequest("POST", "/api/v0/ingest",
+				strings.NewReader(`{"items": [{"author": "a", "content": "$AIXBT to the moon", "source": "rss"}]}`))
+			req.Header.Set("Authorization", tt.authorization)
+			w := httptest.NewRecorder()
+
+			server.requireIngestToken(server.ingestHandler)(w, req)
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
@@ -2,8 +2,13 @@ package main
 
 import (
 	"finowl-backend/internal/utils"
+	"finowl-backend/pkg/alerts"
 	"finowl-backend/pkg/collector"
+	"finowl-backend/pkg/deadletter"
+	"finowl-backend/pkg/events"
 	"finowl-backend/pkg/influencer"
+	"finowl-backend/pkg/ingest"
+	"finowl-backend/pkg/storer"
 	"fmt"
 	"log"
 	"os"
@@ -13,9 +18,16 @@ import (
 const (
 	configFilePath      = "config.yaml"
 	influencersFilePath = "influencers.yaml"
+	scoringFilePath     = "scoring.yaml"
 )
 
 func main() {
+	// Inspect or migrate the schema without starting the bot
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
+
 	// Load and validate the configuration
 	appConfig, err := utils.LoadAppConfig()
 	if err != nil {
@@ -28,8 +40,45 @@ func main() {
 	// Initialize influencer rankings
 	influencerRankings := utils.MustInitInfluencers(influencersFilePath)
 
+	// Load the scoring model, with tier sizes taken from the rankings
+	utils.MustConfigureScoring(scoringFilePath, influencerRankings)
+
+	// Configure time decay of mindshare scores
+	utils.MustConfigureDecay(appConfig.MindshareHalfLife)
+
+	rescoreInterval, err := time.ParseDuration(appConfig.MindshareRescoreInterval)
+	if err != nil || rescoreInterval <= 0 {
+		log.Fatalln("error parsing MindshareRescoreInterval: ", err)
+	}
+
+	storer, err := utils.InitDB(utils.NewDBConfig(*appConfig))
+	if err != nil {
+		log.Fatalf("Failed to create storer: %v", err)
+	}
+
+	// Fan stored mentions and ticker changes out to /api/v0/stream clients
+	broker := events.NewBroker(streamClientBuffer)
+	storer.SetEventPublisher(broker)
+
+	// Deliver the webhooks of the alert rules fired by ticker changes
+	alertEngine := alerts.NewEngine(storer, alerts.DefaultConfig())
+	storer.SetAlertEvaluator(alertEngine)
+	if err := alertEngine.Resume(); err != nil {
+		log.Printf("Error resuming pending alert deliveries: %v", err)
+	}
+
+	// Retry the tweets that could not be stored
+	deadLetterConfig := deadletter.DefaultConfig()
+	if appConfig.DeadLetterSpool != "" {
+		deadLetterConfig.SpoolPath = appConfig.DeadLetterSpool
+	}
+	deadLetters := deadletter.NewQueue(storer, deadLetterConfig)
+	deadLetters.Start()
+
 	// Initialize bot
-	bot := mustInitializeBot(*appConfig, config, influencerRankings)
+	bot := mustInitializeBot(*appConfig, config, influencerRankings, storer)
+	bot.SetEventSource(broker)
+	bot.SetDeadLetters(deadLetters)
 
 	prompt, err := os.ReadFile("prompt.txt")
 	if err != nil {
@@ -51,24 +100,29 @@ func main() {
 		sslmode:              "disable",
 		aiAPIKey:             appConfig.ClaudeAPIKey,
 		aiPrompt:             string(prompt),
+		aiPrompts:            config.CategoryPrompts(),
 		aiGenSummaryInterval: summaryGenInterval,
+		summaries:            storer,
+		categories:           mustLoadCategories(*appConfig, config),
+		events:               broker,
+		adminToken:           appConfig.AdminAPIToken,
+		ingester:             ingest.NewPipeline(storer, *influencerRankings),
+		ingestToken:          appConfig.IngestAPIToken,
+		deadLetters:          deadLetters,
 	})
 
+	// Keep scores decaying between mentions and record their history
+	go runTickerRescoring(storer, rescoreInterval)
+
 	// Start the bot
 	startBot(bot)
 
 	// Wait for graceful shutdown
-	waitForShutdown(bot)
+	waitForShutdown(bot, deadLetters, alertEngine)
 }
 
 // mustInitializeBot creates and configures the bot instance. Exits on error.
-func mustInitializeBot(appConfig utils.AppConfig, config *utils.Prompt, influencerRankings *influencer.InfluencerRankings) *collector.Bot {
-
-	storer, err := utils.InitDB(utils.NewDBConfig(appConfig))
-	if err != nil {
-		log.Fatalf("Failed to create storer: %v", err)
-	}
-
+func mustInitializeBot(appConfig utils.AppConfig, config *utils.Prompt, influencerRankings *influencer.InfluencerRankings, storer *storer.Storer) *collector.Bot {
 	bot, err := collector.NewBot(
 		appConfig,
 		*config,
@@ -82,6 +136,45 @@ func mustInitializeBot(appConfig utils.AppConfig, config *utils.Prompt, influenc
 	return bot
 }
 
+// mustLoadCategories returns the channel categories mentions can be stored under:
+// those relay channels are routed to and those with a summary prompt. Exits on error.
+func mustLoadCategories(appConfig utils.AppConfig, config *utils.Prompt) map[string]bool {
+	channels, err := collector.ChannelCategories(appConfig, *config)
+	if err != nil {
+		log.Fatalf("Error loading channel categories: %v", err)
+	}
+
+	categories := make(map[string]bool, len(channels)+len(config.Prompts))
+	for _, category := range channels {
+		categories[category] = true
+	}
+	for category := range config.Prompts {
+		categories[category] = true
+	}
+	return categories
+}
+
+// runTickerRescoring periodically recomputes the decayed score and category of every
+// ticker, then snapshots them into the hourly and daily history.
+func runTickerRescoring(storer *storer.Storer, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		updated, err := storer.RescoreTickers(time.Now().UTC())
+		if err != nil {
+			log.Printf("Error rescoring tickers: %v", err)
+		} else {
+			log.Printf("Rescored tickers, %d updated.", updated)
+		}
+
+		if err := storer.SnapshotTickers(time.Now().UTC()); err != nil {
+			log.Printf("Error snapshotting tickers: %v", err)
+		}
+		<-ticker.C
+	}
+}
+
 // startBot starts the bot and logs any errors.
 func startBot(bot *collector.Bot) {
 	if err := bot.Start(); err != nil {
@@ -90,10 +183,14 @@ func startBot(bot *collector.Bot) {
 	fmt.Println("Bot is now running. Press CTRL-C to exit.")
 }
 
-// waitForShutdown handles graceful shutdown on interrupt signals.
-func waitForShutdown(bot *collector.Bot) {
+// waitForShutdown handles graceful shutdown on interrupt signals. The bot
+// is closed first, the messages it fails to store meanwhile are dead lettered.
+// The alert engine goes last, the tweets stored until then may fire alerts.
+func waitForShutdown(bot *collector.Bot, deadLetters *deadletter.Queue, alertEngine *alerts.Engine) {
 	utils.WaitForShutdown()
 
 	bot.Close()
+	deadLetters.Close()
+	alertEngine.Close()
 	fmt.Println("Bot has been shut down gracefully.")
 }
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"finowl-backend/internal/utils"
+	"finowl-backend/pkg/storer"
+)
+
+const migrateUsage = `Usage: %s migrate [status|up]
+
+  status  list the schema migrations and whether they were applied (default)
+  up      apply the pending migrations, as done on startup
+`
+
+// runMigrate runs the migrate subcommand, to inspect or migrate the schema
+// without starting the bot
+func runMigrate(args []string) {
+	command := "status"
+	if len(args) > 0 {
+		command = args[0]
+	}
+	if len(args) > 1 || (command != "status" && command != "up") {
+		fmt.Fprintf(os.Stderr, migrateUsage, os.Args[0])
+		os.Exit(2)
+	}
+
+	appConfig, err := utils.LoadToolConfig(true)
+	if err != nil {
+		log.Fatalf("Error loading config: %v", err)
+	}
+
+	db, err := utils.OpenDB(utils.NewDBConfig(*appConfig))
+	if err != nil {
+		log.Fatalf("Failed to create storer: %v", err)
+	}
+	defer db.Close()
+
+	if command == "up" {
+		applied, err := db.Migrate()
+		for _, m := range applied {
+			fmt.Printf("Applied migration %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Error migrating database: %v", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("Database schema is up to date.")
+		}
+		return
+	}
+
+	states, err := db.MigrationStatus()
+	if err != nil {
+		log.Fatalf("Error retrieving migration status: %v", err)
+	}
+	printMigrationStatus(os.Stdout, states)
+}
+
+// printMigrationStatus writes one line per migration, with when it was applied
+func printMigrationStatus(w io.Writer, states []storer.MigrationState) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tNAME\tSTATUS")
+	for _, s := range states {
+		status := "pending"
+		switch {
+		case s.Unknown:
+			status = "applied " + s.AppliedAt.UTC().Format(time.RFC3339) + ", unknown to this binary"
+		case s.AppliedAt != nil:
+			status = "applied " + s.AppliedAt.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%04d\t%s\t%s\n", s.Version, s.Name, status)
+	}
+	tw.Flush()
+}
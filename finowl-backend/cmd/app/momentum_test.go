@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var momentumColumns = []string{
+	"ticker_symbol", "category", "mindshare_score", "last_mentioned_at", "first_mentioned_at", "mention_details",
+	"mentions", "influencers", "previous_mentions", "previous_influencers",
+	"velocity", "previous_velocity", "acceleration", "momentum_score",
+}
+
+func TestGetRecentMomentumHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(sqlmock.Sqlmock)
+		expectedStatus int
+		expectedLen    int
+	}{
+		{
+			name:  "default windows",
+			query: "",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				now := time.Now()
+				rows := sqlmock.NewRows(momentumColumns).
+					AddRow("AIXBT", "Alpha", 310.0, now, now, `{"influencers": {}}`, 12, 5, 2, 2, 0.5, 0.083, 0.417, 22.65).
+					AddRow("ETH", "Trenches", 40.0, now, now, `{"influencers": {}}`, 3, 3, 6, 4, 0.125, 0.25, -0.125, 5.4)
+				mock.ExpectQuery("WITH windows AS").WithArgs(86400.0, 86400.0, 20).WillReturnRows(rows)
+			},
+			expectedStatus: http.StatusOK,
+			expectedLen:    2,
+		},
+		{
+			name:  "custom windows and limit",
+			query: "window=6h&baseline=7d&limit=5",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("WITH windows AS").WithArgs(21600.0, 604800.0, 5).
+					WillReturnRows(sqlmock.NewRows(momentumColumns))
+			},
+			expectedStatus: http.StatusOK,
+			expectedLen:    0,
+		},
+		{
+			name:           "window too short",
+			query:          "window=10m",
+			setupMock:      func(mock sqlmock.Sqlmock) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid baseline",
+			query:          "baseline=soon",
+			setupMock:      func(mock sqlmock.Sqlmock) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "limit too large",
+			query:          "limit=500",
+			setupMock:      func(mock sqlmock.Sqlmock) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, mock := createTestServer(t)
+			defer server.db.Close()
+
+			tt.setupMock(mock)
+
+			req := httptest.NewRequest("GET", "/api/v0/recent-momentum?"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			server.getRecentMomentumHandler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var resp getRecentMomentumHandlerResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Len(t, resp.Tickers, tt.expectedLen)
+				if tt.expectedLen > 0 {
+					assert.Equal(t, "AIXBT", resp.Tickers[0].TickerSymbol)
+					assert.Equal(t, 12, resp.Tickers[0].Momentum.Mentions)
+					assert.InDelta(t, 0.417, resp.Tickers[0].Momentum.Acceleration, 1e-9)
+					assert.InDelta(t, 22.65, resp.Tickers[0].Momentum.Score, 1e-9)
+				}
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestParseDurationParam(t *testing.T) {
+	d, err := parseDurationParam("window", "7d", time.Hour, time.Hour, 30*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 7*24*time.Hour, d)
+
+	d, err = parseDurationParam("window", "", time.Hour, time.Hour, 30*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, d)
+
+	_, err = parseDurationParam("window", "xd", time.Hour, time.Hour, 30*24*time.Hour)
+	assert.Error(t, err)
+
+	_, err = parseDurationParam("window", "31d", time.Hour, time.Hour, 30*24*time.Hour)
+	assert.Error(t, err)
+
+	d, err = parseDurationParam("window", "30d", time.Hour, time.Hour, 30*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 30*24*time.Hour, d)
+
+	// Multiplied out, 213505 days wraps around to about one day
+	_, err = parseDurationParam("window", "213505d", time.Hour, time.Hour, 30*24*time.Hour)
+	assert.EqualError(t, err, "window must be between 1h0m0s and 720h0m0s")
+
+	_, err = parseDurationParam("window", "-1d", time.Hour, time.Hour, 30*24*time.Hour)
+	assert.Error(t, err)
+}
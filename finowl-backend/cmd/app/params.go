@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseTickerSymbol normalizes a ticker path value the way tickers are
+// stored, so "$aixbt" finds AIXBT
+func parseTickerSymbol(value string) string {
+	return strings.ToUpper(strings.TrimPrefix(strings.TrimSpace(value), "$"))
+}
+
+// parseDurationParam parses a time window query parameter. Besides Go
+// durations ("90m", "24h") whole days are accepted ("7d").
+func parseDurationParam(name, value string, fallback, min, max time.Duration) (time.Duration, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	outOfRange := fmt.Errorf("%s must be between %s and %s", name, min, max)
+
+	var d time.Duration
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s %q", name, value)
+		}
+		// Bounded before converting, a large day count overflows a Duration
+		if n < 0 || n > int(max/(24*time.Hour)) {
+			return 0, outOfRange
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(value); err != nil {
+			return 0, fmt.Errorf("invalid %s %q", name, value)
+		}
+	}
+
+	if d < min || d > max {
+		return 0, outOfRange
+	}
+	return d, nil
+}
+
+// parseIntParam parses an integer query parameter within [min, max]
+func parseIntParam(name, value string, fallback, min, max int) (int, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", name, value)
+	}
+	if n < min || n > max {
+		return 0, fmt.Errorf("%s must be between %d and %d", name, min, max)
+	}
+	return n, nil
+}
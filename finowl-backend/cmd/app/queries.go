@@ -2,81 +2,249 @@ package main
 
 // SQL query constants for clean, professional code organization
 const (
+	// mentionDetailsColumn rebuilds the legacy mention_details JSON of a ticker
+	// from ticker_mentions: the latest mention of every influencer plus its count
+	mentionDetailsColumn = `COALESCE((
+			SELECT jsonb_build_object('influencers', jsonb_object_agg(latest.author, jsonb_build_object(
+				'tier', latest.tier,
+				'tweet_link', latest.tweet_link,
+				'content', latest.content,
+				'category', latest.category,
+				'tweet_id', latest.tweet_id,
+				'mentioned_at', latest.mentioned_at,
+				'mention_count', latest.mention_count)))
+			FROM (
+				SELECT DISTINCT ON (tm.author) tm.author, tm.tier, tm.tweet_link, tm.content, tm.category,
+				       COALESCE(tm.tweet_id::text, '') AS tweet_id,
+				       tm.mentioned_at AT TIME ZONE 'UTC' AS mentioned_at,
+				       COUNT(*) OVER (PARTITION BY tm.author) AS mention_count
+				FROM ticker_mentions tm
+				WHERE tm.ticker = tickers_1_0.ticker_symbol
+				ORDER BY tm.author, tm.mentioned_at DESC
+			) AS latest
+		), '{"influencers": {}}'::jsonb) AS mention_details`
+
 	// Tickers queries
+	// An empty category ($3 / $1) disables the channel category filter
 	queryGetTickers = `
-		SELECT ticker_symbol, category, mindshare_score, last_mentioned_at, first_mentioned_at, mention_details 
+		SELECT ticker_symbol, category, mindshare_score, last_mentioned_at, first_mentioned_at, ` + mentionDetailsColumn + ` 
 		FROM tickers_1_0 
+		WHERE ($3 = '' OR EXISTS (
+			SELECT 1 FROM ticker_mentions
+			WHERE ticker = tickers_1_0.ticker_symbol AND category = $3))
 		ORDER BY %s %s 
 		LIMIT $1 OFFSET $2`
 
 	queryGetTickersCount = `
 		SELECT COUNT(*) 
-		FROM tickers_1_0`
+		FROM tickers_1_0
+		WHERE ($1 = '' OR EXISTS (
+			SELECT 1 FROM ticker_mentions
+			WHERE ticker = tickers_1_0.ticker_symbol AND category = $1))`
 
 	// Summary queries
 	queryGetSummaryLatest = `
-		SELECT id, timestamp, content 
+		SELECT id, timestamp, content, category, 
+		       COALESCE(first_tweet_id::text, ''), COALESCE(last_tweet_id::text, ''), 
+		       tweet_count, window_start, window_end, 
+		       featured_tickers, key_insights, market_sentiment 
 		FROM Summaries 
+		WHERE ($1 = '' OR category = $1) 
 		ORDER BY timestamp DESC 
 		LIMIT 1`
 
 	queryGetSummaryByID = `
-		SELECT id, timestamp, content 
+		SELECT id, timestamp, content, category, 
+		       COALESCE(first_tweet_id::text, ''), COALESCE(last_tweet_id::text, ''), 
+		       tweet_count, window_start, window_end, 
+		       featured_tickers, key_insights, market_sentiment 
 		FROM Summaries 
-		WHERE id = $1 
+		WHERE id = $1 AND ($2 = '' OR category = $2) 
 		LIMIT 1`
 
 	queryGetSummaryCount = `
 		SELECT COUNT(*) 
-		FROM Summaries`
+		FROM Summaries 
+		WHERE ($1 = '' OR category = $1)`
 
-	// Fresh mentions - tokens discovered in last 6 hours
+	// discoveryFilters narrows discovery queries down to tickers mentioned from the channel
+	// category $2, scoring at least $3 and mentioned by an influencer of tier $4. An empty
+	// category and a zero tier disable their filter.
+	discoveryFilters = `
+		  AND ($2 = '' OR EXISTS (
+			SELECT 1 FROM ticker_mentions
+			WHERE ticker = tickers_1_0.ticker_symbol AND category = $2))
+		  AND mindshare_score >= $3
+		  AND ($4 = 0 OR EXISTS (
+			SELECT 1 FROM ticker_mentions
+			WHERE ticker = tickers_1_0.ticker_symbol AND tier = $4))`
+
+	// Fresh mentions - tokens discovered in the last $1 seconds
 	queryFreshMentions = `
-		SELECT ticker_symbol, category, mindshare_score, last_mentioned_at, first_mentioned_at, mention_details 
+		SELECT ticker_symbol, category, mindshare_score, last_mentioned_at, first_mentioned_at, ` + mentionDetailsColumn + ` 
 		FROM tickers_1_0 
-		WHERE first_mentioned_at >= NOW() - INTERVAL '6 hours'
+		WHERE first_mentioned_at >= NOW() - $1::float8 * INTERVAL '1 second'` + discoveryFilters + `
 		ORDER BY first_mentioned_at DESC
-		LIMIT 20`
+		LIMIT $5 OFFSET $6`
 
-	// Recent momentum - tokens mentioned most frequently in last 24h
+	queryFreshMentionsCount = `
+		SELECT COUNT(*) 
+		FROM tickers_1_0 
+		WHERE first_mentioned_at >= NOW() - $1::float8 * INTERVAL '1 second'` + discoveryFilters
+
+	// Recent momentum - tokens ranked by mention velocity. Mentions and distinct influencers of
+	// the trailing window of $1 seconds are compared with the preceding window of $2 seconds.
+	// The score is the trailing activity (mentions + influencers), scaled by how much faster
+	// than in the preceding window the ticker is mentioned per hour.
 	queryRecentMomentum = `
-		SELECT ticker_symbol, category, mindshare_score, last_mentioned_at, first_mentioned_at, mention_details 
+		WITH windows AS (
+			SELECT ticker,
+			       COUNT(*) FILTER (WHERE mentioned_at >= NOW() - $1::float8 * INTERVAL '1 second') AS mentions,
+			       COUNT(DISTINCT author) FILTER (WHERE mentioned_at >= NOW() - $1::float8 * INTERVAL '1 second') AS influencers,
+			       COUNT(*) FILTER (WHERE mentioned_at < NOW() - $1::float8 * INTERVAL '1 second') AS previous_mentions,
+			       COUNT(DISTINCT author) FILTER (WHERE mentioned_at < NOW() - $1::float8 * INTERVAL '1 second') AS previous_influencers
+			FROM ticker_mentions
+			WHERE mentioned_at >= NOW() - ($1::float8 + $2::float8) * INTERVAL '1 second'
+			  AND mentioned_at <= NOW()
+			GROUP BY ticker
+		), velocity AS (
+			SELECT *,
+			       mentions / ($1::float8 / 3600) AS velocity,
+			       previous_mentions / ($2::float8 / 3600) AS previous_velocity
+			FROM windows
+			WHERE mentions > 0
+		)
+		SELECT ticker_symbol, category, mindshare_score, last_mentioned_at, first_mentioned_at, ` + mentionDetailsColumn + `, 
+		       v.mentions, v.influencers, v.previous_mentions, v.previous_influencers, 
+		       v.velocity, v.previous_velocity, 
+		       v.velocity - v.previous_velocity AS acceleration, 
+		       (v.mentions + v.influencers) * (v.velocity + 1) / (v.previous_velocity + 1) AS momentum_score 
 		FROM tickers_1_0 
-		WHERE last_mentioned_at >= NOW() - INTERVAL '24 hours'
-		ORDER BY last_mentioned_at DESC
-		LIMIT 20`
+		JOIN velocity v ON v.ticker = tickers_1_0.ticker_symbol 
+		ORDER BY momentum_score DESC, v.mentions DESC, ticker_symbol 
+		LIMIT $3`
 
-	// Revived interest - old tokens with recent attention
+	// Revived interest - tokens first mentioned at least $5 seconds ago with attention in the last $1 seconds
 	queryRevivedInterest = `
-		SELECT ticker_symbol, category, mindshare_score, last_mentioned_at, first_mentioned_at, mention_details 
+		SELECT ticker_symbol, category, mindshare_score, last_mentioned_at, first_mentioned_at, ` + mentionDetailsColumn + ` 
 		FROM tickers_1_0 
-		WHERE first_mentioned_at <= NOW() - INTERVAL '7 days'
-		  AND last_mentioned_at >= NOW() - INTERVAL '12 hours'
+		WHERE first_mentioned_at <= NOW() - $5::float8 * INTERVAL '1 second'
+		  AND last_mentioned_at >= NOW() - $1::float8 * INTERVAL '1 second'` + discoveryFilters + `
 		ORDER BY last_mentioned_at DESC
-		LIMIT 20`
+		LIMIT $6 OFFSET $7`
 
-	// Generic discovery - smart mix of recent action and high mentions (PAGINATED like /tickers)
+	queryRevivedInterestCount = `
+		SELECT COUNT(*) 
+		FROM tickers_1_0 
+		WHERE first_mentioned_at <= NOW() - $5::float8 * INTERVAL '1 second'
+		  AND last_mentioned_at >= NOW() - $1::float8 * INTERVAL '1 second'` + discoveryFilters
+
+	// Generic discovery - tokens with activity in the last $1 seconds (PAGINATED like /tickers)
 	queryGenericDiscovery = `
-		SELECT ticker_symbol, category, mindshare_score, last_mentioned_at, first_mentioned_at, mention_details 
+		SELECT ticker_symbol, category, mindshare_score, last_mentioned_at, first_mentioned_at, ` + mentionDetailsColumn + ` 
 		FROM tickers_1_0 
-		WHERE last_mentioned_at >= NOW() - INTERVAL '3 days'
+		WHERE last_mentioned_at >= NOW() - $1::float8 * INTERVAL '1 second'` + discoveryFilters + `
 		ORDER BY %s %s
-		LIMIT $1 OFFSET $2`
+		LIMIT $5 OFFSET $6`
 
 	queryGenericDiscoveryCount = `
 		SELECT COUNT(*) 
 		FROM tickers_1_0 
-		WHERE last_mentioned_at >= NOW() - INTERVAL '3 days'`
+		WHERE last_mentioned_at >= NOW() - $1::float8 * INTERVAL '1 second'` + discoveryFilters
+
+	// Ticker detail queries
+	queryGetTicker = `
+		SELECT ticker_symbol, category, mindshare_score, last_mentioned_at, first_mentioned_at, ` + mentionDetailsColumn + ` 
+		FROM tickers_1_0 
+		WHERE ticker_symbol = $1`
+
+	queryGetTickerMentions = `
+		SELECT author, tier, tweet_link, content, category, COALESCE(tweet_id::text, ''), mentioned_at 
+		FROM ticker_mentions 
+		WHERE ticker = $1 
+		ORDER BY mentioned_at DESC, id DESC`
 
-	// Tweet queries for summary generation
-	queryGetLatestTweets = `
-		SELECT id, author, content 
+	// Summaries featuring a ticker, newest first. Summary ticker symbols are stored upper case.
+	queryGetTickerSummaries = `
+		SELECT s.id, s.timestamp, s.category, st.project_name, st.description 
+		FROM summary_tickers st 
+		JOIN Summaries s ON s.id = st.summary_id 
+		WHERE st.ticker_symbol = UPPER($1) 
+		ORDER BY s.timestamp DESC, st.position 
+		LIMIT $2`
+
+	// Snapshots of a ticker with a bucket starting in [$3, $4), oldest first
+	queryGetTickerHistory = `
+		SELECT bucket_start, mindshare_score, category, mention_count, influencer_count 
+		FROM ticker_snapshots 
+		WHERE ticker = $1 AND bucket_interval = $2 
+		  AND bucket_start >= $3 AND bucket_start < $4 
+		ORDER BY bucket_start`
+
+	// End of the window covered by the previous summary of a category. Summaries
+	// created before windows were recorded fall back to their creation time.
+	queryGetLastSummaryWindowEnd = `
+		SELECT COALESCE(window_end, timestamp), last_tweet_id::text 
+		FROM Summaries 
+		WHERE category = $1 
+		ORDER BY timestamp DESC, id DESC 
+		LIMIT 1`
+
+	// Tweet queries for summary generation: the first tweets stored in (window_start, window_end],
+	// after tweet $3 among those stored at window_start. Windows follow insertion rather than the
+	// post time, so that backfilled, imported and retried tweets are summarized as well.
+	queryGetTweetsInWindow = `
+		SELECT id, author, content, inserted_at 
 		FROM tweets 
-		ORDER BY timestamp DESC 
-		LIMIT 150`
+		WHERE ($1 = '' OR category = $1) 
+		  AND (inserted_at > $2 OR (inserted_at = $2 AND id > $3)) 
+		  AND inserted_at <= $4 
+		ORDER BY inserted_at ASC, id ASC 
+		LIMIT $5`
+
+	// Featured ticker bullets parsed from a summary
+	queryGetSummaryTickers = `
+		SELECT ticker_symbol, project_name, description 
+		FROM summary_tickers 
+		WHERE summary_id = $1 
+		ORDER BY position`
+
+	// Alert rules, managed through the admin API
+	alertRuleColumns = `id, name, rule_type, ticker, category, threshold, tier, window_seconds, cooldown_seconds, 
+		webhook_url, secret, enabled, created_at, updated_at`
+
+	queryGetAlertRules = `
+		SELECT ` + alertRuleColumns + ` 
+		FROM alert_rules 
+		ORDER BY id`
+
+	queryGetAlertRule = `
+		SELECT ` + alertRuleColumns + ` 
+		FROM alert_rules 
+		WHERE id = $1`
+
+	queryInsertAlertRule = `
+		INSERT INTO alert_rules (name, rule_type, ticker, category, threshold, tier, window_seconds, cooldown_seconds, 
+		                         webhook_url, secret, enabled, created_at, updated_at) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW()) 
+		RETURNING ` + alertRuleColumns
+
+	// The secret is only replaced when $10 is not empty
+	queryUpdateAlertRule = `
+		UPDATE alert_rules 
+		SET name = $1, rule_type = $2, ticker = $3, category = $4, threshold = $5, tier = $6, window_seconds = $7, 
+		    cooldown_seconds = $8, webhook_url = $9, secret = COALESCE(NULLIF($10, ''), secret), enabled = $11, 
+		    updated_at = NOW() 
+		WHERE id = $12 
+		RETURNING ` + alertRuleColumns
+
+	queryDeleteAlertRule = `DELETE FROM alert_rules WHERE id = $1`
 
-	// Summary insertion
-	queryInsertSummary = `
-		INSERT INTO Summaries (timestamp, content) 
-		VALUES ($1, $2)`
+	// Delivery log of a rule, newest first
+	queryGetAlertDeliveries = `
+		SELECT id, rule_id, ticker, payload, status, attempts, response_status, last_error, created_at, delivered_at 
+		FROM alert_deliveries 
+		WHERE rule_id = $1 
+		ORDER BY created_at DESC, id DESC 
+		LIMIT $2`
 )
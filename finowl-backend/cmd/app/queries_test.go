@@ -23,8 +23,15 @@ func TestQuerySyntax(t *testing.T) {
 		{"queryRevivedInterest", queryRevivedInterest},
 		{"queryGenericDiscovery", queryGenericDiscovery},
 		{"queryGenericDiscoveryCount", queryGenericDiscoveryCount},
-		{"queryGetLatestTweets", queryGetLatestTweets},
-		{"queryInsertSummary", queryInsertSummary},
+		{"queryFreshMentionsCount", queryFreshMentionsCount},
+		{"queryRevivedInterestCount", queryRevivedInterestCount},
+		{"queryGetLastSummaryWindowEnd", queryGetLastSummaryWindowEnd},
+		{"queryGetTweetsInWindow", queryGetTweetsInWindow},
+		{"queryGetSummaryTickers", queryGetSummaryTickers},
+		{"queryGetTickerHistory", queryGetTickerHistory},
+		{"queryGetTicker", queryGetTicker},
+		{"queryGetTickerMentions", queryGetTickerMentions},
+		{"queryGetTickerSummaries", queryGetTickerSummaries},
 	}
 
 	for _, tt := range tests {
@@ -48,18 +55,28 @@ func TestQueryParameters(t *testing.T) {
 		query          string
 		expectedParams int
 	}{
-		{"queryGetTickers", queryGetTickers, 2},             // LIMIT $1 OFFSET $2
-		{"queryGetTickersCount", queryGetTickersCount, 0},   // No parameters
-		{"queryGetSummaryByID", queryGetSummaryByID, 1},     // WHERE id = $1
-		{"queryGenericDiscovery", queryGenericDiscovery, 2}, // LIMIT $1 OFFSET $2
-		{"queryInsertSummary", queryInsertSummary, 2},       // VALUES ($1, $2)
+		{"queryGetTickers", queryGetTickers, 3},             // LIMIT $1 OFFSET $2, category $3
+		{"queryGetTickersCount", queryGetTickersCount, 1},   // category $1
+		{"queryGetSummaryLatest", queryGetSummaryLatest, 1}, // category $1
+		{"queryGetSummaryByID", queryGetSummaryByID, 2},     // WHERE id = $1, category $2
+		{"queryGenericDiscovery", queryGenericDiscovery, 6}, // filters $1..$4, LIMIT $5 OFFSET $6
+		{"queryGenericDiscoveryCount", queryGenericDiscoveryCount, 4},
+		{"queryFreshMentions", queryFreshMentions, 6}, // filters $1..$4, LIMIT $5 OFFSET $6
+		{"queryFreshMentionsCount", queryFreshMentionsCount, 4},
+		{"queryRevivedInterest", queryRevivedInterest, 7}, // filters $1..$4, dormancy $5, LIMIT $6 OFFSET $7
+		{"queryRevivedInterestCount", queryRevivedInterestCount, 5},
+		{"queryGetTweetsInWindow", queryGetTweetsInWindow, 5},   // category $1, window $2..$4, LIMIT $5
+		{"queryGetTickerHistory", queryGetTickerHistory, 4},     // ticker $1, interval $2, range $3..$4
+		{"queryRecentMomentum", queryRecentMomentum, 3},         // window $1, baseline $2, LIMIT $3
+		{"queryGetTicker", queryGetTicker, 1},                   // ticker $1
+		{"queryGetTickerSummaries", queryGetTickerSummaries, 2}, // ticker $1, LIMIT $2
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Count parameter placeholders
 			paramCount := 0
-			for i := 1; i <= 10; i++ {
+			for i := 1; i <= 20; i++ {
 				if strings.Contains(tt.query, fmt.Sprintf("$%d", i)) {
 					paramCount++
 				}
@@ -71,23 +88,33 @@ func TestQueryParameters(t *testing.T) {
 }
 
 func TestTimeBasedQueries(t *testing.T) {
+	// Windows are passed in seconds so they can be set per request
 	timeBasedQueries := []struct {
-		name     string
-		query    string
-		interval string
+		name   string
+		query  string
+		window string
 	}{
-		{"queryFreshMentions", queryFreshMentions, "6 hours"},
-		{"queryRecentMomentum", queryRecentMomentum, "24 hours"},
-		{"queryRevivedInterest", queryRevivedInterest, "7 days"},
-		{"queryGenericDiscovery", queryGenericDiscovery, "3 days"},
+		{"queryFreshMentions", queryFreshMentions, "first_mentioned_at >= NOW() - $1::float8 * INTERVAL '1 second'"},
+		{"queryFreshMentionsCount", queryFreshMentionsCount, "first_mentioned_at >= NOW() - $1::float8 * INTERVAL '1 second'"},
+		{"queryRevivedInterest", queryRevivedInterest, "last_mentioned_at >= NOW() - $1::float8 * INTERVAL '1 second'"},
+		{"queryRevivedInterestCount", queryRevivedInterestCount, "last_mentioned_at >= NOW() - $1::float8 * INTERVAL '1 second'"},
+		{"queryGenericDiscovery", queryGenericDiscovery, "last_mentioned_at >= NOW() - $1::float8 * INTERVAL '1 second'"},
+		{"queryGenericDiscoveryCount", queryGenericDiscoveryCount, "last_mentioned_at >= NOW() - $1::float8 * INTERVAL '1 second'"},
 	}
 
+	// Momentum windows are given in seconds
+	assert.Contains(t, queryRecentMomentum, "NOW() - $1::float8 * INTERVAL '1 second'")
+
+	// The dormancy of revived tickers is given in seconds
+	assert.Contains(t, queryRevivedInterest, "first_mentioned_at <= NOW() - $5::float8 * INTERVAL '1 second'")
+	assert.Contains(t, queryRevivedInterestCount, "first_mentioned_at <= NOW() - $5::float8 * INTERVAL '1 second'")
+
 	for _, tt := range timeBasedQueries {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Contains(t, tt.query, "NOW() - INTERVAL",
-				"Time-based query should use NOW() - INTERVAL")
-			assert.Contains(t, tt.query, tt.interval,
-				"Query should contain expected time interval")
+			assert.Contains(t, tt.query, tt.window,
+				"Time-based query should filter on a parameterized window")
+			assert.Contains(t, tt.query, discoveryFilters,
+				"Discovery query should apply the category, score and tier filters")
 		})
 	}
 }
@@ -130,7 +157,7 @@ func TestOrderByLogic(t *testing.T) {
 		expectedOrder string
 	}{
 		{"queryFreshMentions", queryFreshMentions, "ORDER BY first_mentioned_at DESC"},
-		{"queryRecentMomentum", queryRecentMomentum, "ORDER BY last_mentioned_at DESC"},
+		{"queryRecentMomentum", queryRecentMomentum, "ORDER BY momentum_score DESC"},
 		{"queryRevivedInterest", queryRevivedInterest, "ORDER BY last_mentioned_at DESC"},
 		{"queryGenericDiscovery", queryGenericDiscovery, "ORDER BY %s %s"},
 	}
@@ -144,30 +171,24 @@ func TestOrderByLogic(t *testing.T) {
 }
 
 func TestLimitLogic(t *testing.T) {
-	// Test that simple queries have LIMIT 20
-	simpleQueries := []string{
-		queryFreshMentions,
-		queryRecentMomentum,
-		queryRevivedInterest,
-	}
-
-	for i, query := range simpleQueries {
-		t.Run(fmt.Sprintf("simple_query_limit_%d", i), func(t *testing.T) {
-			assert.Contains(t, query, "LIMIT 20",
-				"Simple queries should have LIMIT 20")
-		})
-	}
-
 	// Test that paginated queries use parameters
-	paginatedQueries := []string{
-		queryGetTickers,
-		queryGenericDiscovery,
+	paginatedQueries := []struct {
+		query string
+		limit string
+	}{
+		{queryGetTickers, "LIMIT $1 OFFSET $2"},
+		{queryFreshMentions, "LIMIT $5 OFFSET $6"},
+		{queryRevivedInterest, "LIMIT $6 OFFSET $7"},
+		{queryGenericDiscovery, "LIMIT $5 OFFSET $6"},
+		{queryRecentMomentum, "LIMIT $3"},
 	}
 
-	for i, query := range paginatedQueries {
+	for i, tt := range paginatedQueries {
 		t.Run(fmt.Sprintf("paginated_query_limit_%d", i), func(t *testing.T) {
-			assert.Contains(t, query, "LIMIT $1 OFFSET $2",
+			assert.Contains(t, tt.query, tt.limit,
 				"Paginated queries should use parameterized LIMIT/OFFSET")
+			assert.NotContains(t, tt.query, "LIMIT 20",
+				"Limits should not be hard-coded")
 		})
 	}
 }
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"finowl-backend/pkg/events"
+	"finowl-backend/pkg/ticker"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readStreamEvent reads the next event of a server-sent events stream
+func readStreamEvent(t *testing.T, reader *bufio.Reader) (string, string) {
+	t.Helper()
+
+	var eventType, data string
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "" && eventType != "":
+			return eventType, data
+		case strings.HasPrefix(line, "event: "):
+			eventType = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestGetStreamHandler(t *testing.T) {
+	broker := events.NewBroker(8)
+	defer broker.Close()
+
+	server := &server{events: broker, categories: map[string]bool{"EarlyAlpha": true}}
+	ts := httptest.NewServer(http.HandlerFunc(server.getStreamHandler))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/api/v0/stream?ticker=AIXBT&category=EarlyAlpha")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	require.Eventually(t, func() bool { return broker.Subscribers() == 1 }, time.Second, 10*time.Millisecond)
+
+	// Filtered out: other ticker, other channel category
+	broker.Publish(events.Event{Type: events.TickerCreated, Ticker: "ETH"})
+	broker.Publish(events.Event{Type: events.MentionStored, Ticker: "AIXBT", Mention: &ticker.Mention{Category: "MacroNews"}})
+	broker.Publish(events.Event{Type: events.MentionStored, Ticker: "AIXBT", Mention: &ticker.Mention{Author: "whale1", Category: "EarlyAlpha"}})
+
+	eventType, data := readStreamEvent(t, bufio.NewReader(resp.Body))
+	assert.Equal(t, string(events.MentionStored), eventType)
+
+	var e events.Event
+	require.NoError(t, json.Unmarshal([]byte(data), &e))
+	assert.Equal(t, "AIXBT", e.Ticker)
+	assert.Equal(t, "whale1", e.Mention.Author)
+	assert.Equal(t, uint64(3), e.ID)
+}
+
+func TestGetStreamHandlerInvalidFilter(t *testing.T) {
+	server := &server{events: events.NewBroker(8)}
+
+	req := httptest.NewRequest("GET", "/api/v0/stream?category=drop%20table", nil)
+	rr := httptest.NewRecorder()
+
+	server.getStreamHandler(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, 0, server.events.Subscribers())
+}
+
+func TestParseStreamFilter(t *testing.T) {
+	server := &server{categories: map[string]bool{"EarlyAlpha": true}}
+
+	filter, err := server.parseStreamFilter("AIXBT, eth", "High Alpha")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AIXBT", "eth"}, filter.Tickers)
+	assert.Equal(t, "High Alpha", filter.Category)
+
+	filter, err = server.parseStreamFilter("", "EarlyAlpha")
+	require.NoError(t, err)
+	assert.Equal(t, "EarlyAlpha", filter.Category)
+
+	_, err = server.parseStreamFilter("", "MacroNews")
+	assert.Error(t, err)
+
+	_, err = server.parseStreamFilter("AIXBT,,ETH", "")
+	assert.Error(t, err)
+}
@@ -1,17 +1,44 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"finowl-backend/pkg/mindshare"
+
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// summaryColumns lists the columns selected by the summary queries
+var summaryColumns = []string{
+	"id", "timestamp", "content", "category",
+	"first_tweet_id", "last_tweet_id", "tweet_count", "window_start", "window_end",
+	"featured_tickers", "key_insights", "market_sentiment",
+}
+
+// sectionedSummary is a model answer containing every required section
+const sectionedSummary = `<!-- BEGIN FEATURED TICKERS AND PROJECTS -->
+## Featured Tickers and Projects
+- **$ABC (Alphabet)**: trending
+<!-- END FEATURED TICKERS AND PROJECTS -->
+<!-- BEGIN KEY INSIGHTS FROM INFLUENCERS -->
+## Key Insights from Influencers
+- insight
+<!-- END KEY INSIGHTS FROM INFLUENCERS -->
+<!-- BEGIN MARKET SENTIMENT AND DIRECTIONS -->
+## Market Sentiment and Directions
+- bullish
+<!-- END MARKET SENTIMENT AND DIRECTIONS -->`
+
 func TestGetSummaryHandler(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -24,9 +51,10 @@ func TestGetSummaryHandler(t *testing.T) {
 			name:        "get latest summary (no ID)",
 			queryParams: map[string]string{},
 			setupMock: func(mock sqlmock.Sqlmock) {
-				summaryRow := sqlmock.NewRows([]string{"id", "timestamp", "content"}).
-					AddRow(1, time.Now(), "Latest summary content")
-				mock.ExpectQuery("SELECT id, timestamp, content FROM Summaries ORDER BY timestamp DESC LIMIT 1").
+				summaryRow := sqlmock.NewRows(summaryColumns).
+					AddRow(1, time.Now(), "Latest summary content", "", "", "", 0, nil, nil, nil, nil, nil)
+				mock.ExpectQuery("SELECT id, timestamp, content, category, .* FROM Summaries WHERE .* ORDER BY timestamp DESC LIMIT 1").
+					WithArgs("").
 					WillReturnRows(summaryRow)
 
 				countRow := sqlmock.NewRows([]string{"count"}).AddRow(5)
@@ -41,10 +69,10 @@ func TestGetSummaryHandler(t *testing.T) {
 				"id": "2",
 			},
 			setupMock: func(mock sqlmock.Sqlmock) {
-				summaryRow := sqlmock.NewRows([]string{"id", "timestamp", "content"}).
-					AddRow(2, time.Now(), "Specific summary content")
-				mock.ExpectQuery("SELECT id, timestamp, content FROM Summaries WHERE id = \\$1 LIMIT 1").
-					WithArgs(2).WillReturnRows(summaryRow)
+				summaryRow := sqlmock.NewRows(summaryColumns).
+					AddRow(2, time.Now(), "Specific summary content", "", "", "", 0, nil, nil, nil, nil, nil)
+				mock.ExpectQuery("SELECT id, timestamp, content, category, .* FROM Summaries WHERE id = \\$1 .* LIMIT 1").
+					WithArgs(2, "").WillReturnRows(summaryRow)
 
 				countRow := sqlmock.NewRows([]string{"count"}).AddRow(5)
 				mock.ExpectQuery("SELECT COUNT").WillReturnRows(countRow)
@@ -52,6 +80,24 @@ func TestGetSummaryHandler(t *testing.T) {
 			expectedStatus: http.StatusOK,
 			expectSummary:  true,
 		},
+		{
+			name: "get latest summary for a category",
+			queryParams: map[string]string{
+				"category": "MacroNews",
+			},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				summaryRow := sqlmock.NewRows(summaryColumns).
+					AddRow(3, time.Now(), "Macro summary content", "MacroNews", "", "", 0, nil, nil, nil, nil, nil)
+				mock.ExpectQuery("SELECT id, timestamp, content, category, .* FROM Summaries WHERE .* ORDER BY timestamp DESC LIMIT 1").
+					WithArgs("MacroNews").
+					WillReturnRows(summaryRow)
+
+				countRow := sqlmock.NewRows([]string{"count"}).AddRow(1)
+				mock.ExpectQuery("SELECT COUNT").WithArgs("MacroNews").WillReturnRows(countRow)
+			},
+			expectedStatus: http.StatusOK,
+			expectSummary:  true,
+		},
 		{
 			name: "invalid ID parameter",
 			queryParams: map[string]string{
@@ -65,7 +111,8 @@ func TestGetSummaryHandler(t *testing.T) {
 			name:        "summary not found",
 			queryParams: map[string]string{},
 			setupMock: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery("SELECT id, timestamp, content FROM Summaries ORDER BY timestamp DESC LIMIT 1").
+				mock.ExpectQuery("SELECT id, timestamp, content, category, .* FROM Summaries WHERE .* ORDER BY timestamp DESC LIMIT 1").
+					WithArgs("").
 					WillReturnError(sql.ErrNoRows)
 			},
 			expectedStatus: http.StatusNotFound,
@@ -77,8 +124,8 @@ func TestGetSummaryHandler(t *testing.T) {
 				"id": "999",
 			},
 			setupMock: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery("SELECT id, timestamp, content FROM Summaries WHERE id = \\$1 LIMIT 1").
-					WithArgs(999).WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery("SELECT id, timestamp, content, category, .* FROM Summaries WHERE id = \\$1 .* LIMIT 1").
+					WithArgs(999, "").WillReturnError(sql.ErrNoRows)
 			},
 			expectedStatus: http.StatusNotFound,
 			expectSummary:  false,
@@ -129,9 +176,10 @@ func TestGetSummaryByID(t *testing.T) {
 			name:      "get latest summary (ID = -1)",
 			summaryID: -1,
 			setupMock: func(mock sqlmock.Sqlmock) {
-				summaryRow := sqlmock.NewRows([]string{"id", "timestamp", "content"}).
-					AddRow(1, time.Now(), "Latest summary content")
-				mock.ExpectQuery("SELECT id, timestamp, content FROM Summaries ORDER BY timestamp DESC LIMIT 1").
+				summaryRow := sqlmock.NewRows(summaryColumns).
+					AddRow(1, time.Now(), "Latest summary content", "", "", "", 0, nil, nil, nil, nil, nil)
+				mock.ExpectQuery("SELECT id, timestamp, content, category, .* FROM Summaries WHERE .* ORDER BY timestamp DESC LIMIT 1").
+					WithArgs("").
 					WillReturnRows(summaryRow)
 			},
 			expectedError: false,
@@ -140,10 +188,10 @@ func TestGetSummaryByID(t *testing.T) {
 			name:      "get specific summary",
 			summaryID: 5,
 			setupMock: func(mock sqlmock.Sqlmock) {
-				summaryRow := sqlmock.NewRows([]string{"id", "timestamp", "content"}).
-					AddRow(5, time.Now(), "Specific summary content")
-				mock.ExpectQuery("SELECT id, timestamp, content FROM Summaries WHERE id = \\$1 LIMIT 1").
-					WithArgs(5).WillReturnRows(summaryRow)
+				summaryRow := sqlmock.NewRows(summaryColumns).
+					AddRow(5, time.Now(), "Specific summary content", "", "", "", 0, nil, nil, nil, nil, nil)
+				mock.ExpectQuery("SELECT id, timestamp, content, category, .* FROM Summaries WHERE id = \\$1 .* LIMIT 1").
+					WithArgs(5, "").WillReturnRows(summaryRow)
 			},
 			expectedError: false,
 		},
@@ -151,8 +199,8 @@ func TestGetSummaryByID(t *testing.T) {
 			name:      "summary not found",
 			summaryID: 999,
 			setupMock: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery("SELECT id, timestamp, content FROM Summaries WHERE id = \\$1 LIMIT 1").
-					WithArgs(999).WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery("SELECT id, timestamp, content, category, .* FROM Summaries WHERE id = \\$1 .* LIMIT 1").
+					WithArgs(999, "").WillReturnError(sql.ErrNoRows)
 			},
 			expectedError: true,
 		},
@@ -165,7 +213,7 @@ func TestGetSummaryByID(t *testing.T) {
 
 			tt.setupMock(mock)
 
-			summary, err := server.getSummaryByID(tt.summaryID)
+			summary, err := server.getSummaryByID(tt.summaryID, "")
 
 			if tt.expectedError {
 				assert.Error(t, err)
@@ -214,7 +262,7 @@ func TestGetSummaryCount(t *testing.T) {
 
 			tt.setupMock(mock)
 
-			count, err := server.getSummaryCount()
+			count, err := server.getSummaryCount("")
 
 			if tt.expectedError {
 				assert.Error(t, err)
@@ -228,3 +276,306 @@ func TestGetSummaryCount(t *testing.T) {
 		})
 	}
 }
+
+// recordingSummaryStore keeps the summaries the server generated
+type recordingSummaryStore struct {
+	summaries []mindshare.Summary
+}
+
+func (r *recordingSummaryStore) InsertSummary(summary *mindshare.Summary) error {
+	summary.ID = len(r.summaries) + 1
+	r.summaries = append(r.summaries, *summary)
+	return nil
+}
+
+// fakeSummarizer records the prompts and tweets it was asked to summarize
+type fakeSummarizer struct {
+	prompts []string
+	tweets  []string
+	replies []string // answers returned in order, the last one repeats
+}
+
+func (f *fakeSummarizer) AnalyzeTweets(_ context.Context, prompt, tweets string) (string, error) {
+	f.prompts = append(f.prompts, prompt)
+	f.tweets = append(f.tweets, tweets)
+	reply := f.replies[len(f.replies)-1]
+	if len(f.prompts) <= len(f.replies) {
+		reply = f.replies[len(f.prompts)-1]
+	}
+	return reply, nil
+}
+
+func TestGenerateSummaries(t *testing.T) {
+	t.Run("one summary per category", func(t *testing.T) {
+		server, mock := createTestServer(t)
+		defer server.db.Close()
+
+		summarizer := &fakeSummarizer{replies: []string{sectionedSummary}}
+		server.aiClient = summarizer
+		store := &recordingSummaryStore{}
+		server.summaries = store
+		server.aiPrompts = map[string]string{
+			"MacroNews":  "macro prompt",
+			"EarlyAlpha": "alpha prompt",
+		}
+
+		// Categories are processed in alphabetical order
+		for _, category := range []string{"EarlyAlpha", "MacroNews"} {
+			mock.ExpectQuery("SELECT COALESCE\\(window_end, timestamp\\), last_tweet_id::text FROM Summaries").
+				WithArgs(category).WillReturnError(sql.ErrNoRows)
+			rows := sqlmock.NewRows(windowTweetColumns).
+				AddRow("1", "author", category+" tweet", time.Now().UTC())
+			mock.ExpectQuery("SELECT id, author, content, inserted_at FROM tweets").
+				WithArgs(category, sqlmock.AnyArg(), nil, sqlmock.AnyArg(), maxSummaryTweets).WillReturnRows(rows)
+		}
+
+		assert.NoError(t, server.generateSummaries())
+		assert.Equal(t, []string{"alpha prompt\n\ntest prompt", "macro prompt\n\ntest prompt"}, summarizer.prompts)
+		assert.Equal(t, []string{"author: EarlyAlpha tweet\n", "author: MacroNews tweet\n"}, summarizer.tweets)
+		assert.NoError(t, mock.ExpectationsWereMet())
+
+		require.Len(t, store.summaries, 2)
+		for i, category := range []string{"EarlyAlpha", "MacroNews"} {
+			summary := store.summaries[i]
+			assert.Equal(t, category, summary.Category)
+			assert.Equal(t, sectionedSummary, summary.Content)
+			// Unposted summaries are looked up in UTC
+			assert.Equal(t, time.UTC, summary.Time.Location())
+			assert.Equal(t, "1", summary.FirstTweetID)
+			assert.Equal(t, "1", summary.LastTweetID)
+			assert.Equal(t, 1, summary.TweetCount)
+			require.NotNil(t, summary.Sections)
+			assert.Equal(t, []mindshare.SummaryTicker{{TickerSymbol: "ABC", ProjectName: "Alphabet", Description: "trending"}}, summary.Sections.Tickers)
+		}
+	})
+
+	t.Run("falls back to the default prompt", func(t *testing.T) {
+		server, mock := createTestServer(t)
+		defer server.db.Close()
+
+		summarizer := &fakeSummarizer{replies: []string{sectionedSummary}}
+		server.aiClient = summarizer
+		store := &recordingSummaryStore{}
+		server.summaries = store
+
+		mock.ExpectQuery("SELECT COALESCE\\(window_end, timestamp\\), last_tweet_id::text FROM Summaries").
+			WithArgs("").WillReturnError(sql.ErrNoRows)
+		rows := sqlmock.NewRows(windowTweetColumns).
+			AddRow("1", "author", "tweet", time.Now().UTC())
+		mock.ExpectQuery("SELECT id, author, content, inserted_at FROM tweets").
+			WithArgs("", sqlmock.AnyArg(), nil, sqlmock.AnyArg(), maxSummaryTweets).WillReturnRows(rows)
+
+		assert.NoError(t, server.generateSummaries())
+		assert.Equal(t, []string{"test prompt"}, summarizer.prompts)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		require.Len(t, store.summaries, 1)
+		assert.Empty(t, store.summaries[0].Category)
+	})
+}
+
+// lastWindowColumns lists the columns of the previous summary window query
+var lastWindowColumns = []string{"window_end", "last_tweet_id"}
+
+// windowTweetColumns lists the columns of the summarized tweets query
+var windowTweetColumns = []string{"id", "author", "content", "inserted_at"}
+
+// settledBefore matches a window end no later than its time, within a second
+type settledBefore struct{ t time.Time }
+
+func (a settledBefore) Match(v driver.Value) bool {
+	end, ok := v.(time.Time)
+	return ok && !end.After(a.t.Add(time.Second))
+}
+
+func TestGenerateSummaryWindow(t *testing.T) {
+	t.Run("covers tweets since the previous summary", func(t *testing.T) {
+		server, mock := createTestServer(t)
+		defer server.db.Close()
+
+		summarizer := &fakeSummarizer{replies: []string{sectionedSummary}}
+		server.aiClient = summarizer
+		store := &recordingSummaryStore{}
+		server.summaries = store
+
+		previousEnd := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		mock.ExpectQuery("SELECT COALESCE\\(window_end, timestamp\\), last_tweet_id::text FROM Summaries").
+			WithArgs("MacroNews").
+			WillReturnRows(sqlmock.NewRows(lastWindowColumns).AddRow(previousEnd, nil))
+		rows := sqlmock.NewRows(windowTweetColumns).
+			AddRow("a", "alice", "first", previousEnd.Add(time.Minute)).
+			AddRow("b", "bob", "second", previousEnd.Add(2*time.Minute)).
+			AddRow("c", "carol", "third", previousEnd.Add(3*time.Minute))
+		mock.ExpectQuery("SELECT id, author, content, inserted_at FROM tweets").
+			WithArgs("MacroNews", previousEnd, nil, sqlmock.AnyArg(), maxSummaryTweets).WillReturnRows(rows)
+
+		assert.NoError(t, server.generateSummary("MacroNews", "prompt"))
+		assert.Equal(t, []string{"alice: first\nbob: second\ncarol: third\n"}, summarizer.tweets)
+		assert.NoError(t, mock.ExpectationsWereMet())
+
+		require.Len(t, store.summaries, 1)
+		summary := store.summaries[0]
+		assert.Equal(t, "a", summary.FirstTweetID)
+		assert.Equal(t, "c", summary.LastTweetID)
+		assert.Equal(t, 3, summary.TweetCount)
+		require.NotNil(t, summary.WindowStart)
+		assert.Equal(t, previousEnd, *summary.WindowStart)
+	})
+
+	t.Run("resumes after the last tweet summarized", func(t *testing.T) {
+		server, mock := createTestServer(t)
+		defer server.db.Close()
+
+		server.aiClient = &fakeSummarizer{replies: []string{sectionedSummary}}
+		server.summaries = &recordingSummaryStore{}
+
+		// Tweets stored at the end of the previous window after its last one are not summarized yet
+		previousEnd := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		mock.ExpectQuery("SELECT COALESCE\\(window_end, timestamp\\), last_tweet_id::text FROM Summaries").
+			WithArgs("MacroNews").
+			WillReturnRows(sqlmock.NewRows(lastWindowColumns).AddRow(previousEnd, "c"))
+		mock.ExpectQuery("SELECT id, author, content, inserted_at FROM tweets .*inserted_at = \\$2 AND id > \\$3").
+			WithArgs("MacroNews", previousEnd, "c", sqlmock.AnyArg(), maxSummaryTweets).
+			WillReturnRows(sqlmock.NewRows(windowTweetColumns))
+
+		assert.NoError(t, server.generateSummary("MacroNews", "prompt"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("splits windows with too many tweets", func(t *testing.T) {
+		server, mock := createTestServer(t)
+		defer server.db.Close()
+
+		summarizer := &fakeSummarizer{replies: []string{sectionedSummary}}
+		server.aiClient = summarizer
+		store := &recordingSummaryStore{}
+		server.summaries = store
+
+		// An import stored every tweet at once, the first summary ends at its last tweet
+		previousEnd := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		imported := previousEnd.Add(time.Minute)
+		mock.ExpectQuery("SELECT COALESCE\\(window_end, timestamp\\), last_tweet_id::text FROM Summaries").
+			WithArgs("MacroNews").
+			WillReturnRows(sqlmock.NewRows(lastWindowColumns).AddRow(previousEnd, nil))
+		full := sqlmock.NewRows(windowTweetColumns)
+		for i := 0; i < maxSummaryTweets; i++ {
+			full.AddRow(fmt.Sprintf("%04d", i), "alice", "tweet", imported)
+		}
+		mock.ExpectQuery("SELECT id, author, content, inserted_at FROM tweets").
+			WithArgs("MacroNews", previousEnd, nil, sqlmock.AnyArg(), maxSummaryTweets).
+			WillReturnRows(full)
+		lastID := fmt.Sprintf("%04d", maxSummaryTweets-1)
+		mock.ExpectQuery("SELECT id, author, content, inserted_at FROM tweets").
+			WithArgs("MacroNews", imported, lastID, sqlmock.AnyArg(), maxSummaryTweets).
+			WillReturnRows(sqlmock.NewRows(windowTweetColumns).
+				AddRow("rest-1", "bob", "tweet", imported).
+				AddRow("rest-2", "carol", "tweet", imported.Add(time.Minute)))
+
+		assert.NoError(t, server.generateSummary("MacroNews", "prompt"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+
+		require.Len(t, store.summaries, 2)
+		first, second := store.summaries[0], store.summaries[1]
+		assert.Equal(t, maxSummaryTweets, first.TweetCount)
+		assert.Equal(t, lastID, first.LastTweetID)
+		assert.Equal(t, previousEnd, *first.WindowStart)
+		assert.Equal(t, imported, *first.WindowEnd)
+		assert.Equal(t, 2, second.TweetCount)
+		assert.Equal(t, imported, *second.WindowStart)
+		assert.True(t, second.WindowEnd.After(imported))
+	})
+
+	t.Run("leaves tweets still being stored to the next window", func(t *testing.T) {
+		server, mock := createTestServer(t)
+		defer server.db.Close()
+
+		summarizer := &fakeSummarizer{replies: []string{sectionedSummary}}
+		server.aiClient = summarizer
+
+		previousEnd := time.Now().UTC().Add(-time.Hour)
+		mock.ExpectQuery("SELECT COALESCE\\(window_end, timestamp\\), last_tweet_id::text FROM Summaries").
+			WithArgs("MacroNews").
+			WillReturnRows(sqlmock.NewRows(lastWindowColumns).AddRow(previousEnd, nil))
+		mock.ExpectQuery("SELECT id, author, content, inserted_at FROM tweets .*inserted_at > \\$2").
+			WithArgs("MacroNews", previousEnd, nil, settledBefore{time.Now().UTC().Add(-summarySettleDelay)}, maxSummaryTweets).
+			WillReturnRows(sqlmock.NewRows(windowTweetColumns))
+
+		assert.NoError(t, server.generateSummary("MacroNews", "prompt"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("skips generation without new tweets", func(t *testing.T) {
+		server, mock := createTestServer(t)
+		defer server.db.Close()
+
+		summarizer := &fakeSummarizer{replies: []string{sectionedSummary}}
+		server.aiClient = summarizer
+
+		mock.ExpectQuery("SELECT COALESCE\\(window_end, timestamp\\), last_tweet_id::text FROM Summaries").
+			WithArgs("MacroNews").
+			WillReturnRows(sqlmock.NewRows(lastWindowColumns).AddRow(time.Now().UTC(), nil))
+		mock.ExpectQuery("SELECT id, author, content, inserted_at FROM tweets").
+			WillReturnRows(sqlmock.NewRows(windowTweetColumns))
+
+		assert.NoError(t, server.generateSummary("MacroNews", "prompt"))
+		assert.Empty(t, summarizer.prompts)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAnalyzeTweetsWithSections(t *testing.T) {
+	t.Run("re-asks until the markers are present", func(t *testing.T) {
+		server, _ := createTestServer(t)
+		defer server.db.Close()
+
+		summarizer := &fakeSummarizer{replies: []string{"no markers", sectionedSummary}}
+		server.aiClient = summarizer
+
+		summary, sections, err := server.analyzeTweetsWithSections(context.Background(), "prompt", "tweets")
+		assert.NoError(t, err)
+		assert.Equal(t, sectionedSummary, summary)
+		assert.NotNil(t, sections)
+		assert.Len(t, summarizer.prompts, 2)
+		assert.Contains(t, summarizer.prompts[1], "previous answer was rejected")
+	})
+
+	t.Run("gives up after the maximum attempts", func(t *testing.T) {
+		server, _ := createTestServer(t)
+		defer server.db.Close()
+
+		summarizer := &fakeSummarizer{replies: []string{"no markers"}}
+		server.aiClient = summarizer
+
+		summary, sections, err := server.analyzeTweetsWithSections(context.Background(), "prompt", "tweets")
+		assert.NoError(t, err)
+		assert.Equal(t, "no markers", summary)
+		assert.Nil(t, sections)
+		assert.Len(t, summarizer.prompts, maxSummaryAttempts)
+	})
+}
+
+func TestGetSummaryByIDWithSections(t *testing.T) {
+	server, mock := createTestServer(t)
+	defer server.db.Close()
+
+	summaryRow := sqlmock.NewRows(summaryColumns).
+		AddRow(7, time.Now(), sectionedSummary, "MacroNews", "a", "c", 3, time.Now(), time.Now(),
+			"- **$ABC (Alphabet)**: trending", "- insight", "- bullish")
+	mock.ExpectQuery("SELECT id, timestamp, content, category, .* FROM Summaries WHERE id = \\$1").
+		WithArgs(7, "").WillReturnRows(summaryRow)
+	tickerRows := sqlmock.NewRows([]string{"ticker_symbol", "project_name", "description"}).
+		AddRow("ABC", "Alphabet", "trending")
+	mock.ExpectQuery("SELECT ticker_symbol, project_name, description FROM summary_tickers").
+		WithArgs(7).WillReturnRows(tickerRows)
+
+	summary, err := server.getSummaryByID(7, "")
+	assert.NoError(t, err)
+	if assert.NotNil(t, summary.Sections) {
+		assert.Equal(t, "- insight", summary.Sections.KeyInsights)
+		assert.Equal(t, "- bullish", summary.Sections.MarketSentiment)
+		assert.Equal(t, []mindshare.SummaryTicker{
+			{TickerSymbol: "ABC", ProjectName: "Alphabet", Description: "trending"},
+		}, summary.Sections.Tickers)
+	}
+	assert.Equal(t, 3, summary.TweetCount)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
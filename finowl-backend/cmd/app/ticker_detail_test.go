@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"finowl-backend/pkg/ticker"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTickerDetailHandler(t *testing.T) {
+	server, mock := createTestServer(t)
+	defer server.db.Close()
+
+	first := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	last := first.Add(5 * time.Hour)
+
+	mock.ExpectQuery("SELECT ticker_symbol, category, mindshare_score").WithArgs("AIXBT").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"ticker_symbol", "category", "mindshare_score",
+			"last_mentioned_at", "first_mentioned_at", "mention_details",
+		}).AddRow("AIXBT", "Alpha", 310.0, last, first, `{"influencers": {}}`))
+
+	mock.ExpectQuery("SELECT author, tier, tweet_link, content, category").WithArgs("AIXBT").
+		WillReturnRows(sqlmock.NewRows([]string{"author", "tier", "tweet_link", "content", "category", "tweet_id", "mentioned_at"}).
+			AddRow("whale1", 1, "https://x.com/whale1/status/3", "$AIXBT again", "EarlyAlpha", "", last).
+			AddRow("degen", 3, "https://x.com/degen/status/2", "$AIXBT", "AlphaTrenches", "", first.Add(time.Hour)).
+			AddRow("whale1", 1, "https://x.com/whale1/status/1", "$AIXBT", "EarlyAlpha", "", first))
+
+	mock.ExpectQuery("SELECT s.id, s.timestamp, s.category, st.project_name, st.description").
+		WithArgs("AIXBT", maxTickerSummaries).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "timestamp", "category", "project_name", "description"}).
+			AddRow(7, last, "EarlyAlpha", "aixbt", "AI agent"))
+
+	req := httptest.NewRequest("GET", "/api/v0/tickers/AIXBT", nil)
+	req.SetPathValue("symbol", "AIXBT")
+	w := httptest.NewRecorder()
+
+	server.getTickerDetailHandler(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp getTickerDetailHandlerResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "AIXBT", resp.Ticker.TickerSymbol)
+	assert.True(t, first.Equal(resp.Ticker.FirstMentionedAt))
+	assert.True(t, last.Equal(resp.Ticker.LastMentionedAt))
+	assert.Len(t, resp.Mentions, 3)
+	assert.Equal(t, []ticker.TierBreakdown{
+		{Tier: 1, Influencers: 1, Mentions: 2},
+		{Tier: 3, Influencers: 1, Mentions: 1},
+	}, resp.Tiers)
+	require.Len(t, resp.Summaries, 1)
+	assert.Equal(t, 7, resp.Summaries[0].SummaryID)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTickerDetailHandlerNotFound(t *testing.T) {
+	server, mock := createTestServer(t)
+	defer server.db.Close()
+
+	mock.ExpectQuery("SELECT ticker_symbol, category, mindshare_score").WithArgs("NOPE").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"ticker_symbol", "category", "mindshare_score",
+			"last_mentioned_at", "first_mentioned_at", "mention_details",
+		}))
+
+	req := httptest.NewRequest("GET", "/api/v0/tickers/NOPE", nil)
+	req.SetPathValue("symbol", "NOPE")
+	w := httptest.NewRecorder()
+
+	server.getTickerDetailHandler(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var resp errorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Contains(t, resp.Error, "not found")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTickerDetailHandlerNormalizesSymbol(t *testing.T) {
+	server, mock := createTestServer(t)
+	defer server.db.Close()
+
+	// Symbols are stored uppercase, without the cashtag
+	mock.ExpectQuery("SELECT ticker_symbol, category, mindshare_score").WithArgs("AIXBT").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"ticker_symbol", "category", "mindshare_score",
+			"last_mentioned_at", "first_mentioned_at", "mention_details",
+		}))
+
+	req := httptest.NewRequest("GET", "/api/v0/tickers/$aixbt", nil)
+	req.SetPathValue("symbol", "$aixbt")
+	w := httptest.NewRecorder()
+
+	server.getTickerDetailHandler(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), `\"AIXBT\" not found`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Archive formats
+const (
+	formatAuto  = "auto"
+	formatDCE   = "dce"   // DiscordChatExporter JSON export of a channel
+	formatJSONL = "jsonl" // One message object per line
+)
+
+// maxLineSize bounds a JSONL line, messages with large embeds exceed bufio's default
+const maxLineSize = 4 * 1024 * 1024
+
+// messageHandler receives every message read from an archive, with the
+// channel it was posted in
+type messageHandler func(channelID string, m *discordgo.Message) error
+
+// detectFormat picks the archive format from a file extension
+func detectFormat(path, format string) string {
+	if format != formatAuto {
+		return format
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson":
+		return formatJSONL
+	default:
+		return formatDCE
+	}
+}
+
+// readArchive hands every message of an archive to handle, in file order
+func readArchive(r io.Reader, format string, handle messageHandler) error {
+	switch format {
+	case formatDCE:
+		return readDCE(r, handle)
+	case formatJSONL:
+		return readJSONL(r, handle)
+	default:
+		return fmt.Errorf("unknown archive format %q", format)
+	}
+}
+
+// dceChannel is the channel block of a DiscordChatExporter export
+type dceChannel struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+}
+
+// dceMessage is a message of a DiscordChatExporter export
+type dceMessage struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	Content   string    `json:"content"`
+	Author    struct {
+		ID    string `json:"id"`
+		Name  string `json:"name"`
+		IsBot bool   `json:"isBot"`
+	} `json:"author"`
+	Embeds []dceEmbed `json:"embeds"`
+}
+
+// dceEmbed is an embed of a DiscordChatExporter export, which names its
+// fields differently from the Discord API
+type dceEmbed struct {
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	Timestamp   string `json:"timestamp"`
+	Description string `json:"description"`
+	Author      *struct {
+		Name    string `json:"name"`
+		URL     string `json:"url"`
+		IconURL string `json:"iconUrl"`
+	} `json:"author"`
+	Footer *struct {
+		Text string `json:"text"`
+	} `json:"footer"`
+}
+
+// readDCE streams the messages of a DiscordChatExporter JSON export without
+// loading the whole file, exports of busy channels run into gigabytes
+func readDCE(r io.Reader, handle messageHandler) error {
+	decoder := json.NewDecoder(r)
+	if err := expectDelim(decoder, '{'); err != nil {
+		return err
+	}
+
+	var channel dceChannel
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return fmt.Errorf("failed to read export: %w", err)
+		}
+
+		switch token {
+		case "channel":
+			if err := decoder.Decode(&channel); err != nil {
+				return fmt.Errorf("failed to decode channel: %w", err)
+			}
+
+		case "messages":
+			if channel.ID == "" {
+				return fmt.Errorf("export lists its messages before its channel")
+			}
+			if err := expectDelim(decoder, '['); err != nil {
+				return err
+			}
+			for decoder.More() {
+				var m dceMessage
+				if err := decoder.Decode(&m); err != nil {
+					return fmt.Errorf("failed to decode message: %w", err)
+				}
+				// Joins, pins and other system messages carry no tweet
+				if m.Type != "Default" && m.Type != "Reply" {
+					continue
+				}
+				if err := handle(channel.ID, m.toDiscord(channel.ID)); err != nil {
+					return err
+				}
+			}
+			if err := expectDelim(decoder, ']'); err != nil {
+				return err
+			}
+
+		default:
+			var skipped json.RawMessage
+			if err := decoder.Decode(&skipped); err != nil {
+				return fmt.Errorf("failed to read export: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// toDiscord converts an exported message to the shape the collector receives
+func (m dceMessage) toDiscord(channelID string) *discordgo.Message {
+	message := &discordgo.Message{
+		ID:        m.ID,
+		ChannelID: channelID,
+		Content:   m.Content,
+		Timestamp: m.Timestamp,
+		Author:    &discordgo.User{ID: m.Author.ID, Username: m.Author.Name, Bot: m.Author.IsBot},
+	}
+
+	for _, e := range m.Embeds {
+		embed := &discordgo.MessageEmbed{
+			URL:         e.URL,
+			Title:       e.Title,
+			Description: e.Description,
+			Timestamp:   e.Timestamp,
+		}
+		if e.Author != nil {
+			embed.Author = &discordgo.MessageEmbedAuthor{Name: e.Author.Name, URL: e.Author.URL, IconURL: e.Author.IconURL}
+		}
+		if e.Footer != nil {
+			embed.Footer = &discordgo.MessageEmbedFooter{Text: e.Footer.Text}
+		}
+		message.Embeds = append(message.Embeds, embed)
+	}
+	return message
+}
+
+// jsonlMessage is a line of a JSONL archive. Embeds follow the Discord API.
+type jsonlMessage struct {
+	ID        string                    `json:"id"`
+	ChannelID string                    `json:"channel_id"`
+	Author    string                    `json:"author"`
+	Timestamp time.Time                 `json:"timestamp"`
+	Content   string                    `json:"content"`
+	Embeds    []*discordgo.MessageEmbed `json:"embeds"`
+}
+
+// readJSONL reads an archive holding one message object per line
+func readJSONL(r io.Reader, handle messageHandler) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var m jsonlMessage
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if m.ID == "" || m.Timestamp.IsZero() {
+			return fmt.Errorf("line %d: id and timestamp are required", line)
+		}
+
+		err := handle(m.ChannelID, &discordgo.Message{
+			ID:        m.ID,
+			ChannelID: m.ChannelID,
+			Content:   m.Content,
+			Timestamp: m.Timestamp,
+			Author:    &discordgo.User{Username: m.Author},
+			Embeds:    m.Embeds,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("line %d: %w", line+1, err)
+	}
+	return nil
+}
+
+// expectDelim reads the next token and checks it is the given delimiter
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("failed to read export: %w", err)
+	}
+	if token != delim {
+		return fmt.Errorf("malformed export: expected %q, found %v", delim, token)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"finowl-backend/pkg/influencer"
+	"finowl-backend/pkg/mindshare"
+	"finowl-backend/pkg/storer"
+	"finowl-backend/pkg/ticker"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	if err := mindshare.Configure(mindshare.DefaultScoringConfig(), map[int]int{1: 5, 2: 15, 3: 33}); err != nil {
+		log.Fatalf("failed to configure scoring: %v", err)
+	}
+	os.Exit(m.Run())
+}
+
+// memoryStore stores the imported tweets like the tweets table, keyed by Discord message
+type memoryStore struct {
+	tweets  map[string]storer.Tweet
+	tickers []ticker.Ticker
+}
+
+func (s *memoryStore) StoreTweet(tweet storer.Tweet, tickers []ticker.Ticker) error {
+	if _, ok := s.tweets[tweet.MessageID]; ok {
+		return fmt.Errorf("%w for message %s", storer.ErrTweetExists, tweet.MessageID)
+	}
+	s.tweets[tweet.MessageID] = tweet
+	s.tickers = append(s.tickers, tickers...)
+	return nil
+}
+
+// readTestArchive returns every message of an archive of testdata
+func readTestArchive(t *testing.T, name string) []*discordgo.Message {
+	t.Helper()
+
+	file, err := os.Open(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	defer file.Close()
+
+	var messages []*discordgo.Message
+	err = readArchive(file, detectFormat(name, formatAuto), func(channelID string, m *discordgo.Message) error {
+		assert.Equal(t, channelID, m.ChannelID)
+		messages = append(messages, m)
+		return nil
+	})
+	require.NoError(t, err)
+	return messages
+}
+
+func TestReadDCE(t *testing.T) {
+	messages := readTestArchive(t, "export.json")
+
+	// The pinned message notification is skipped
+	require.Len(t, messages, 3)
+	assert.Equal(t, "1345678901234567890", messages[0].ID)
+	assert.Equal(t, "1300000000000000001", messages[0].ChannelID)
+	assert.Equal(t, "Murad • TweetShift", messages[0].Author.Username)
+	assert.True(t, messages[0].Timestamp.Equal(time.Date(2025, 1, 15, 8, 30, 12, 512e6, time.UTC)))
+
+	require.Len(t, messages[0].Embeds, 1)
+	embed := messages[0].Embeds[0]
+	assert.Equal(t, "https://twitter.com/MustStopMurad/status/1879475000000000000", embed.URL)
+	assert.Equal(t, "Murad (@MustStopMurad)", embed.Author.Name)
+	assert.Equal(t, "https://twitter.com/MustStopMurad", embed.Author.URL)
+}
+
+func TestReadJSONL(t *testing.T) {
+	messages := readTestArchive(t, "messages.jsonl")
+
+	require.Len(t, messages, 3)
+	assert.Equal(t, "Kaleo • Relay", messages[0].Author.Username)
+	assert.Equal(t, "aixbt (@aixbt_agent)", messages[1].Embeds[0].Title)
+	assert.Equal(t, "1399999999999999999", messages[2].ChannelID)
+}
+
+func TestReadMalformedArchives(t *testing.T) {
+	noop := func(string, *discordgo.Message) error { return nil }
+
+	err := readArchive(bytes.NewBufferString("{\"id\": \"1\", \"timestamp\": \"2025-01-16T10:00:00Z\"}\n{oops}\n"), formatJSONL, noop)
+	assert.ErrorContains(t, err, "line 2")
+
+	err = readArchive(bytes.NewBufferString(`{"id": "2"}`), formatJSONL, noop)
+	assert.ErrorContains(t, err, "id and timestamp are required")
+
+	err = readArchive(bytes.NewBufferString(`[]`), formatDCE, noop)
+	assert.Error(t, err)
+}
+
+func TestImport(t *testing.T) {
+	store := &memoryStore{tweets: map[string]storer.Tweet{}}
+	var progress bytes.Buffer
+	imp := newImporter(store, influencer.InfluencerRankings{}, map[string]string{
+		"1300000000000000001": "EarlyAlpha",
+		"1300000000000000002": "MacroNews",
+	}, "", &progress)
+
+	messages := append(readTestArchive(t, "export.json"), readTestArchive(t, "messages.jsonl")...)
+	for _, m := range messages {
+		require.NoError(t, imp.importMessage(m.ChannelID, m))
+	}
+
+	// $SPX and $SOL are excluded coins and only stored with their tweets
+	assert.Equal(t, importStats{Messages: 6, Tweets: 4, Invalid: 1, Unmapped: 1, Mentions: 3}, imp.stats)
+
+	// Original timestamps, categories and message IDs are kept
+	murad := store.tweets["1345678901234567890"]
+	assert.Equal(t, "Murad", murad.Author)
+	assert.Equal(t, "MustStopMurad", murad.Handle)
+	assert.Equal(t, "2025-01-15T08:30:12Z", murad.Timestamp)
+	assert.Equal(t, "EarlyAlpha", murad.Category)
+	assert.Equal(t, []string{"$SPX"}, murad.Tickers)
+	assert.Equal(t, "MacroNews", store.tweets["1345678901234567901"].Category)
+
+	require.Len(t, store.tickers, 3)
+	assert.Equal(t, "AIXBT", store.tickers[0].TickerSymbol)
+	assert.True(t, store.tickers[0].FirstMentionedAt.Equal(time.Date(2025, 1, 15, 9, 2, 44, 0, time.UTC)))
+
+	// Importing the same archive again stores nothing new
+	for _, m := range readTestArchive(t, "export.json") {
+		require.NoError(t, imp.importMessage(m.ChannelID, m))
+	}
+	assert.Equal(t, 2, imp.stats.Duplicates)
+	assert.Len(t, store.tweets, 4)
+	assert.Len(t, store.tickers, 3)
+
+	imp.report()
+	assert.Contains(t, progress.String(), "9 messages")
+	assert.Contains(t, progress.String(), "2 duplicates")
+}
+
+func TestImportDryRun(t *testing.T) {
+	var progress bytes.Buffer
+	imp := newImporter(nil, influencer.InfluencerRankings{}, nil, "MacroNews", &progress)
+
+	for _, m := range readTestArchive(t, "messages.jsonl") {
+		require.NoError(t, imp.importMessage(m.ChannelID, m))
+	}
+
+	// The category flag routes every channel
+	assert.Equal(t, importStats{Messages: 3, Tweets: 3, Mentions: 1}, imp.stats)
+}
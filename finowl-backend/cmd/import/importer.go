@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"finowl-backend/pkg/analyzer"
+	"finowl-backend/pkg/influencer"
+	"finowl-backend/pkg/storer"
+	"finowl-backend/pkg/ticker"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// importStore stores the imported tweets, satisfied by *storer.Storer
+type importStore interface {
+	StoreTweet(tweet storer.Tweet, tickers []ticker.Ticker) error
+}
+
+// importStats counts what happened to the imported messages
+type importStats struct {
+	Messages   int // read from the archives
+	Tweets     int // valid tweets, stored unless in a dry run
+	Invalid    int // too short to be a tweet
+	Duplicates int // already stored, from this message or another relaying the same tweet
+	Unmapped   int // from a channel routed to no category
+	Failed     int // could not be stored
+	Mentions   int // ticker mentions of the stored tweets
+}
+
+// importer runs archived messages through the collector's pipeline
+type importer struct {
+	store       importStore // nil in dry runs
+	analyzer    *analyzer.TweetAnalyzer
+	influencers influencer.InfluencerRankings
+	categories  map[string]string // channel ID -> category
+	category    string            // category of every message, overriding categories
+
+	stats importStats
+
+	progress       io.Writer
+	reportInterval time.Duration
+	started        time.Time
+	lastReport     time.Time
+}
+
+func newImporter(store importStore, influencers influencer.InfluencerRankings, categories map[string]string, category string, progress io.Writer) *importer {
+	now := time.Now()
+	return &importer{
+		store:          store,
+		analyzer:       analyzer.NewTweetAnalyzer(),
+		influencers:    influencers,
+		categories:     categories,
+		category:       category,
+		progress:       progress,
+		reportInterval: 5 * time.Second,
+		started:        now,
+		lastReport:     now,
+	}
+}
+
+// dryRun reports whether the importer only analyzes messages
+func (i *importer) dryRun() bool {
+	return i.store == nil
+}
+
+// importMessage extracts the tweet of a message and stores it with its
+// mentions, timestamped when the message was originally posted. Storage
+// failures are counted, not returned, so that one bad row does not stop an
+// import of months of messages.
+func (i *importer) importMessage(channelID string, m *discordgo.Message) error {
+	i.stats.Messages++
+	defer i.maybeReport()
+
+	category := i.category
+	if category == "" {
+		category = i.categories[channelID]
+	}
+	if category == "" {
+		i.stats.Unmapped++
+		return nil
+	}
+
+	tweet := i.analyzer.ProcessDiscordMessage(m)
+	if !tweet.IsValid {
+		i.stats.Invalid++
+		return nil
+	}
+
+	tt := storer.TransformToStorerTweet(*tweet)
+	tt.Category = category
+	tt.MessageID = m.ID
+	tickers := storer.ConvertTweetsToTickers([]storer.Tweet{tt}, i.influencers)
+
+	if i.dryRun() {
+		i.stats.Tweets++
+		i.stats.Mentions += len(tickers)
+		return nil
+	}
+
+	if err := i.store.StoreTweet(tt, tickers); err != nil {
+		if errors.Is(err, storer.ErrTweetExists) {
+			i.stats.Duplicates++
+			return nil
+		}
+		i.stats.Failed++
+		fmt.Fprintf(i.progress, "message %s: %v\n", m.ID, err)
+		return nil
+	}
+	i.stats.Tweets++
+	i.stats.Mentions += len(tickers)
+	return nil
+}
+
+// maybeReport prints the progress when the last report is old enough
+func (i *importer) maybeReport() {
+	if now := time.Now(); now.Sub(i.lastReport) >= i.reportInterval {
+		i.lastReport = now
+		i.report()
+	}
+}
+
+// report prints the counts so far
+func (i *importer) report() {
+	elapsed := time.Since(i.started)
+	rate := float64(i.stats.Messages) / max(elapsed.Seconds(), 0.001)
+
+	fmt.Fprintf(i.progress,
+		"%d messages (%.0f/s): %d tweets, %d mentions, %d invalid, %d duplicates, %d unmapped, %d failed\n",
+		i.stats.Messages, rate, i.stats.Tweets, i.stats.Mentions,
+		i.stats.Invalid, i.stats.Duplicates, i.stats.Unmapped, i.stats.Failed)
+}
@@ -0,0 +1,109 @@
+// Command import runs exported Discord channel archives through the collector
+// pipeline, so that months of relay messages can be loaded into the database.
+//
+//	go run ./cmd/import [-dry-run] [-category EarlyAlpha] export.json messages.jsonl
+//
+// Archives are DiscordChatExporter JSON exports or JSONL files holding one
+// {"id", "channel_id", "author", "timestamp", "content", "embeds"} object per line.
+// Messages keep their original timestamps and are keyed by their Discord ID,
+// so importing an archive twice, or one overlapping collected messages, stores
+// every tweet once.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"finowl-backend/internal/utils"
+	"finowl-backend/pkg/collector"
+	"finowl-backend/pkg/storer"
+)
+
+func main() {
+	var (
+		dryRun          = flag.Bool("dry-run", false, "analyze the archives without writing to the database")
+		category        = flag.String("category", "", "category of every imported message, instead of routing them by channel")
+		format          = flag.String("format", formatAuto, "archive format: auto, dce or jsonl (auto picks jsonl for .jsonl and .ndjson files)")
+		configPath      = flag.String("config", "config.yaml", "config file routing channels to categories")
+		influencersPath = flag.String("influencers", "influencers.yaml", "influencer rankings")
+		scoringPath     = flag.String("scoring", "scoring.yaml", "scoring model")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] archive...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	switch *format {
+	case formatAuto, formatDCE, formatJSONL:
+	default:
+		log.Fatalf("Unknown archive format %q", *format)
+	}
+
+	appConfig, err := utils.LoadToolConfig(!*dryRun)
+	if err != nil {
+		log.Fatalf("Error loading config: %v", err)
+	}
+
+	config := utils.MustLoadConfig(*configPath)
+	influencerRankings := utils.MustInitInfluencers(*influencersPath)
+	utils.MustConfigureScoring(*scoringPath, influencerRankings)
+
+	utils.MustConfigureDecay(appConfig.MindshareHalfLife)
+
+	channels, err := collector.ChannelCategories(*appConfig, *config)
+	if err != nil && *category == "" {
+		log.Fatalf("Error routing channels: %v", err)
+	}
+
+	imp := newImporter(nil, *influencerRankings, channels, *category, os.Stdout)
+
+	var db *storer.Storer
+	if !*dryRun {
+		db, err = utils.InitDB(utils.NewDBConfig(*appConfig))
+		if err != nil {
+			log.Fatalf("Failed to create storer: %v", err)
+		}
+		defer db.Close()
+		imp.store = db
+	}
+
+	for _, path := range flag.Args() {
+		if err := importFile(imp, path, detectFormat(path, *format)); err != nil {
+			imp.report()
+			log.Fatalf("Error importing %s: %v", path, err)
+		}
+	}
+	imp.report()
+
+	if *dryRun {
+		fmt.Println("Dry run, nothing was written. Messages stored before are counted as tweets.")
+		return
+	}
+
+	// Scores were computed as the mentions were imported, bring them to the present
+	updated, err := db.RescoreTickers(time.Now().UTC())
+	if err != nil {
+		log.Fatalf("Error rescoring tickers: %v", err)
+	}
+	fmt.Printf("Rescored tickers, %d updated.\n", updated)
+}
+
+// importFile imports every message of an archive
+func importFile(imp *importer, path, format string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	fmt.Printf("Importing %s (%s)\n", path, format)
+	return readArchive(file, format, imp.importMessage)
+}
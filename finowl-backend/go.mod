@@ -2,12 +2,14 @@ module finowl-backend
 
 go 1.23
 
-require github.com/bwmarrin/discordgo v0.28.1
+require (
+	github.com/DATA-DOG/go-sqlmock v1.5.2
+	github.com/bwmarrin/discordgo v0.28.1
+	github.com/openai/openai-go v0.1.0-alpha.61
+)
 
 require (
-	github.com/DATA-DOG/go-sqlmock v1.5.2 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/openai/openai-go v0.1.0-alpha.61 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/tidwall/gjson v1.14.4 // indirect
 	github.com/tidwall/match v1.1.1 // indirect
@@ -17,8 +17,28 @@ type DBConfig struct {
 	DBName   string
 }
 
-// InitDB initializes and returns a database connection
+// InitDB initializes and returns a database connection, once the pending
+// schema migrations were applied
 func InitDB(cfg DBConfig) (*storer.Storer, error) {
+	storerClient, err := OpenDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := storerClient.Migrate()
+	if err != nil {
+		storerClient.Close()
+		return nil, fmt.Errorf("error migrating database: %w", err)
+	}
+	for _, m := range applied {
+		log.Printf("Applied migration %04d_%s", m.Version, m.Name)
+	}
+
+	return storerClient, nil
+}
+
+// OpenDB returns a database connection without migrating the schema
+func OpenDB(cfg DBConfig) (*storer.Storer, error) {
 
 	dataSourceName := fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
@@ -39,8 +59,6 @@ func InitDB(cfg DBConfig) (*storer.Storer, error) {
 		return nil, fmt.Errorf("error initializing storer: %v", err)
 	}
 
-	storer.CreateTables(storerClient)
-
 	return storerClient, nil
 }
 
@@ -2,9 +2,11 @@ package utils
 
 import (
 	"finowl-backend/pkg/influencer"
+	"finowl-backend/pkg/mindshare"
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v2"
 )
@@ -41,3 +43,28 @@ func MustInitInfluencers(filePath string) *influencer.InfluencerRankings {
 	}
 	return rankings
 }
+
+// MustConfigureScoring loads the scoring model and validates it against the tiers of the
+// influencer rankings. Exits on error.
+func MustConfigureScoring(filePath string, rankings *influencer.InfluencerRankings) {
+	config, err := mindshare.LoadScoringConfig(filePath)
+	if err != nil {
+		log.Fatalf("Failed to load scoring config: %v", err)
+	}
+
+	tierCounts := rankings.TierCounts()
+	if err := mindshare.Configure(*config, tierCounts); err != nil {
+		log.Fatalf("Invalid scoring config for influencer rankings: %v", err)
+	}
+	log.Printf("Scoring configured for influencer tiers %v", tierCounts)
+}
+
+// MustConfigureDecay sets the half-life of mention weights. Exits unless it is a
+// positive duration.
+func MustConfigureDecay(halfLife string) {
+	d, err := time.ParseDuration(halfLife)
+	if err != nil || d <= 0 {
+		log.Fatalf("Invalid MINDSHARE_HALF_LIFE %q, expected a positive duration such as 72h", halfLife)
+	}
+	mindshare.DecayHalfLife = d
+}
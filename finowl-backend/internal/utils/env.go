@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -13,11 +15,35 @@ const (
 	// Discord related constants
 	discordTokenKey = "DISCORD_BOT_TOKEN"
 	channelIDKey    = "DISCORD_Macro_News_CHANNEL_ID"
+	// channelCategoriesKey holds a comma separated list of channelID:Category pairs,
+	// e.g. "1234:EarlyAlpha,5678:MacroNews"
+	channelCategoriesKey = "DISCORD_CHANNEL_CATEGORIES"
+	// guildIDKey optionally restricts the slash commands to a guild, where they are available at once
+	guildIDKey = "DISCORD_GUILD_ID"
+	// Channels the bot posts to; posting is disabled while unset
+	summaryChannelIDKey = "DISCORD_SUMMARY_CHANNEL_ID"
+	alertsChannelIDKey  = "DISCORD_ALERTS_CHANNEL_ID"
 
 	// AI API related constants
 	claudeAPIKeyKey         = "CLAUDE_API"
 	aiGenSummaryIntervalKey = "AI_GEN_SUMMARY_INTERVAL"
 
+	// Mindshare scoring related constants
+	mindshareHalfLifeKey        = "MINDSHARE_HALF_LIFE"
+	mindshareRescoreIntervalKey = "MINDSHARE_RESCORE_INTERVAL"
+
+	// adminAPITokenKey holds the bearer token of the admin API, which is disabled when unset
+	adminAPITokenKey = "ADMIN_API_TOKEN"
+	// ingestAPITokenKey holds the bearer token of the ingest API, which is disabled when unset
+	ingestAPITokenKey = "INGEST_API_TOKEN"
+
+	// Collector workers processing the messages of the collected channels, and
+	// how many messages may wait for them before the gateway is slowed down
+	collectorWorkersKey   = "COLLECTOR_WORKERS"
+	collectorQueueSizeKey = "COLLECTOR_QUEUE_SIZE"
+	// deadLetterSpoolKey holds the file dead letters are spooled to while the database is down
+	deadLetterSpoolKey = "DEAD_LETTER_SPOOL"
+
 	// Database related constants
 	dbHostKey     = "FINOWL_DB_HOST"
 	dbPortKey     = "FINOWL_DB_PORT"
@@ -26,10 +52,22 @@ const (
 	dbNameKey     = "FINOWL_DB_NAME"
 )
 
+// Defaults applied when the optional environment variables are not set
+const (
+	defaultMindshareHalfLife        = "72h"
+	defaultMindshareRescoreInterval = "15m"
+	defaultCollectorWorkers         = 4
+	defaultCollectorQueueSize       = 256
+)
+
 // AppConfig holds all the environment configuration for the application
 type AppConfig struct {
 	DiscordToken         string
 	ChannelID            string
+	ChannelCategories    map[string]string // channel ID -> category
+	DiscordGuildID       string            // Guild of the slash commands, empty registers them globally
+	SummaryChannelID     string            // Channel new summaries are posted to, empty disables it
+	AlertsChannelID      string            // Channel top category transitions are posted to, empty disables it
 	ClaudeAPIKey         string
 	DBHost               string
 	DBPort               string
@@ -37,6 +75,17 @@ type AppConfig struct {
 	DBPassword           string
 	DBName               string
 	AIGenSummaryInterval string
+
+	MindshareHalfLife        string // Half-life of a mention's weight
+	MindshareRescoreInterval string // How often every ticker is rescored
+
+	AdminAPIToken  string // Bearer token of the admin API, empty disables it
+	IngestAPIToken string // Bearer token of the ingest API, empty disables it
+
+	CollectorWorkers   int // Workers processing collected messages
+	CollectorQueueSize int // Messages waiting for the workers before the bot stops reading events
+
+	DeadLetterSpool string // File dead letters are spooled to while the database is down, empty for the default
 }
 
 // LoadAppConfig loads and validates the environment variables from the .env file.
@@ -50,6 +99,9 @@ func LoadAppConfig() (*AppConfig, error) {
 	config := &AppConfig{
 		DiscordToken:         os.Getenv(discordTokenKey),
 		ChannelID:            os.Getenv(channelIDKey),
+		DiscordGuildID:       os.Getenv(guildIDKey),
+		SummaryChannelID:     os.Getenv(summaryChannelIDKey),
+		AlertsChannelID:      os.Getenv(alertsChannelIDKey),
 		ClaudeAPIKey:         os.Getenv(claudeAPIKeyKey),
 		AIGenSummaryInterval: os.Getenv(aiGenSummaryIntervalKey),
 		DBHost:               os.Getenv(dbHostKey),
@@ -57,6 +109,27 @@ func LoadAppConfig() (*AppConfig, error) {
 		DBUser:               os.Getenv(dbUserKey),
 		DBPassword:           os.Getenv(dbPasswordKey),
 		DBName:               os.Getenv(dbNameKey),
+
+		MindshareHalfLife:        getEnvOrDefault(mindshareHalfLifeKey, defaultMindshareHalfLife),
+		MindshareRescoreInterval: getEnvOrDefault(mindshareRescoreIntervalKey, defaultMindshareRescoreInterval),
+
+		AdminAPIToken:  os.Getenv(adminAPITokenKey),
+		IngestAPIToken: os.Getenv(ingestAPITokenKey),
+
+		DeadLetterSpool: os.Getenv(deadLetterSpoolKey),
+	}
+
+	channelCategories, err := ParseChannelCategories(os.Getenv(channelCategoriesKey))
+	if err != nil {
+		return nil, fmt.Errorf("environment variable %s is invalid: %w", channelCategoriesKey, err)
+	}
+	config.ChannelCategories = channelCategories
+
+	if config.CollectorWorkers, err = getEnvIntOrDefault(collectorWorkersKey, defaultCollectorWorkers); err != nil {
+		return nil, err
+	}
+	if config.CollectorQueueSize, err = getEnvIntOrDefault(collectorQueueSizeKey, defaultCollectorQueueSize); err != nil {
+		return nil, err
 	}
 
 	// Validate that all required environment variables are set
@@ -67,20 +140,76 @@ func LoadAppConfig() (*AppConfig, error) {
 	return config, nil
 }
 
+// LoadToolConfig loads the environment of the command line tools working on
+// the database. The bot and AI credentials are not needed, the database
+// variables only when requireDB is set.
+func LoadToolConfig(requireDB bool) (*AppConfig, error) {
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Error loading .env file: %v", err)
+	}
+
+	config := &AppConfig{
+		ChannelID:         os.Getenv(channelIDKey),
+		DBHost:            os.Getenv(dbHostKey),
+		DBPort:            os.Getenv(dbPortKey),
+		DBUser:            os.Getenv(dbUserKey),
+		DBPassword:        os.Getenv(dbPasswordKey),
+		DBName:            os.Getenv(dbNameKey),
+		MindshareHalfLife: getEnvOrDefault(mindshareHalfLifeKey, defaultMindshareHalfLife),
+	}
+
+	channelCategories, err := ParseChannelCategories(os.Getenv(channelCategoriesKey))
+	if err != nil {
+		return nil, fmt.Errorf("environment variable %s is invalid: %w", channelCategoriesKey, err)
+	}
+	config.ChannelCategories = channelCategories
+
+	if requireDB {
+		if err := validateDBConfig(config); err != nil {
+			return nil, err
+		}
+	}
+	return config, nil
+}
+
+// getEnvOrDefault returns the value of an environment variable, or fallback when it is not set
+func getEnvOrDefault(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getEnvIntOrDefault returns the positive integer held by an environment
+// variable, or fallback when it is not set
+func getEnvIntOrDefault(key string, fallback int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("environment variable %s must be a positive integer, got %q", key, value)
+	}
+	return n, nil
+}
+
 // validateConfig checks that all required environment variables are present
 func validateConfig(config *AppConfig) error {
 	if config.DiscordToken == "" {
 		return fmt.Errorf("environment variable %s is required but not set", discordTokenKey)
 	}
-	if config.ChannelID == "" {
-		return fmt.Errorf("environment variable %s is required but not set", channelIDKey)
-	}
 	if config.ClaudeAPIKey == "" {
 		return fmt.Errorf("environment variable %s is required but not set", claudeAPIKeyKey)
 	}
 	if config.AIGenSummaryInterval == "" {
 		return fmt.Errorf("environment variable %s is required but not set", aiGenSummaryIntervalKey)
 	}
+	return validateDBConfig(config)
+}
+
+// validateDBConfig checks that the database environment variables are present
+func validateDBConfig(config *AppConfig) error {
 	if config.DBHost == "" {
 		return fmt.Errorf("environment variable %s is required but not set", dbHostKey)
 	}
@@ -99,9 +228,50 @@ func validateConfig(config *AppConfig) error {
 	return nil
 }
 
+// ParseChannelCategories parses a "channelID:Category,channelID:Category" list
+// into a channel ID -> category map. An empty string yields an empty map.
+func ParseChannelCategories(raw string) (map[string]string, error) {
+	channels := make(map[string]string)
+	for _, pair := range strings.Split(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		channelID, category, found := strings.Cut(pair, ":")
+		channelID = strings.TrimSpace(channelID)
+		category = strings.TrimSpace(category)
+		if !found || channelID == "" || category == "" {
+			return nil, fmt.Errorf("malformed channel mapping %q (expected channelID:Category)", pair)
+		}
+
+		channels[channelID] = category
+	}
+	return channels, nil
+}
+
 type Prompt struct {
 	Prompts map[string]struct {
 		Prompt string   `yaml:"prompt"`
 		Coins  []string `yaml:"coins"`
 	} `yaml:"prompts"`
+	// Channels maps Discord channel IDs to the category their messages belong to
+	Channels map[string]string `yaml:"channels"`
+}
+
+// CategoryPrompts returns the summary prompt of every configured category,
+// with the category's focus coins appended when present.
+func (p Prompt) CategoryPrompts() map[string]string {
+	prompts := make(map[string]string, len(p.Prompts))
+	for category, cfg := range p.Prompts {
+		prompt := strings.TrimSpace(cfg.Prompt)
+		if prompt == "" {
+			continue
+		}
+		if len(cfg.Coins) > 0 {
+			prompt += "\n\nCoins to pay particular attention to: " + strings.Join(cfg.Coins, ", ")
+		}
+		prompts[category] = prompt
+	}
+	return prompts
 }
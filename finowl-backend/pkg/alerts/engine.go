@@ -0,0 +1,459 @@
+package alerts
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"finowl-backend/pkg/ticker"
+)
+
+// Headers of a webhook request
+const (
+	SignatureHeader = "X-Finowl-Signature" // "sha256=" + hex HMAC of "<timestamp>.<body>"
+	TimestampHeader = "X-Finowl-Timestamp" // Unix seconds the request was signed at
+	DeliveryHeader  = "X-Finowl-Delivery"  // ID of the delivery in the log
+)
+
+// DeliveryStatus is the state of a webhook delivery
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// Delivery is an entry of the delivery log: one fired alert and its webhook attempts
+type Delivery struct {
+	ID             int64           `json:"id"`
+	RuleID         int64           `json:"rule_id"`
+	Ticker         string          `json:"ticker_symbol"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         DeliveryStatus  `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"response_status,omitempty"` // HTTP status of the last attempt
+	LastError      string          `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+// Payload is the JSON body posted to the webhook of a fired rule
+type Payload struct {
+	RuleID           int64            `json:"rule_id"`
+	RuleName         string           `json:"rule_name"`
+	Type             RuleType         `json:"type"`
+	Ticker           string           `json:"ticker_symbol"`
+	Reason           string           `json:"reason"`
+	FiredAt          time.Time        `json:"fired_at"`
+	MindshareScore   float64          `json:"mindshare_score"`
+	PreviousScore    float64          `json:"previous_score"`
+	Category         string           `json:"category"`
+	PreviousCategory string           `json:"previous_category,omitempty"`
+	Mentions         []ticker.Mention `json:"mentions,omitempty"`
+}
+
+// Store persists rules and deliveries and gives access to score history
+type Store interface {
+	GetEnabledAlertRules() ([]Rule, error)
+	// ScoreAt returns the mindshare score a ticker had at the given time
+	ScoreAt(symbol string, at time.Time) (float64, error)
+	// InsertAlertDelivery records a fired alert and sets its ID, unless the
+	// rule already fired for the ticker within cooldown before d.CreatedAt.
+	// Concurrent calls for the same rule and ticker are serialized, so only
+	// one of them records a delivery.
+	InsertAlertDelivery(d *Delivery, cooldown time.Duration) (bool, error)
+	UpdateAlertDelivery(d *Delivery) error
+	// PendingAlertDeliveries returns the deliveries left pending, oldest first
+	PendingAlertDeliveries() ([]Delivery, error)
+}
+
+// Config tunes the delivery of webhooks
+type Config struct {
+	Workers      int           // Concurrent webhook deliveries
+	QueueSize    int           // Fired alerts waiting for a worker
+	MaxAttempts  int           // Attempts per delivery, including the first one
+	Backoff      time.Duration // Delay before the first retry, doubled on every retry
+	Timeout      time.Duration // Timeout of a single webhook request
+	RulesRefresh time.Duration // How long loaded rules are used before being reloaded
+}
+
+// DefaultConfig returns the delivery settings used in production
+func DefaultConfig() Config {
+	return Config{
+		Workers:      4,
+		QueueSize:    256,
+		MaxAttempts:  5,
+		Backoff:      2 * time.Second,
+		Timeout:      10 * time.Second,
+		RulesRefresh: 30 * time.Second,
+	}
+}
+
+// job is a delivery waiting to be sent
+type job struct {
+	rule     Rule
+	delivery *Delivery
+}
+
+// Engine evaluates the alert rules against ticker changes and delivers the
+// fired alerts to their webhooks in the background, so a slow or failing
+// receiver never holds up ingestion. Deliveries interrupted by a shutdown stay
+// pending in the delivery log and are resumed by the next engine.
+type Engine struct {
+	store  Store
+	config Config
+	client *http.Client
+	now    func() time.Time
+
+	mu       sync.Mutex
+	rules    []Rule
+	loadedAt time.Time
+	closed   bool
+
+	queue   chan job
+	stop    chan struct{} // Closed by Close, interrupts the retries
+	wg      sync.WaitGroup
+	feeders sync.WaitGroup // Resumed deliveries waiting for room in the queue
+}
+
+// NewEngine creates an Engine and starts its delivery workers
+func NewEngine(store Store, config Config) *Engine {
+	e := &Engine{
+		store:  store,
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		now:    func() time.Time { return time.Now().UTC() },
+		queue:  make(chan job, config.QueueSize),
+		stop:   make(chan struct{}),
+	}
+
+	for i := 0; i < config.Workers; i++ {
+		e.wg.Add(1)
+		go e.worker()
+	}
+	return e
+}
+
+// Resume queues the deliveries a previous engine left pending. Those of rules
+// disabled or deleted meanwhile are failed.
+func (e *Engine) Resume() error {
+	pending, err := e.store.PendingAlertDeliveries()
+	if err != nil {
+		return err
+	}
+	rules, err := e.store.GetEnabledAlertRules()
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[int64]Rule, len(rules))
+	for _, r := range rules {
+		byID[r.ID] = r
+	}
+
+	var jobs []job
+	for i := range pending {
+		d := &pending[i]
+		rule, ok := byID[d.RuleID]
+		if !ok {
+			if err := e.fail(d, "alert rule is disabled or deleted"); err != nil {
+				log.Printf("Error resuming alert delivery: %v", err)
+			}
+			continue
+		}
+		jobs = append(jobs, job{rule: rule, delivery: d})
+	}
+	if len(jobs) == 0 {
+		return nil
+	}
+	log.Printf("Resuming %d pending alert deliveries", len(jobs))
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return nil
+	}
+
+	// More deliveries than the queue holds wait for the workers
+	e.feeders.Add(1)
+	go func() {
+		defer e.feeders.Done()
+		for _, j := range jobs {
+			select {
+			case e.queue <- j:
+			case <-e.stop:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Close stops accepting alerts and waits for the attempts in flight. Queued
+// deliveries and those waiting for a retry are left pending for Resume.
+func (e *Engine) Close() {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return
+	}
+	e.closed = true
+	close(e.stop)
+	e.mu.Unlock()
+
+	e.feeders.Wait()
+	close(e.queue)
+	e.wg.Wait()
+}
+
+// stopping reports whether Close was called
+func (e *Engine) stopping() bool {
+	select {
+	case <-e.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// Evaluate checks every enabled rule against a change and queues a delivery
+// for each rule that fires. Errors are logged: alerting never fails ingestion.
+func (e *Engine) Evaluate(c Change) {
+	for _, rule := range e.loadRules() {
+		if !rule.matchesTicker(c) {
+			continue
+		}
+
+		reason, fired, err := e.matches(rule, c)
+		if err != nil {
+			log.Printf("Error evaluating alert rule %d on %s: %v", rule.ID, c.Ticker, err)
+			continue
+		}
+		if !fired {
+			continue
+		}
+
+		if err := e.fire(rule, c, reason); err != nil {
+			log.Printf("Error firing alert rule %d on %s: %v", rule.ID, c.Ticker, err)
+		}
+	}
+}
+
+// loadRules returns the enabled rules, reloading them once they are older than RulesRefresh.
+// The previous rules are kept when they cannot be reloaded.
+func (e *Engine) loadRules() []Rule {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.loadedAt.IsZero() && e.now().Sub(e.loadedAt) < e.config.RulesRefresh {
+		return e.rules
+	}
+
+	rules, err := e.store.GetEnabledAlertRules()
+	if err != nil {
+		log.Printf("Error loading alert rules: %v", err)
+		return e.rules
+	}
+
+	e.rules = rules
+	e.loadedAt = e.now()
+	return e.rules
+}
+
+// matches reports whether a rule fires on a change, along with a human readable reason
+func (e *Engine) matches(rule Rule, c Change) (string, bool, error) {
+	switch rule.Type {
+	case CategoryEntered:
+		if c.NewCategory == rule.Category && c.OldCategory != rule.Category {
+			return fmt.Sprintf("%s entered %s", c.Ticker, rule.Category), true, nil
+		}
+
+	case ScoreRise:
+		// Only a change that raised the score can complete a rise
+		if c.NewScore <= c.OldScore {
+			return "", false, nil
+		}
+		baseline, err := e.store.ScoreAt(c.Ticker, c.At.Add(-rule.Window()))
+		if err != nil {
+			return "", false, err
+		}
+		if c.NewScore-baseline > rule.Threshold {
+			return fmt.Sprintf("%s score rose from %.2f to %.2f in %s", c.Ticker, baseline, c.NewScore, rule.Window()), true, nil
+		}
+
+	case NewTickerMention:
+		if !c.Created {
+			return "", false, nil
+		}
+		for _, m := range c.Mentions {
+			if m.Tier == rule.Tier {
+				return fmt.Sprintf("tier %d influencer %s mentioned new ticker %s", m.Tier, m.Author, c.Ticker), true, nil
+			}
+		}
+	}
+
+	return "", false, nil
+}
+
+// fire records a delivery for a rule that fired and queues it, unless the
+// rule already fired for the ticker within its cooldown
+func (e *Engine) fire(rule Rule, c Change, reason string) error {
+	now := e.now()
+
+	payload, err := json.Marshal(&Payload{
+		RuleID:           rule.ID,
+		RuleName:         rule.Name,
+		Type:             rule.Type,
+		Ticker:           c.Ticker,
+		Reason:           reason,
+		FiredAt:          now,
+		MindshareScore:   c.NewScore,
+		PreviousScore:    c.OldScore,
+		Category:         c.NewCategory,
+		PreviousCategory: c.OldCategory,
+		Mentions:         c.Mentions,
+	})
+	if err != nil {
+		return err
+	}
+
+	d := &Delivery{
+		RuleID:    rule.ID,
+		Ticker:    c.Ticker,
+		Payload:   payload,
+		Status:    DeliveryPending,
+		CreatedAt: now,
+	}
+	inserted, err := e.store.InsertAlertDelivery(d, rule.Cooldown())
+	if err != nil || !inserted {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.closed {
+		log.Printf("Alert engine is closed, delivery %d is left pending", d.ID)
+		return nil
+	}
+
+	select {
+	case e.queue <- job{rule: rule, delivery: d}:
+		return nil
+	default:
+		return e.fail(d, "delivery queue is full")
+	}
+}
+
+// fail marks a delivery as failed without attempting it
+func (e *Engine) fail(d *Delivery, reason string) error {
+	d.Status = DeliveryFailed
+	d.LastError = reason
+	if err := e.store.UpdateAlertDelivery(d); err != nil {
+		return err
+	}
+	return fmt.Errorf("delivery %d dropped: %s", d.ID, reason)
+}
+
+func (e *Engine) worker() {
+	defer e.wg.Done()
+
+	for j := range e.queue {
+		if e.stopping() {
+			continue
+		}
+		e.deliver(j.rule, j.delivery)
+	}
+}
+
+// deliver posts a delivery to the webhook of its rule, retrying with
+// exponential backoff, and records every attempt in the delivery log. A
+// delivery waiting for a retry when the engine closes is left pending.
+func (e *Engine) deliver(rule Rule, d *Delivery) {
+	backoff := e.config.Backoff
+
+	for {
+		d.Attempts++
+		status, err := e.post(rule, d)
+		d.ResponseStatus = status
+
+		if err == nil {
+			deliveredAt := e.now()
+			d.Status = DeliveryDelivered
+			d.LastError = ""
+			d.DeliveredAt = &deliveredAt
+		} else {
+			d.LastError = err.Error()
+			if d.Attempts >= e.config.MaxAttempts || !retryable(status) {
+				d.Status = DeliveryFailed
+			}
+		}
+
+		if err := e.store.UpdateAlertDelivery(d); err != nil {
+			log.Printf("Error recording alert delivery %d: %v", d.ID, err)
+		}
+		if d.Status != DeliveryPending {
+			return
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-e.stop:
+			return
+		}
+		backoff *= 2
+	}
+}
+
+// post sends the signed payload once and returns the response status
+func (e *Engine) post(rule Rule, d *Delivery) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, rule.WebhookURL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := e.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(rule.Secret, timestamp, d.Payload))
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(d.ID, 10))
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// retryable reports whether an attempt ending with status may succeed later.
+// Network errors (status 0), rate limiting and server errors are retried.
+func retryable(status int) bool {
+	return status == 0 || status == http.StatusTooManyRequests || status >= 500
+}
+
+// Sign returns the signature header value of a payload: the hex HMAC-SHA256
+// of "<timestamp>.<body>" keyed by the rule secret. Receivers recompute it to
+// authenticate the request and reject stale timestamps to prevent replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
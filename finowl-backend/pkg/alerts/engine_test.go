@@ -0,0 +1,357 @@
+package alerts
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"finowl-backend/pkg/mindshare"
+	"finowl-backend/pkg/ticker"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	if err := mindshare.Configure(mindshare.DefaultScoringConfig(), map[int]int{1: 5, 2: 15, 3: 33}); err != nil {
+		log.Fatalf("failed to configure scoring: %v", err)
+	}
+	os.Exit(m.Run())
+}
+
+// memoryStore keeps rules and deliveries in memory
+type memoryStore struct {
+	mu         sync.Mutex
+	rules      []Rule
+	scores     map[string]float64 // ticker -> score at any past time
+	deliveries []Delivery
+	updates    chan Delivery
+}
+
+func newMemoryStore(rules ...Rule) *memoryStore {
+	return &memoryStore{rules: rules, scores: map[string]float64{}, updates: make(chan Delivery, 16)}
+}
+
+func (s *memoryStore) GetEnabledAlertRules() ([]Rule, error) {
+	return s.rules, nil
+}
+
+func (s *memoryStore) ScoreAt(symbol string, at time.Time) (float64, error) {
+	return s.scores[symbol], nil
+}
+
+func (s *memoryStore) InsertAlertDelivery(d *Delivery, cooldown time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, previous := range s.deliveries {
+		if previous.RuleID == d.RuleID && previous.Ticker == d.Ticker && previous.CreatedAt.After(d.CreatedAt.Add(-cooldown)) {
+			return false, nil
+		}
+	}
+
+	d.ID = int64(len(s.deliveries) + 1)
+	s.deliveries = append(s.deliveries, *d)
+	return true, nil
+}
+
+func (s *memoryStore) UpdateAlertDelivery(d *Delivery) error {
+	s.mu.Lock()
+	s.deliveries[d.ID-1] = *d
+	s.mu.Unlock()
+
+	s.updates <- *d
+	return nil
+}
+
+func (s *memoryStore) PendingAlertDeliveries() ([]Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pending []Delivery
+	for _, d := range s.deliveries {
+		if d.Status == DeliveryPending {
+			pending = append(pending, d)
+		}
+	}
+	return pending, nil
+}
+
+func (s *memoryStore) fired() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.deliveries)
+}
+
+func testConfig() Config {
+	return Config{Workers: 1, QueueSize: 8, MaxAttempts: 3, Backoff: time.Millisecond, Timeout: time.Second, RulesRefresh: time.Minute}
+}
+
+func TestRuleValidate(t *testing.T) {
+	valid := Rule{Name: "alpha", Type: CategoryEntered, Category: "High Alpha", WebhookURL: "https://example.com/hook", CooldownSeconds: 3600}
+	require.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name   string
+		modify func(r *Rule)
+	}{
+		{"missing name", func(r *Rule) { r.Name = "" }},
+		{"unknown type", func(r *Rule) { r.Type = "moon" }},
+		{"unknown category", func(r *Rule) { r.Category = "Moon" }},
+		{"score rise without threshold", func(r *Rule) { r.Type = ScoreRise; r.WindowSeconds = 3600 }},
+		{"score rise window too short", func(r *Rule) { r.Type = ScoreRise; r.Threshold = 50; r.WindowSeconds = 1 }},
+		{"unknown tier", func(r *Rule) { r.Type = NewTickerMention; r.Tier = 9 }},
+		{"negative cooldown", func(r *Rule) { r.CooldownSeconds = -1 }},
+		{"relative webhook", func(r *Rule) { r.WebhookURL = "/hook" }},
+		{"non http webhook", func(r *Rule) { r.WebhookURL = "ftp://example.com/hook" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := valid
+			tt.modify(&rule)
+			assert.Error(t, rule.Validate())
+		})
+	}
+}
+
+func TestEngineMatches(t *testing.T) {
+	store := newMemoryStore()
+	store.scores["AIXBT"] = 100
+	e := &Engine{store: store}
+
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		rule   Rule
+		change Change
+		fired  bool
+	}{
+		{
+			"enters category",
+			Rule{Type: CategoryEntered, Category: "High Alpha"},
+			Change{Ticker: "AIXBT", OldCategory: "Alpha", NewCategory: "High Alpha"},
+			true,
+		},
+		{
+			"stays in category",
+			Rule{Type: CategoryEntered, Category: "High Alpha"},
+			Change{Ticker: "AIXBT", OldCategory: "High Alpha", NewCategory: "High Alpha"},
+			false,
+		},
+		{
+			"score rose over the window",
+			Rule{Type: ScoreRise, Threshold: 50, WindowSeconds: 3600},
+			Change{Ticker: "AIXBT", OldScore: 140, NewScore: 160, At: at},
+			true,
+		},
+		{
+			"score rose less than the threshold",
+			Rule{Type: ScoreRise, Threshold: 50, WindowSeconds: 3600},
+			Change{Ticker: "AIXBT", OldScore: 120, NewScore: 140, At: at},
+			false,
+		},
+		{
+			"score fell",
+			Rule{Type: ScoreRise, Threshold: 50, WindowSeconds: 3600},
+			Change{Ticker: "AIXBT", OldScore: 170, NewScore: 160, At: at},
+			false,
+		},
+		{
+			"tier 1 mentions a new ticker",
+			Rule{Type: NewTickerMention, Tier: 1},
+			Change{Ticker: "AIXBT", Created: true, Mentions: []ticker.Mention{{Author: "whale", Tier: 1}}},
+			true,
+		},
+		{
+			"tier 3 mentions a new ticker",
+			Rule{Type: NewTickerMention, Tier: 1},
+			Change{Ticker: "AIXBT", Created: true, Mentions: []ticker.Mention{{Author: "degen", Tier: 3}}},
+			false,
+		},
+		{
+			"tier 1 mentions a known ticker",
+			Rule{Type: NewTickerMention, Tier: 1},
+			Change{Ticker: "AIXBT", Mentions: []ticker.Mention{{Author: "whale", Tier: 1}}},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, fired, err := e.matches(tt.rule, tt.change)
+			require.NoError(t, err)
+			assert.Equal(t, tt.fired, fired)
+			if fired {
+				assert.Contains(t, reason, "AIXBT")
+			}
+		})
+	}
+}
+
+func TestEngineDeliversSignedPayloadWithRetries(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, err := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+		require.NoError(t, err)
+		assert.Equal(t, Sign("s3cret-s3cret-s3cret", timestamp, body), r.Header.Get(SignatureHeader))
+		assert.Equal(t, "1", r.Header.Get(DeliveryHeader))
+
+		var payload Payload
+		require.NoError(t, json.Unmarshal(body, &payload))
+		assert.Equal(t, "AIXBT", payload.Ticker)
+		assert.Equal(t, "High Alpha", payload.Category)
+
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer webhook.Close()
+
+	store := newMemoryStore(Rule{ID: 7, Name: "alpha", Type: CategoryEntered, Category: "High Alpha",
+		WebhookURL: webhook.URL, Secret: "s3cret-s3cret-s3cret", CooldownSeconds: 3600})
+	engine := NewEngine(store, testConfig())
+	defer engine.Close()
+
+	change := Change{Ticker: "AIXBT", OldCategory: "Alpha", NewCategory: "High Alpha", NewScore: 310}
+	engine.Evaluate(change)
+
+	first := <-store.updates
+	assert.Equal(t, DeliveryPending, first.Status)
+	assert.Equal(t, http.StatusBadGateway, first.ResponseStatus)
+
+	second := <-store.updates
+	assert.Equal(t, DeliveryDelivered, second.Status)
+	assert.Equal(t, 2, second.Attempts)
+	assert.NotNil(t, second.DeliveredAt)
+	assert.Empty(t, second.LastError)
+
+	// The cooldown holds back the same alert for the ticker
+	engine.Evaluate(change)
+	assert.Equal(t, 1, store.fired())
+}
+
+func TestEngineGivesUpOnClientErrors(t *testing.T) {
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer webhook.Close()
+
+	store := newMemoryStore(Rule{ID: 1, Name: "new", Type: NewTickerMention, Tier: 1, WebhookURL: webhook.URL, Secret: "s3cret-s3cret-s3cret"})
+	engine := NewEngine(store, testConfig())
+	defer engine.Close()
+
+	engine.Evaluate(Change{Ticker: "AIXBT", Created: true, Mentions: []ticker.Mention{{Author: "whale", Tier: 1}}})
+
+	d := <-store.updates
+	assert.Equal(t, DeliveryFailed, d.Status)
+	assert.Equal(t, 1, d.Attempts)
+	assert.Equal(t, http.StatusGone, d.ResponseStatus)
+	assert.Contains(t, d.LastError, "410")
+}
+
+func TestEngineCloseLeavesRetriesPending(t *testing.T) {
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer webhook.Close()
+
+	store := newMemoryStore(Rule{ID: 1, Name: "new", Type: NewTickerMention, Tier: 1, WebhookURL: webhook.URL, Secret: "s3cret-s3cret-s3cret"})
+	config := testConfig()
+	config.Backoff = time.Hour
+	engine := NewEngine(store, config)
+
+	engine.Evaluate(Change{Ticker: "AIXBT", Created: true, Mentions: []ticker.Mention{{Author: "whale", Tier: 1}}})
+	d := <-store.updates
+	assert.Equal(t, DeliveryPending, d.Status)
+
+	// Close does not wait for the retry an hour later
+	closed := make(chan struct{})
+	go func() {
+		engine.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close waited for the retry")
+	}
+
+	// Alerts fired after Close are left pending as well
+	engine.Evaluate(Change{Ticker: "ETH", Created: true, Mentions: []ticker.Mention{{Author: "whale", Tier: 1}}})
+
+	pending, err := store.PendingAlertDeliveries()
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, 1, pending[0].Attempts)
+	assert.Zero(t, pending[1].Attempts)
+}
+
+func TestEngineResume(t *testing.T) {
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer webhook.Close()
+
+	store := newMemoryStore(Rule{ID: 1, Name: "new", Type: NewTickerMention, Tier: 1, WebhookURL: webhook.URL, Secret: "s3cret-s3cret-s3cret"})
+	store.deliveries = []Delivery{
+		{ID: 1, RuleID: 1, Ticker: "AIXBT", Payload: json.RawMessage(`{}`), Status: DeliveryPending, Attempts: 1},
+		{ID: 2, RuleID: 9, Ticker: "ETH", Payload: json.RawMessage(`{}`), Status: DeliveryPending},
+	}
+
+	engine := NewEngine(store, testConfig())
+	defer engine.Close()
+	require.NoError(t, engine.Resume())
+
+	// The rule of the second delivery is gone, the first one is sent again
+	updates := map[int64]Delivery{}
+	for range 2 {
+		d := <-store.updates
+		updates[d.ID] = d
+	}
+	assert.Equal(t, DeliveryDelivered, updates[1].Status)
+	assert.Equal(t, 2, updates[1].Attempts)
+	assert.Equal(t, DeliveryFailed, updates[2].Status)
+	assert.Equal(t, "alert rule is disabled or deleted", updates[2].LastError)
+}
+
+func TestEngineCooldownWithConcurrentChanges(t *testing.T) {
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer webhook.Close()
+
+	store := newMemoryStore(Rule{ID: 1, Name: "new", Type: NewTickerMention, Tier: 1, WebhookURL: webhook.URL,
+		Secret: "s3cret-s3cret-s3cret", CooldownSeconds: 3600})
+	engine := NewEngine(store, testConfig())
+	defer engine.Close()
+
+	// Workers evaluating changes of the same ticker at once fire the rule once
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			engine.Evaluate(Change{Ticker: "AIXBT", Created: true, Mentions: []ticker.Mention{{Author: "whale", Tier: 1}}})
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, store.fired())
+	assert.Equal(t, DeliveryDelivered, (<-store.updates).Status)
+}
@@ -0,0 +1,124 @@
+package alerts
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"finowl-backend/pkg/mindshare"
+	"finowl-backend/pkg/ticker"
+)
+
+// RuleType selects the condition a rule watches for
+type RuleType string
+
+const (
+	// CategoryEntered fires when a ticker moves into Category, e.g. "High Alpha"
+	CategoryEntered RuleType = "category_entered"
+	// ScoreRise fires when the score of a ticker rose by more than Threshold over the last WindowSeconds
+	ScoreRise RuleType = "score_rise"
+	// NewTickerMention fires when an influencer of Tier mentions a ticker never seen before
+	NewTickerMention RuleType = "new_ticker_mention"
+)
+
+const (
+	maxRuleNameLength = 100
+	maxRuleWindow     = 7 * 24 * 60 * 60 // seconds
+)
+
+// Rule is an alert registered through the API
+type Rule struct {
+	ID   int64    `json:"id"`
+	Name string   `json:"name"`
+	Type RuleType `json:"type"`
+
+	Ticker    string  `json:"ticker,omitempty"`    // Only watch this ticker, empty for all
+	Category  string  `json:"category,omitempty"`  // CategoryEntered
+	Threshold float64 `json:"threshold,omitempty"` // ScoreRise
+	Tier      int     `json:"tier,omitempty"`      // NewTickerMention
+
+	WindowSeconds   int `json:"window_seconds,omitempty"` // ScoreRise
+	CooldownSeconds int `json:"cooldown_seconds"`         // Minimum delay between two alerts of a ticker
+
+	WebhookURL string `json:"webhook_url"`
+	Secret     string `json:"-"` // HMAC key signing the webhook payloads
+	Enabled    bool   `json:"enabled"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Change describes how a ticker was affected by newly stored mentions
+type Change struct {
+	Ticker      string
+	Created     bool // The ticker was mentioned for the first time
+	OldScore    float64
+	NewScore    float64
+	OldCategory string
+	NewCategory string
+	Mentions    []ticker.Mention // Mentions stored by the change
+	At          time.Time
+}
+
+// Validate checks that the rule is complete and consistent with the scoring model
+func (r Rule) Validate() error {
+	if r.Name == "" || len(r.Name) > maxRuleNameLength {
+		return fmt.Errorf("name must be between 1 and %d characters", maxRuleNameLength)
+	}
+
+	switch r.Type {
+	case CategoryEntered:
+		if !isScoringCategory(r.Category) {
+			return fmt.Errorf("unknown category %q", r.Category)
+		}
+	case ScoreRise:
+		if r.Threshold <= 0 {
+			return fmt.Errorf("threshold must be positive")
+		}
+		if r.WindowSeconds < 60 || r.WindowSeconds > maxRuleWindow {
+			return fmt.Errorf("window_seconds must be between 60 and %d", maxRuleWindow)
+		}
+	case NewTickerMention:
+		if _, ok := mindshare.Scoring.Tiers[r.Tier]; !ok {
+			return fmt.Errorf("unknown tier %d", r.Tier)
+		}
+	default:
+		return fmt.Errorf("unknown rule type %q", r.Type)
+	}
+
+	if r.CooldownSeconds < 0 || r.CooldownSeconds > maxRuleWindow {
+		return fmt.Errorf("cooldown_seconds must be between 0 and %d", maxRuleWindow)
+	}
+
+	webhook, err := url.Parse(r.WebhookURL)
+	if err != nil || (webhook.Scheme != "http" && webhook.Scheme != "https") || webhook.Host == "" {
+		return fmt.Errorf("webhook_url must be an absolute http(s) URL")
+	}
+
+	return nil
+}
+
+// Window returns the ScoreRise window
+func (r Rule) Window() time.Duration {
+	return time.Duration(r.WindowSeconds) * time.Second
+}
+
+// Cooldown returns the minimum delay between two alerts of the rule for a ticker
+func (r Rule) Cooldown() time.Duration {
+	return time.Duration(r.CooldownSeconds) * time.Second
+}
+
+// matchesTicker reports whether the rule watches the ticker of a change
+func (r Rule) matchesTicker(c Change) bool {
+	return r.Ticker == "" || r.Ticker == c.Ticker
+}
+
+// isScoringCategory reports whether category is one of the configured mindshare categories
+func isScoringCategory(category string) bool {
+	for _, c := range mindshare.Scoring.Categories {
+		if c.Name == category {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/google/uuid"
+)
+
+var (
+	// statusURLPattern matches the URL of a tweet and captures the handle of its author
+	statusURLPattern = regexp.MustCompile(`https?://(?:www\.|mobile\.)?(?:twitter|x|fxtwitter|vxtwitter|fixupx)\.com/([A-Za-z0-9_]{1,15})/status(?:es)?/(\d+)`)
+
+	// profileURLPattern matches the profile URL of an account and captures its handle
+	profileURLPattern = regexp.MustCompile(`^https?://(?:www\.|mobile\.)?(?:twitter|x)\.com/([A-Za-z0-9_]{1,15})/?$`)
+
+	// handlePattern matches a Twitter handle in an embed author or title, e.g. "Elon Musk (@elonmusk)"
+	handlePattern = regexp.MustCompile(`@([A-Za-z0-9_]{1,15})\b`)
+
+	// markdownLinkPattern matches the links relays wrap cashtags, mentions and hashtags in
+	markdownLinkPattern = regexp.MustCompile(`\[([^\[\]]+)\]\(<?https?://[^)\s]+>?\)`)
+)
+
+// ProcessDiscordMessage analyzes a relayed Discord message. Tweets relayed as
+// embeds are read from the embed, other messages from their content.
+func (ta *TweetAnalyzer) ProcessDiscordMessage(m *discordgo.Message) *Tweet {
+	author := ""
+	if m.Author != nil {
+		author = m.Author.Username
+	}
+
+	tweet := TweetFromEmbeds(m.Content, m.Embeds, author, m.Timestamp)
+	if tweet == nil {
+		return ta.ProcessMessage(m.Content, author, m.Timestamp)
+	}
+
+	ta.record(tweet)
+	return tweet
+}
+
+// TweetFromEmbeds builds a tweet from the first embed relaying one, or returns
+// nil when there is none. An embed relays a tweet when it has text and shows
+// a status URL or a Twitter handle, which leaves out link previews. The author
+// is the display name of the embed, falling back to rawAuthor, and the status
+// URL comes first in Links.
+func TweetFromEmbeds(content string, embeds []*discordgo.MessageEmbed, rawAuthor string, timestamp time.Time) *Tweet {
+	for _, embed := range embeds {
+		if embed == nil || strings.TrimSpace(embed.Description) == "" {
+			continue
+		}
+
+		name, handle := embedAuthor(embed)
+		statusURL, urlHandle := findStatusURL(embed, content)
+		if statusURL == "" && handle == "" {
+			continue
+		}
+		if handle == "" {
+			handle = urlHandle
+		}
+
+		text := unwrapMarkdownLinks(embed.Description)
+		tweet := &Tweet{
+			ID:        uuid.New().String(),
+			Content:   CleanTweetContent(text),
+			RawAuthor: rawAuthor,
+			Author:    CleanAuthorName(rawAuthor),
+			Handle:    handle,
+			StatusURL: statusURL,
+			Timestamp: timestamp,
+			IsValid:   ValidateTweetContent(text),
+			Tickers:   ExtractTickers(text),
+		}
+		if name != "" {
+			tweet.Author = name
+		} else if handle != "" {
+			tweet.Author = handle
+		}
+
+		if statusURL != "" {
+			tweet.Links = append(tweet.Links, statusURL)
+		}
+		for _, link := range ExtractLinks(content) {
+			if !contains(tweet.Links, link) {
+				tweet.Links = append(tweet.Links, link)
+			}
+		}
+		return tweet
+	}
+	return nil
+}
+
+// embedAuthor returns the display name and handle shown in an embed, from its
+// author line or, for relays without one, from a title like "Name (@handle)"
+func embedAuthor(embed *discordgo.MessageEmbed) (name, handle string) {
+	if embed.Author != nil && embed.Author.Name != "" {
+		name, handle = splitHandle(embed.Author.Name)
+		if match := profileURLPattern.FindStringSubmatch(embed.Author.URL); handle == "" && match != nil {
+			handle = match[1]
+		}
+		return name, handle
+	}
+
+	// Other titles, like "New tweet", are no names
+	if name, handle := splitHandle(embed.Title); handle != "" {
+		return name, handle
+	}
+	return "", ""
+}
+
+// splitHandle separates "Elon Musk (@elonmusk)" into its name and handle
+func splitHandle(line string) (name, handle string) {
+	if match := handlePattern.FindStringSubmatch(line); match != nil {
+		handle = match[1]
+		line = strings.Replace(line, match[0], "", 1)
+	}
+
+	// The handle leaves "Elon Musk ()" behind
+	name = strings.TrimSpace(strings.Trim(strings.TrimSpace(line), "()-·|"))
+	return name, handle
+}
+
+// findStatusURL looks for the URL of the relayed tweet in the embed, then in
+// the message content, and returns it with the handle it carries
+func findStatusURL(embed *discordgo.MessageEmbed, content string) (statusURL, handle string) {
+	candidates := []string{embed.URL}
+	if embed.Author != nil {
+		candidates = append(candidates, embed.Author.URL)
+	}
+	candidates = append(candidates, content)
+
+	for _, candidate := range candidates {
+		if match := statusURLPattern.FindStringSubmatch(candidate); match != nil {
+			return match[0], match[1]
+		}
+	}
+	return "", ""
+}
+
+// HandleFromStatusURL returns the handle of the author of a tweet from its
+// URL, or "" when it is not the URL of a tweet
+func HandleFromStatusURL(url string) string {
+	if match := statusURLPattern.FindStringSubmatch(url); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// StatusIDFromURL returns the ID of a tweet from its URL, or "" when it is
+// not the URL of a tweet. The same tweet has the same ID on every domain.
+func StatusIDFromURL(url string) string {
+	if match := statusURLPattern.FindStringSubmatch(url); match != nil {
+		return match[2]
+	}
+	return ""
+}
+
+// unwrapMarkdownLinks replaces markdown links by their text, so that
+// "[$AIXBT](https://x.com/search?q=%24AIXBT)" reads "$AIXBT"
+func unwrapMarkdownLinks(text string) string {
+	return markdownLinkPattern.ReplaceAllString(text, "$1")
+}
@@ -0,0 +1,177 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// loadMessage reads a message recorded from a relay channel
+func loadMessage(t *testing.T, name string) *discordgo.Message {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", "embeds", name))
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", name, err)
+	}
+
+	var m discordgo.Message
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("failed to decode %s: %v", name, err)
+	}
+	return &m
+}
+
+func TestProcessDiscordMessage(t *testing.T) {
+	tests := []struct {
+		file      string
+		author    string
+		handle    string
+		statusURL string
+		content   string
+		links     []string
+		tickers   []string
+		valid     bool
+	}{
+		{
+			file:      "tweetshift.json",
+			author:    "Murad",
+			handle:    "MustStopMurad",
+			statusURL: "https://twitter.com/MustStopMurad/status/1895812345678901234",
+			content:   "Still early on $SPX and $GIGA. The memecoin supercycle is not over",
+			links:     []string{"https://twitter.com/MustStopMurad/status/1895812345678901234"},
+			tickers:   []string{"$SPX", "$GIGA"},
+			valid:     true,
+		},
+		{
+			file:      "title_gallery.json",
+			author:    "aixbt",
+			handle:    "aixbt_agent",
+			statusURL: "https://x.com/aixbt_agent/status/1895800000000000000",
+			content:   "$VIRTUAL ecosystem volume up 40% this week while $AIXBT holds the 200 day average",
+			links:     []string{"https://x.com/aixbt_agent/status/1895800000000000000"},
+			tickers:   []string{"$VIRTUAL", "$AIXBT"},
+			valid:     true,
+		},
+		{
+			file:      "fxtwitter.json",
+			author:    "K A L E O",
+			handle:    "CryptoKaleo",
+			statusURL: "https://fxtwitter.com/CryptoKaleo/status/1895790000000000000",
+			content:   "$SOL to $300 is the easiest trade of the cycle **💬 120 🔁 45 ❤️ 980 👁️ 120K **",
+			links:     []string{"https://fxtwitter.com/CryptoKaleo/status/1895790000000000000"},
+			tickers:   []string{"$SOL"},
+			valid:     true,
+		},
+		{
+			// A link preview is not a tweet, the message content is used
+			file:    "link_preview.json",
+			author:  "Analyst",
+			content: "Worth a read on $ETH staking flows this week",
+			tickers: []string{"$ETH"},
+			valid:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			m := loadMessage(t, tt.file)
+
+			ta := NewTweetAnalyzer()
+			got := ta.ProcessDiscordMessage(m)
+
+			if got.Author != tt.author {
+				t.Errorf("Author = %q, want %q", got.Author, tt.author)
+			}
+			if got.Handle != tt.handle {
+				t.Errorf("Handle = %q, want %q", got.Handle, tt.handle)
+			}
+			if got.StatusURL != tt.statusURL {
+				t.Errorf("StatusURL = %q, want %q", got.StatusURL, tt.statusURL)
+			}
+			if got.Content != tt.content {
+				t.Errorf("Content = %q, want %q", got.Content, tt.content)
+			}
+			if len(got.Links) != 0 || len(tt.links) != 0 {
+				if !reflect.DeepEqual(got.Links, tt.links) {
+					t.Errorf("Links = %v, want %v", got.Links, tt.links)
+				}
+			}
+			if !reflect.DeepEqual(got.Tickers, tt.tickers) {
+				t.Errorf("Tickers = %v, want %v", got.Tickers, tt.tickers)
+			}
+			if got.IsValid != tt.valid {
+				t.Errorf("IsValid = %v, want %v", got.IsValid, tt.valid)
+			}
+			if !got.Timestamp.Equal(m.Timestamp) {
+				t.Errorf("Timestamp = %v, want %v", got.Timestamp, m.Timestamp)
+			}
+			if stats := ta.GetStats(); stats["valid_tweets"] != 1 {
+				t.Errorf("Valid tweets count = %v, want 1", stats["valid_tweets"])
+			}
+		})
+	}
+}
+
+func TestTweetFromEmbedsSkipsEmbedsWithoutTweet(t *testing.T) {
+	embeds := []*discordgo.MessageEmbed{
+		{Type: discordgo.EmbedTypeImage, URL: "https://x.com/aixbt_agent/status/1"},
+		{Type: discordgo.EmbedTypeRich, Title: "New tweet", Description: "Some text long enough to be a tweet"},
+	}
+	if tweet := TweetFromEmbeds("", embeds, "relay", time.Time{}); tweet != nil {
+		t.Errorf("TweetFromEmbeds() = %+v, want nil", tweet)
+	}
+
+	// The status URL may only be in the message content
+	embeds[1].Title = ""
+	tweet := TweetFromEmbeds("https://x.com/aixbt_agent/status/1", embeds, "relay", time.Time{})
+	if tweet == nil {
+		t.Fatal("TweetFromEmbeds() = nil, want a tweet")
+	}
+	if tweet.Author != "aixbt_agent" || tweet.Handle != "aixbt_agent" {
+		t.Errorf("Author, Handle = %q, %q, want the handle of the status URL", tweet.Author, tweet.Handle)
+	}
+}
+
+func TestUnwrapMarkdownLinks(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"[$SPX](https://twitter.com/search?q=%24SPX) up", "$SPX up"},
+		{"gm [@aixbt_agent](<https://x.com/aixbt_agent>)", "gm @aixbt_agent"},
+		{"[not a link] (text)", "[not a link] (text)"},
+	}
+	for _, tt := range tests {
+		if got := unwrapMarkdownLinks(tt.text); got != tt.want {
+			t.Errorf("unwrapMarkdownLinks(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestStatusURL(t *testing.T) {
+	tests := []struct {
+		url    string
+		handle string
+		id     string
+	}{
+		{"https://x.com/aixbt_agent/status/1879491000000000000", "aixbt_agent", "1879491000000000000"},
+		{"https://mobile.twitter.com/MustStopMurad/statuses/1879475000000000000?s=20", "MustStopMurad", "1879475000000000000"},
+		{"https://fxtwitter.com/aixbt_agent/status/1879491000000000000", "aixbt_agent", "1879491000000000000"},
+		{"https://x.com/aixbt_agent", "", ""},
+		{"https://t.me/whales/1234", "", ""},
+	}
+	for _, tt := range tests {
+		if got := HandleFromStatusURL(tt.url); got != tt.handle {
+			t.Errorf("HandleFromStatusURL(%q) = %q, want %q", tt.url, got, tt.handle)
+		}
+		if got := StatusIDFromURL(tt.url); got != tt.id {
+			t.Errorf("StatusIDFromURL(%q) = %q, want %q", tt.url, got, tt.id)
+		}
+	}
+}
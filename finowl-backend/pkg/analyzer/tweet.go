@@ -2,6 +2,7 @@ package analyzer
 
 import (
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -36,23 +37,24 @@ type Tweet struct {
 	Content   string
 	RawAuthor string // Original author string
 	Author    string // Cleaned author name
+	Handle    string // Twitter handle, when the relay embeds it
+	StatusURL string // URL of the tweet, when the relay embeds it
 	Timestamp time.Time
 	IsValid   bool
 	Links     []string
 	Tickers   []string
 }
 
-// TweetAnalyzer handles tweet content analysis
+// TweetAnalyzer handles tweet content analysis. It is safe for concurrent use.
 type TweetAnalyzer struct {
-	validTweets   []Tweet
-	invalidTweets []Tweet
+	mu              sync.Mutex
+	validTweets     int
+	invalidTweets   int
+	duplicateTweets int // valid tweets already stored from another message
 }
 
 func NewTweetAnalyzer() *TweetAnalyzer {
-	return &TweetAnalyzer{
-		validTweets:   make([]Tweet, 0),
-		invalidTweets: make([]Tweet, 0),
-	}
+	return &TweetAnalyzer{}
 }
 
 func CleanTweetContent(content string) string {
@@ -127,27 +129,50 @@ func (ta *TweetAnalyzer) ProcessMessage(content, authorName string, timestamp ti
 		Tickers:   ExtractTickers(content),
 	}
 
-	if ValidateTweetContent(content) {
-		tweet.IsValid = true
-		ta.validTweets = append(ta.validTweets, tweet)
-	} else {
-		ta.invalidTweets = append(ta.invalidTweets, tweet)
-	}
+	tweet.IsValid = ValidateTweetContent(content)
+	ta.record(&tweet)
 
 	return &tweet
 }
 
-// GetValidTweets returns all valid tweets collected
-func (ta *TweetAnalyzer) GetValidTweets() []Tweet {
-	return ta.validTweets
+// record counts an analyzed tweet. Only counts are kept, a long running
+// collector analyzes more tweets than it could remember.
+func (ta *TweetAnalyzer) record(tweet *Tweet) {
+	ta.mu.Lock()
+	defer ta.mu.Unlock()
+
+	if tweet.IsValid {
+		ta.validTweets++
+	} else {
+		ta.invalidTweets++
+	}
+}
+
+// RecordDuplicate counts a valid tweet that was not stored because another
+// message relayed it before
+func (ta *TweetAnalyzer) RecordDuplicate() {
+	ta.mu.Lock()
+	defer ta.mu.Unlock()
+
+	ta.duplicateTweets++
 }
 
 // GetStats returns analysis statistics
 func (ta *TweetAnalyzer) GetStats() map[string]interface{} {
+	ta.mu.Lock()
+	defer ta.mu.Unlock()
+
+	total := ta.validTweets + ta.invalidTweets
+	ratio := 0.0
+	if total > 0 {
+		ratio = float64(ta.validTweets) / float64(total)
+	}
+
 	return map[string]interface{}{
-		"total_tweets":   len(ta.validTweets) + len(ta.invalidTweets),
-		"valid_tweets":   len(ta.validTweets),
-		"invalid_tweets": len(ta.invalidTweets),
-		"validity_ratio": float64(len(ta.validTweets)) / float64(len(ta.validTweets)+len(ta.invalidTweets)),
+		"total_tweets":     total,
+		"valid_tweets":     ta.validTweets,
+		"invalid_tweets":   ta.invalidTweets,
+		"duplicate_tweets": ta.duplicateTweets,
+		"validity_ratio":   ratio,
 	}
 }
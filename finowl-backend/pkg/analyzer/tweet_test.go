@@ -1,6 +1,7 @@
 package analyzer
 
 import (
+	"sync"
 	"testing"
 	"time"
 )
@@ -113,12 +114,51 @@ func TestTweetAnalyzer_ProcessMessage(t *testing.T) {
 			}
 
 			// Check valid/invalid tweet counts
-			if len(ta.validTweets) != tt.wantValid {
-				t.Errorf("Valid tweets count = %v, want %v", len(ta.validTweets), tt.wantValid)
+			if ta.validTweets != tt.wantValid {
+				t.Errorf("Valid tweets count = %v, want %v", ta.validTweets, tt.wantValid)
 			}
-			if len(ta.invalidTweets) != tt.wantInvalid {
-				t.Errorf("Invalid tweets count = %v, want %v", len(ta.invalidTweets), tt.wantInvalid)
+			if ta.invalidTweets != tt.wantInvalid {
+				t.Errorf("Invalid tweets count = %v, want %v", ta.invalidTweets, tt.wantInvalid)
 			}
 		})
 	}
 }
+
+func TestTweetAnalyzer_GetStats(t *testing.T) {
+	ta := NewTweetAnalyzer()
+	ta.ProcessMessage("$AIXBT mindshare keeps climbing", "aixbt • Relay", time.Now())
+	ta.ProcessMessage("$AIXBT mindshare keeps climbing", "aixbt • TweetShift", time.Now())
+	ta.ProcessMessage("gm", "degen", time.Now())
+	ta.RecordDuplicate()
+
+	stats := ta.GetStats()
+	if stats["total_tweets"] != 3 || stats["valid_tweets"] != 2 || stats["invalid_tweets"] != 1 {
+		t.Errorf("GetStats() = %v, want 3 tweets of which 2 valid", stats)
+	}
+	if stats["duplicate_tweets"] != 1 {
+		t.Errorf("duplicate_tweets = %v, want 1", stats["duplicate_tweets"])
+	}
+}
+
+func TestTweetAnalyzer_ConcurrentUse(t *testing.T) {
+	ta := NewTweetAnalyzer()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				ta.ProcessMessage("$AIXBT mindshare keeps climbing", "aixbt • Relay", time.Now())
+				ta.ProcessMessage("gm", "degen", time.Now())
+				ta.RecordDuplicate()
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats := ta.GetStats()
+	if stats["valid_tweets"] != 800 || stats["invalid_tweets"] != 800 || stats["duplicate_tweets"] != 800 {
+		t.Errorf("GetStats() = %v, want 800 valid, invalid and duplicate tweets", stats)
+	}
+}
@@ -0,0 +1,107 @@
+package collector
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// backfillPageSize is the most messages Discord returns per history request
+	backfillPageSize = 100
+
+	// maxBackfillPages bounds how many messages of a channel are recovered
+	// after a long downtime, the rest of the gap is skipped
+	maxBackfillPages = 50
+)
+
+// channelHistory pages through past messages, satisfied by *discordgo.Session
+type channelHistory interface {
+	ChannelMessages(channelID string, limit int, beforeID, afterID, aroundID string, options ...discordgo.RequestOption) ([]*discordgo.Message, error)
+}
+
+// backfill runs the messages posted in every collected channel since its
+// checkpoint through the normal pipeline. from overrides the stored checkpoints,
+// it returns the newest message seen in every channel to resume from.
+// Channels never collected before have no checkpoint and are not backfilled.
+func (b *Bot) backfill(from map[string]string) map[string]string {
+	me, err := b.session.User("@me")
+	if err != nil {
+		log.Printf("Error identifying the bot, skipping backfill: %v", err)
+		return from
+	}
+
+	reached := make(map[string]string, len(b.channels))
+	for channelID, category := range b.channels {
+		after, ok := from[channelID]
+		if !ok {
+			if after, err = b.storer.GetChannelCheckpoint(channelID); err != nil {
+				log.Printf("Error retrieving checkpoint of channel %s: %v", channelID, err)
+				continue
+			}
+		}
+		reached[channelID] = after
+		if after == "" {
+			continue
+		}
+
+		last, count, err := backfillChannel(b.session, channelID, after, func(m *discordgo.Message) {
+			if m.Author != nil && m.Author.ID == me.ID {
+				return
+			}
+			b.handleCategoryMessage(category, &discordgo.MessageCreate{Message: m})
+		})
+		if err != nil {
+			log.Printf("Error backfilling channel %s: %v", channelID, err)
+		}
+		if count > 0 {
+			log.Printf("Backfilled %d messages of channel %s (%s)", count, channelID, category)
+		}
+		reached[channelID] = last
+	}
+	return reached
+}
+
+// backfillChannel hands every message posted in a channel after the given
+// message to handle, oldest first, and returns the newest message handled
+// along with how many were
+func backfillChannel(history channelHistory, channelID, after string, handle func(*discordgo.Message)) (string, int, error) {
+	count := 0
+	for page := 0; page < maxBackfillPages; page++ {
+		messages, err := history.ChannelMessages(channelID, backfillPageSize, "", after, "")
+		if err != nil {
+			return after, count, fmt.Errorf("failed to retrieve messages after %s: %w", after, err)
+		}
+		if len(messages) == 0 {
+			return after, count, nil
+		}
+
+		// Discord returns the page newest first
+		sort.Slice(messages, func(i, j int) bool {
+			return snowflakeBefore(messages[i].ID, messages[j].ID)
+		})
+		for _, m := range messages {
+			handle(m)
+			count++
+		}
+		after = messages[len(messages)-1].ID
+
+		if len(messages) < backfillPageSize {
+			return after, count, nil
+		}
+	}
+
+	log.Printf("Backfill of channel %s stopped after %d messages, the messages posted after %s were skipped", channelID, count, after)
+	return after, count, nil
+}
+
+// snowflakeBefore reports whether Discord ID a was created before b. IDs are
+// decimal numbers without leading zeros, so they compare by length first.
+func snowflakeBefore(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
@@ -0,0 +1,89 @@
+package collector
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeHistory serves a channel holding messages 1 to count like the Discord API:
+// the page right after afterID, newest first
+type fakeHistory struct {
+	count    int
+	requests []string
+	err      error
+}
+
+func (f *fakeHistory) ChannelMessages(channelID string, limit int, beforeID, afterID, aroundID string, options ...discordgo.RequestOption) ([]*discordgo.Message, error) {
+	f.requests = append(f.requests, afterID)
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	after, _ := strconv.Atoi(afterID)
+	var page []*discordgo.Message
+	for id := after + 1; id <= f.count && len(page) < limit; id++ {
+		page = append([]*discordgo.Message{{ID: strconv.Itoa(id), ChannelID: channelID}}, page...)
+	}
+	return page, nil
+}
+
+func TestBackfillChannel(t *testing.T) {
+	history := &fakeHistory{count: 250}
+
+	var handled []string
+	last, count, err := backfillChannel(history, "channel", "95", func(m *discordgo.Message) {
+		handled = append(handled, m.ID)
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "250", last)
+	assert.Equal(t, 155, count)
+	assert.Equal(t, []string{"95", "195"}, history.requests)
+
+	// Oldest first, across pages
+	require.Len(t, handled, 155)
+	assert.Equal(t, "96", handled[0])
+	assert.Equal(t, "99", handled[3])
+	assert.Equal(t, "100", handled[4])
+	assert.Equal(t, "250", handled[154])
+}
+
+func TestBackfillChannelNothingMissed(t *testing.T) {
+	history := &fakeHistory{count: 200}
+
+	last, count, err := backfillChannel(history, "channel", "200", func(m *discordgo.Message) {
+		t.Errorf("unexpected message %s", m.ID)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "200", last)
+	assert.Zero(t, count)
+}
+
+func TestBackfillChannelStopsAtLimit(t *testing.T) {
+	history := &fakeHistory{count: (maxBackfillPages + 2) * backfillPageSize}
+
+	last, count, err := backfillChannel(history, "channel", "0", func(*discordgo.Message) {})
+	require.NoError(t, err)
+	assert.Equal(t, maxBackfillPages*backfillPageSize, count)
+	assert.Equal(t, strconv.Itoa(count), last)
+}
+
+func TestBackfillChannelError(t *testing.T) {
+	history := &fakeHistory{err: errors.New("rate limited")}
+
+	last, _, err := backfillChannel(history, "channel", "42", func(*discordgo.Message) {})
+	assert.Error(t, err)
+	assert.Equal(t, "42", last)
+}
+
+func TestSnowflakeBefore(t *testing.T) {
+	assert.True(t, snowflakeBefore("99", "100"))
+	assert.True(t, snowflakeBefore("1345678901234567890", "1345678901234567891"))
+	assert.False(t, snowflakeBefore("200", "199"))
+	assert.False(t, snowflakeBefore("7", "7"))
+}
@@ -0,0 +1,45 @@
+package collector
+
+import (
+	"finowl-backend/internal/utils"
+	"fmt"
+)
+
+// knownCategories lists the categories a relay channel can be routed to.
+var knownCategories = map[string]bool{
+	EarlyAlpha:        true,
+	MacroNews:         true,
+	PortfolioInsights: true,
+	AlphaTrenches:     true,
+}
+
+// ChannelCategories merges the channel -> category routes declared in
+// config.yaml with those from the environment. Environment entries win on
+// conflict, and the legacy single channel variable is routed to MacroNews.
+func ChannelCategories(appConfig utils.AppConfig, config utils.Prompt) (map[string]string, error) {
+	channels := make(map[string]string)
+
+	for channelID, category := range config.Channels {
+		channels[channelID] = category
+	}
+
+	if appConfig.ChannelID != "" {
+		channels[appConfig.ChannelID] = MacroNews
+	}
+
+	for channelID, category := range appConfig.ChannelCategories {
+		channels[channelID] = category
+	}
+
+	if len(channels) == 0 {
+		return nil, fmt.Errorf("no Discord channels configured")
+	}
+
+	for channelID, category := range channels {
+		if _, hasPrompt := config.Prompts[category]; !knownCategories[category] && !hasPrompt {
+			return nil, fmt.Errorf("channel %s is routed to unknown category %q", channelID, category)
+		}
+	}
+
+	return channels, nil
+}
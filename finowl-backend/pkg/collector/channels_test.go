@@ -0,0 +1,60 @@
+package collector
+
+import (
+	"finowl-backend/internal/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildChannelCategories(t *testing.T) {
+	tests := []struct {
+		name      string
+		appConfig utils.AppConfig
+		config    utils.Prompt
+		want      map[string]string
+		wantErr   bool
+	}{
+		{
+			name:   "channels from config.yaml",
+			config: utils.Prompt{Channels: map[string]string{"1": EarlyAlpha, "2": AlphaTrenches}},
+			want:   map[string]string{"1": EarlyAlpha, "2": AlphaTrenches},
+		},
+		{
+			name:      "legacy channel variable routes to MacroNews",
+			appConfig: utils.AppConfig{ChannelID: "42"},
+			want:      map[string]string{"42": MacroNews},
+		},
+		{
+			name: "environment overrides config.yaml",
+			appConfig: utils.AppConfig{
+				ChannelCategories: map[string]string{"1": PortfolioInsights, "3": MacroNews},
+			},
+			config: utils.Prompt{Channels: map[string]string{"1": EarlyAlpha}},
+			want:   map[string]string{"1": PortfolioInsights, "3": MacroNews},
+		},
+		{
+			name:    "no channels configured",
+			wantErr: true,
+		},
+		{
+			name:    "unknown category",
+			config:  utils.Prompt{Channels: map[string]string{"1": "Gossip"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ChannelCategories(tt.appConfig, tt.config)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"finowl-backend/ai"
 	"finowl-backend/internal/utils"
 	"finowl-backend/pkg/analyzer"
+	"finowl-backend/pkg/deadletter"
+	"finowl-backend/pkg/events"
 	"finowl-backend/pkg/influencer"
 	"finowl-backend/pkg/storer"
+	"fmt"
 	"log"
 	"os"
 
@@ -13,17 +16,24 @@ import (
 )
 
 type Bot struct {
-	session      *discordgo.Session
-	channelIDs   map[string]string
-	analyzer     *analyzer.TweetAnalyzer
-	aiClient     *ai.AI
-	tweetBatch   map[string][]string
-	currentBatch []string
-	batchSize    int
-	config       utils.Prompt
-	influencers  influencer.InfluencerRankings
-	storer       *storer.Storer
-	logger       *log.Logger
+	session     *discordgo.Session
+	channels    map[string]string // channel ID -> category
+	analyzer    *analyzer.TweetAnalyzer
+	aiClient    *ai.AI
+	config      utils.Prompt
+	influencers influencer.InfluencerRankings
+	storer      *storer.Storer
+	logger      *log.Logger
+
+	queue *workerPool // processes the messages of the collected channels
+
+	commands *commands // slash commands answered from the database
+	guildID  string    // guild the commands are registered in, empty for every guild
+
+	publisher *publisher     // posts summaries and alerts back to Discord
+	events    *events.Broker // ticker events the alerts are posted from
+
+	deadLetters *deadletter.Queue // retries the tweets that could not be stored, nil drops them
 }
 
 // channelMapping := map[string]string{
@@ -49,41 +59,106 @@ func NewBot(
 		log.Fatalf("Error opening log file: %v", err)
 	}
 
+	channels, err := ChannelCategories(appConfig, config)
+	if err != nil {
+		return nil, err
+	}
+
 	session, err := discordgo.New("Bot " + appConfig.DiscordToken)
 	if err != nil {
 		return nil, err
 	}
+	// Handlers run on the gateway connection, so that a full queue slows down
+	// reading events instead of piling up goroutines
+	session.SyncEvents = true
 
 	// Create a new logger
 	logger := log.New(logFile, "INFO: ", log.Ldate|log.Ltime|log.Lshortfile)
-	return &Bot{
-		session: session,
-		channelIDs: map[string]string{
-			"mainChannel": appConfig.ChannelID},
+	b := &Bot{
+		session:     session,
+		channels:    channels,
 		analyzer:    analyzer.NewTweetAnalyzer(),
 		aiClient:    ai.NewDeepSeekAI(appConfig.ClaudeAPIKey),
-		tweetBatch:  make(map[string][]string),
-		batchSize:   2, // Set the batch size to 5
 		influencers: influencers,
 		config:      config,
 		logger:      logger,
 		storer:      storer,
-	}, nil
+		commands:    newCommands(storer, channels),
+		guildID:     appConfig.DiscordGuildID,
+		publisher:   newPublisher(storer, session, appConfig.SummaryChannelID, appConfig.AlertsChannelID),
+	}
+	b.queue = newWorkerPool(appConfig.CollectorWorkers, appConfig.CollectorQueueSize, b.process)
+	return b, nil
+}
+
+// SetEventSource makes the bot announce the tickers entering the top mindshare
+// category, as published on broker. Must be called before Start.
+func (b *Bot) SetEventSource(broker *events.Broker) {
+	b.events = broker
+}
+
+// SetDeadLetters makes the bot record the tweets it fails to store in queue,
+// which retries them. Must be called before Start.
+func (b *Bot) SetDeadLetters(queue *deadletter.Queue) {
+	b.deadLetters = queue
 }
 
 func (b *Bot) Start() error {
 	b.session.AddHandler(b.messageHandler)
+	b.session.AddHandler(b.messageUpdateHandler)
+	b.session.AddHandler(b.messageDeleteHandler)
+	b.session.AddHandler(b.interactionHandler)
 
+	// Catch up on the messages posted while the collector was down, then on
+	// those posted while connecting. Live events may overlap the second pass,
+	// messages already stored are skipped. They wait in the queue until it is
+	// over, a live message must not checkpoint a channel past a message the
+	// backfill has yet to process.
+	checkpoints := b.backfill(nil)
 	if err := b.session.Open(); err != nil {
 		return err
 	}
+	b.backfill(checkpoints)
+	b.queue.start()
+
+	if err := b.registerCommands(); err != nil {
+		b.session.Close()
+		return err
+	}
+
+	if b.publisher.enabled() {
+		b.publisher.start(b.events)
+	}
 
 	b.session.UpdateGameStatus(0, "Watching for messages")
 	return nil
 }
 
+// Close disconnects the bot and returns once the messages received before
+// are processed
 func (b *Bot) Close() error {
-	return b.session.Close()
+	err := b.session.Close()
+	b.queue.close()
+
+	if b.publisher.enabled() {
+		b.publisher.close()
+	}
+
+	stats := b.analyzer.GetStats()
+	log.Printf("Collector processed %v tweets: %v valid, %v invalid, %v duplicates",
+		stats["total_tweets"], stats["valid_tweets"], stats["invalid_tweets"], stats["duplicate_tweets"])
+	return err
+}
+
+// registerCommands replaces the slash commands of the application with ours.
+// Commands registered in a guild are available at once, global ones can take
+// up to an hour to show up.
+func (b *Bot) registerCommands() error {
+	_, err := b.session.ApplicationCommandBulkOverwrite(b.session.State.User.ID, b.guildID, b.commands.definitions())
+	if err != nil {
+		return fmt.Errorf("failed to register slash commands: %w", err)
+	}
+	return nil
 }
 
 // GetSession returns the discord session (useful if needed externally)
@@ -0,0 +1,377 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"finowl-backend/pkg/mindshare"
+	"finowl-backend/pkg/storer"
+	"finowl-backend/pkg/ticker"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	topTickersLimit   = 10
+	freshTickersLimit = 10
+	topInfluencers    = 5
+
+	defaultFreshHours = 6
+	maxFreshHours     = 7 * 24
+
+	// Discord rejects embeds whose description is longer
+	maxEmbedDescription = 4096
+)
+
+// Embed colors of the mindshare categories: the highest is green, the lowest
+// grey and those in between take the middle colors in turn
+const (
+	highestCategoryColor = 0x2ecc71
+	lowestCategoryColor  = 0x95a5a6
+)
+
+var middleCategoryColors = []int{0xf1c40f, 0xe67e22, 0x3498db, 0x9b59b6}
+
+// categoryColor returns the embed color of a category of the configured
+// scoring model, 0 (no color) for unknown ones
+func categoryColor(category string) int {
+	categories := mindshare.Scoring.Categories
+	for i, boundary := range categories {
+		if boundary.Name != category {
+			continue
+		}
+		switch i {
+		case 0:
+			return highestCategoryColor
+		case len(categories) - 1:
+			return lowestCategoryColor
+		default:
+			return middleCategoryColors[(i-1)%len(middleCategoryColors)]
+		}
+	}
+	return 0
+}
+
+// commandStore is the read access the slash commands need, satisfied by *storer.Storer
+type commandStore interface {
+	GetTicker(symbol string) (*ticker.Ticker, error)
+	GetTopTickers(category string, limit int) ([]ticker.Ticker, error)
+	GetFreshTickers(since time.Time, limit int) ([]ticker.Ticker, error)
+	GetLatestSummary(category string) (*mindshare.Summary, error)
+	GetSummary(id int) (*mindshare.Summary, error)
+}
+
+// commands answers the slash commands of the bot from the database. It does
+// not touch the Discord session, so every command can be tested on its own.
+type commands struct {
+	store      commandStore
+	categories []string // channel categories offered by /top
+	now        func() time.Time
+}
+
+func newCommands(store commandStore, channels map[string]string) *commands {
+	seen := make(map[string]bool)
+	var categories []string
+	for _, category := range channels {
+		if !seen[category] {
+			seen[category] = true
+			categories = append(categories, category)
+		}
+	}
+	sort.Strings(categories)
+
+	return &commands{
+		store:      store,
+		categories: categories,
+		now:        func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// definitions returns the slash commands registered with Discord
+func (c *commands) definitions() []*discordgo.ApplicationCommand {
+	categoryChoices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(c.categories))
+	for _, category := range c.categories {
+		categoryChoices = append(categoryChoices, &discordgo.ApplicationCommandOptionChoice{Name: category, Value: category})
+	}
+
+	minHours := 1.0
+	return []*discordgo.ApplicationCommand{
+		{
+			Name:        "mindshare",
+			Description: "Mindshare score, category and top influencers of a ticker",
+			Options: []*discordgo.ApplicationCommandOption{{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "ticker",
+				Description: "Ticker symbol, e.g. $AIXBT",
+				Required:    true,
+			}},
+		},
+		{
+			Name:        "top",
+			Description: "Tickers with the highest mindshare",
+			Options: []*discordgo.ApplicationCommandOption{{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "category",
+				Description: "Only tickers mentioned in this channel category",
+				Choices:     categoryChoices,
+			}},
+		},
+		{
+			Name:        "fresh",
+			Description: "Tickers mentioned for the first time recently",
+			Options: []*discordgo.ApplicationCommandOption{{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "hours",
+				Description: fmt.Sprintf("How far back to look (default %d)", defaultFreshHours),
+				MinValue:    &minHours,
+				MaxValue:    maxFreshHours,
+			}},
+		},
+		{
+			Name:        "summary",
+			Description: "Latest AI summary, or the one with the given ID",
+			Options: []*discordgo.ApplicationCommandOption{{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "summary",
+				Description: `"latest" or a summary ID`,
+			}},
+		},
+	}
+}
+
+// inputError is a failure caused by the options of a command, shown as is to its user
+type inputError string
+
+func (e inputError) Error() string {
+	return string(e)
+}
+
+// respond builds the reply to a slash command. Failures are replied with a
+// message only visible to the user who ran the command.
+func (c *commands) respond(data discordgo.ApplicationCommandInteractionData) (*discordgo.InteractionResponseData, error) {
+	options := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(data.Options))
+	for _, option := range data.Options {
+		options[option.Name] = option
+	}
+
+	var embed *discordgo.MessageEmbed
+	var err error
+
+	switch data.Name {
+	case "mindshare":
+		embed, err = c.mindshare(options)
+	case "top":
+		embed, err = c.top(options)
+	case "fresh":
+		embed, err = c.fresh(options)
+	case "summary":
+		embed, err = c.summary(options)
+	default:
+		err = inputError(fmt.Sprintf("unknown command /%s", data.Name))
+	}
+
+	var input inputError
+	if errors.As(err, &input) {
+		return ephemeral(input.Error()), nil
+	}
+	if err != nil {
+		return ephemeral("Something went wrong, please try again later."), err
+	}
+
+	return &discordgo.InteractionResponseData{Embeds: []*discordgo.MessageEmbed{embed}}, nil
+}
+
+func (c *commands) mindshare(options map[string]*discordgo.ApplicationCommandInteractionDataOption) (*discordgo.MessageEmbed, error) {
+	symbol, err := parseTickerOption(options["ticker"])
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := c.store.GetTicker(symbol)
+	if errors.Is(err, storer.ErrTickerNotFound) {
+		return nil, inputError(fmt.Sprintf("no mentions of $%s yet", symbol))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	mentions := 0
+	for _, detail := range t.MentionDetails.Influencers {
+		mentions += detail.MentionCount
+	}
+
+	return &discordgo.MessageEmbed{
+		Title: "$" + t.TickerSymbol,
+		Color: categoryColor(t.Category),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Mindshare", Value: fmt.Sprintf("%.2f", t.MindshareScore), Inline: true},
+			{Name: "Category", Value: t.Category, Inline: true},
+			{Name: "Influencers", Value: fmt.Sprintf("%d (%d mentions)", len(t.MentionDetails.Influencers), mentions), Inline: true},
+			{Name: "First seen", Value: discordTimestamp(t.FirstMentionedAt), Inline: true},
+			{Name: "Last mentioned", Value: discordTimestamp(t.LastMentionedAt), Inline: true},
+			{Name: "Top influencers", Value: formatTopInfluencers(t.MentionDetails)},
+		},
+	}, nil
+}
+
+func (c *commands) top(options map[string]*discordgo.ApplicationCommandInteractionDataOption) (*discordgo.MessageEmbed, error) {
+	category := ""
+	if option, ok := options["category"]; ok {
+		category = option.StringValue()
+	}
+
+	tickers, err := c.store.GetTopTickers(category, topTickersLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	title := "Top mindshare"
+	if category != "" {
+		title += " in " + category
+	}
+	return &discordgo.MessageEmbed{Title: title, Description: formatTickerList(tickers)}, nil
+}
+
+func (c *commands) fresh(options map[string]*discordgo.ApplicationCommandInteractionDataOption) (*discordgo.MessageEmbed, error) {
+	hours := int64(defaultFreshHours)
+	if option, ok := options["hours"]; ok {
+		hours = option.IntValue()
+		if hours < 1 || hours > maxFreshHours {
+			return nil, inputError(fmt.Sprintf("hours must be between 1 and %d", maxFreshHours))
+		}
+	}
+
+	tickers, err := c.store.GetFreshTickers(c.now().Add(-time.Duration(hours)*time.Hour), freshTickersLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("Fresh tickers of the last %dh", hours),
+		Description: formatTickerList(tickers),
+	}, nil
+}
+
+func (c *commands) summary(options map[string]*discordgo.ApplicationCommandInteractionDataOption) (*discordgo.MessageEmbed, error) {
+	selector := "latest"
+	if option, ok := options["summary"]; ok {
+		selector = strings.TrimSpace(option.StringValue())
+	}
+
+	var summary *mindshare.Summary
+	var err error
+	if strings.EqualFold(selector, "latest") {
+		summary, err = c.store.GetLatestSummary("")
+	} else {
+		id, convErr := strconv.Atoi(strings.TrimPrefix(selector, "#"))
+		if convErr != nil || id < 1 {
+			return nil, inputError(fmt.Sprintf("expected \"latest\" or a summary ID, got %q", selector))
+		}
+		summary, err = c.store.GetSummary(id)
+	}
+	if errors.Is(err, storer.ErrSummaryNotFound) {
+		return nil, inputError("no summary found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	title := fmt.Sprintf("Summary #%d", summary.ID)
+	if summary.Category != "" {
+		title += " · " + summary.Category
+	}
+	return &discordgo.MessageEmbed{
+		Title:       title,
+		Description: truncate(mindshare.StripSectionMarkers(summary.Content), maxEmbedDescription),
+		Timestamp:   summary.Time.UTC().Format(time.RFC3339),
+		Footer:      &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("%d tweets", summary.TweetCount)},
+	}, nil
+}
+
+// parseTickerOption normalizes a ticker option: "$aixbt" becomes "AIXBT"
+func parseTickerOption(option *discordgo.ApplicationCommandInteractionDataOption) (string, error) {
+	if option == nil {
+		return "", inputError("a ticker is required")
+	}
+
+	symbol := strings.ToUpper(strings.TrimPrefix(strings.TrimSpace(option.StringValue()), "$"))
+	if symbol == "" || len(symbol) > 20 {
+		return "", inputError(fmt.Sprintf("invalid ticker %q", option.StringValue()))
+	}
+	for _, r := range symbol {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return "", inputError(fmt.Sprintf("invalid ticker %q", option.StringValue()))
+		}
+	}
+	return symbol, nil
+}
+
+// formatTickerList renders one numbered line per ticker
+func formatTickerList(tickers []ticker.Ticker) string {
+	if len(tickers) == 0 {
+		return "No tickers found."
+	}
+
+	var b strings.Builder
+	for i, t := range tickers {
+		fmt.Fprintf(&b, "%d. **$%s** · %.2f · %s · last mentioned %s\n",
+			i+1, t.TickerSymbol, t.MindshareScore, t.Category, discordTimestamp(t.LastMentionedAt))
+	}
+	return b.String()
+}
+
+// formatTopInfluencers lists the highest tier influencers of a ticker, most recent first within a tier
+func formatTopInfluencers(details ticker.MentionDetails) string {
+	type influencer struct {
+		name   string
+		detail ticker.MentionDetail
+	}
+
+	influencers := make([]influencer, 0, len(details.Influencers))
+	for name, detail := range details.Influencers {
+		influencers = append(influencers, influencer{name, detail})
+	}
+	if len(influencers) == 0 {
+		return "None"
+	}
+
+	sort.Slice(influencers, func(i, j int) bool {
+		if influencers[i].detail.Tier != influencers[j].detail.Tier {
+			return influencers[i].detail.Tier < influencers[j].detail.Tier
+		}
+		return influencers[i].detail.MentionedAt.After(influencers[j].detail.MentionedAt)
+	})
+	if len(influencers) > topInfluencers {
+		influencers = influencers[:topInfluencers]
+	}
+
+	lines := make([]string, 0, len(influencers))
+	for _, inf := range influencers {
+		lines = append(lines, fmt.Sprintf("**%s** (tier %d) %s", inf.name, inf.detail.Tier, discordTimestamp(inf.detail.MentionedAt)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// discordTimestamp renders a time that Discord displays relative to the reader, e.g. "3 hours ago"
+func discordTimestamp(t time.Time) string {
+	return fmt.Sprintf("<t:%d:R>", t.Unix())
+}
+
+// truncate shortens s to at most limit characters, marking the cut with an ellipsis
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit-1]) + "…"
+}
+
+// ephemeral builds a plain reply only visible to the user who ran the command
+func ephemeral(message string) *discordgo.InteractionResponseData {
+	return &discordgo.InteractionResponseData{Content: message, Flags: discordgo.MessageFlagsEphemeral}
+}
@@ -0,0 +1,233 @@
+package collector
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"finowl-backend/pkg/mindshare"
+	"finowl-backend/pkg/storer"
+	"finowl-backend/pkg/ticker"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCommandStore serves the slash commands from memory and records the queries it received
+type fakeCommandStore struct {
+	tickers   map[string]*ticker.Ticker
+	top       []ticker.Ticker
+	summaries map[int]*mindshare.Summary
+	err       error
+
+	topCategory string
+	freshSince  time.Time
+}
+
+func (f *fakeCommandStore) GetTicker(symbol string) (*ticker.Ticker, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	if t, ok := f.tickers[symbol]; ok {
+		return t, nil
+	}
+	return nil, storer.ErrTickerNotFound
+}
+
+func (f *fakeCommandStore) GetTopTickers(category string, limit int) ([]ticker.Ticker, error) {
+	f.topCategory = category
+	return f.top, f.err
+}
+
+func (f *fakeCommandStore) GetFreshTickers(since time.Time, limit int) ([]ticker.Ticker, error) {
+	f.freshSince = since
+	return f.top, f.err
+}
+
+func (f *fakeCommandStore) GetLatestSummary(category string) (*mindshare.Summary, error) {
+	latest := 0
+	for id := range f.summaries {
+		latest = max(latest, id)
+	}
+	return f.GetSummary(latest)
+}
+
+func (f *fakeCommandStore) GetSummary(id int) (*mindshare.Summary, error) {
+	if s, ok := f.summaries[id]; ok {
+		return s, nil
+	}
+	return nil, storer.ErrSummaryNotFound
+}
+
+func commandData(name string, options ...*discordgo.ApplicationCommandInteractionDataOption) discordgo.ApplicationCommandInteractionData {
+	return discordgo.ApplicationCommandInteractionData{Name: name, Options: options}
+}
+
+func stringOption(name, value string) *discordgo.ApplicationCommandInteractionDataOption {
+	return &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: discordgo.ApplicationCommandOptionString, Value: value}
+}
+
+func newTestCommands() (*commands, *fakeCommandStore) {
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := &fakeCommandStore{
+		tickers: map[string]*ticker.Ticker{
+			"AIXBT": {
+				TickerSymbol:     "AIXBT",
+				Category:         "High Alpha",
+				MindshareScore:   312.5,
+				FirstMentionedAt: at.Add(-48 * time.Hour),
+				LastMentionedAt:  at,
+				MentionDetails: ticker.MentionDetails{Influencers: map[string]ticker.MentionDetail{
+					"degen": {Tier: 3, MentionedAt: at, MentionCount: 1},
+					"whale": {Tier: 1, MentionedAt: at.Add(-time.Hour), MentionCount: 2},
+				}},
+			},
+		},
+		top: []ticker.Ticker{
+			{TickerSymbol: "AIXBT", Category: "High Alpha", MindshareScore: 312.5, LastMentionedAt: at},
+			{TickerSymbol: "ETH", Category: "Alpha", MindshareScore: 150, LastMentionedAt: at},
+		},
+		summaries: map[int]*mindshare.Summary{
+			3: {ID: 3, Time: at, Category: "MacroNews", TweetCount: 12,
+				Content: "<!-- BEGIN KEY INSIGHTS FROM INFLUENCERS -->\n## Key Insights\n- Bullish\n<!-- END KEY INSIGHTS FROM INFLUENCERS -->"},
+			7: {ID: 7, Time: at, Content: "latest"},
+		},
+	}
+
+	c := newCommands(store, map[string]string{"1": EarlyAlpha, "2": MacroNews, "3": EarlyAlpha})
+	c.now = func() time.Time { return at }
+	return c, store
+}
+
+func TestCommandDefinitions(t *testing.T) {
+	c, _ := newTestCommands()
+	definitions := c.definitions()
+
+	names := make([]string, 0, len(definitions))
+	for _, d := range definitions {
+		names = append(names, d.Name)
+	}
+	assert.Equal(t, []string{"mindshare", "top", "fresh", "summary"}, names)
+
+	// /top offers every configured channel category once
+	choices := definitions[1].Options[0].Choices
+	require.Len(t, choices, 2)
+	assert.Equal(t, EarlyAlpha, choices[0].Value)
+	assert.Equal(t, MacroNews, choices[1].Value)
+}
+
+func TestMindshareCommand(t *testing.T) {
+	c, _ := newTestCommands()
+
+	data, err := c.respond(commandData("mindshare", stringOption("ticker", "$aixbt")))
+	require.NoError(t, err)
+	require.Len(t, data.Embeds, 1)
+
+	embed := data.Embeds[0]
+	assert.Equal(t, "$AIXBT", embed.Title)
+	assert.Equal(t, highestCategoryColor, embed.Color)
+	assert.Equal(t, "312.50", embed.Fields[0].Value)
+	assert.Equal(t, "2 (3 mentions)", embed.Fields[2].Value)
+
+	// Higher tiers are listed first
+	assert.Regexp(t, `^\*\*whale\*\* \(tier 1\).*\n\*\*degen\*\* \(tier 3\)`, embed.Fields[5].Value)
+}
+
+func TestCategoryColor(t *testing.T) {
+	defer func(scoring mindshare.ScoringConfig) { mindshare.Scoring = scoring }(mindshare.Scoring)
+
+	mindshare.Scoring.Categories = []mindshare.CategoryBoundary{
+		{Name: "Moon", Above: 800},
+		{Name: "High Alpha", Above: 600},
+		{Name: "Alpha", Above: 250},
+		{Name: "Dust", Above: 0},
+	}
+
+	assert.Equal(t, highestCategoryColor, categoryColor("Moon"))
+	assert.Equal(t, middleCategoryColors[0], categoryColor("High Alpha"))
+	assert.Equal(t, middleCategoryColors[1], categoryColor("Alpha"))
+	assert.Equal(t, lowestCategoryColor, categoryColor("Dust"))
+	assert.Zero(t, categoryColor("Trenches"))
+}
+
+func TestCommandsClockIsUTC(t *testing.T) {
+	c := newCommands(&fakeCommandStore{}, nil)
+	assert.Equal(t, time.UTC, c.now().Location())
+}
+
+func TestMindshareCommandErrors(t *testing.T) {
+	c, store := newTestCommands()
+
+	data, err := c.respond(commandData("mindshare", stringOption("ticker", "$NOPE")))
+	require.NoError(t, err)
+	assert.Equal(t, "no mentions of $NOPE yet", data.Content)
+	assert.Equal(t, discordgo.MessageFlagsEphemeral, data.Flags)
+
+	data, err = c.respond(commandData("mindshare", stringOption("ticker", "$ETH; DROP")))
+	require.NoError(t, err)
+	assert.Contains(t, data.Content, "invalid ticker")
+
+	// Database failures are reported to the caller, not to the user
+	store.err = errors.New("connection refused")
+	data, err = c.respond(commandData("mindshare", stringOption("ticker", "AIXBT")))
+	assert.Error(t, err)
+	assert.NotContains(t, data.Content, "connection refused")
+	assert.Equal(t, discordgo.MessageFlagsEphemeral, data.Flags)
+}
+
+func TestTopAndFreshCommands(t *testing.T) {
+	c, store := newTestCommands()
+
+	data, err := c.respond(commandData("top", stringOption("category", EarlyAlpha)))
+	require.NoError(t, err)
+	assert.Equal(t, EarlyAlpha, store.topCategory)
+	assert.Equal(t, "Top mindshare in EarlyAlpha", data.Embeds[0].Title)
+	assert.Contains(t, data.Embeds[0].Description, "1. **$AIXBT** · 312.50 · High Alpha")
+	assert.Contains(t, data.Embeds[0].Description, "2. **$ETH** · 150.00 · Alpha")
+
+	data, err = c.respond(commandData("fresh"))
+	require.NoError(t, err)
+	assert.Equal(t, c.now().Add(-defaultFreshHours*time.Hour), store.freshSince)
+	assert.Equal(t, "Fresh tickers of the last 6h", data.Embeds[0].Title)
+
+	hours := &discordgo.ApplicationCommandInteractionDataOption{Name: "hours", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(24)}
+	_, err = c.respond(commandData("fresh", hours))
+	require.NoError(t, err)
+	assert.Equal(t, c.now().Add(-24*time.Hour), store.freshSince)
+
+	store.top = nil
+	data, err = c.respond(commandData("top"))
+	require.NoError(t, err)
+	assert.Empty(t, store.topCategory)
+	assert.Equal(t, "No tickers found.", data.Embeds[0].Description)
+}
+
+func TestSummaryCommand(t *testing.T) {
+	c, _ := newTestCommands()
+
+	data, err := c.respond(commandData("summary"))
+	require.NoError(t, err)
+	assert.Equal(t, "Summary #7", data.Embeds[0].Title)
+
+	data, err = c.respond(commandData("summary", stringOption("summary", "#3")))
+	require.NoError(t, err)
+	embed := data.Embeds[0]
+	assert.Equal(t, "Summary #3 · MacroNews", embed.Title)
+	assert.Equal(t, "## Key Insights\n- Bullish", embed.Description)
+	assert.Equal(t, "12 tweets", embed.Footer.Text)
+
+	data, err = c.respond(commandData("summary", stringOption("summary", "99")))
+	require.NoError(t, err)
+	assert.Equal(t, "no summary found", data.Content)
+
+	data, err = c.respond(commandData("summary", stringOption("summary", "yesterday")))
+	require.NoError(t, err)
+	assert.Contains(t, data.Content, `expected "latest" or a summary ID`)
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", truncate("short", 10))
+	assert.Equal(t, "abcd…", truncate("abcdefgh", 5))
+	assert.Equal(t, "ééé…", truncate("éééééé", 4))
+}
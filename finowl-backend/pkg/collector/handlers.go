@@ -1,9 +1,9 @@
 package collector
 
 import (
+	"errors"
 	"finowl-backend/pkg/analyzer"
 	"finowl-backend/pkg/storer"
-	"fmt"
 	"log"
 
 	"github.com/bwmarrin/discordgo"
@@ -23,11 +23,71 @@ func (b *Bot) messageHandler(s *discordgo.Session, m *discordgo.MessageCreate) {
 	}
 
 	// Check which category the message belongs to
-	for category, channelID := range b.channelIDs {
-		if m.ChannelID == channelID {
-			go b.handleCategoryMessage(category, m) // Launch a goroutine for each category
-			return
-		}
+	if category, ok := b.channels[m.ChannelID]; ok {
+		b.enqueue(messageJob{event: messageCreated, category: category, message: m.Message})
+	}
+}
+
+// messageUpdateHandler re-extracts the tweet of an edited message
+func (b *Bot) messageUpdateHandler(s *discordgo.Session, m *discordgo.MessageUpdate) {
+	// Embeds unfurled after posting arrive as partial updates without an author
+	if m.Author == nil || m.Author.ID == s.State.User.ID {
+		return
+	}
+
+	if category, ok := b.channels[m.ChannelID]; ok {
+		b.enqueue(messageJob{event: messageEdited, category: category, message: m.Message})
+	}
+}
+
+// messageDeleteHandler forgets the tweet of a deleted message
+func (b *Bot) messageDeleteHandler(s *discordgo.Session, m *discordgo.MessageDelete) {
+	if category, ok := b.channels[m.ChannelID]; ok {
+		b.enqueue(messageJob{event: messageDeleted, category: category, message: m.Message})
+	}
+}
+
+// enqueue hands a message event to the workers
+func (b *Bot) enqueue(job messageJob) {
+	if !b.queue.submit(job) {
+		log.Printf("Collector is shutting down, dropped message %s", job.message.ID)
+	}
+}
+
+// process handles a queued message event
+func (b *Bot) process(job messageJob) {
+	switch job.event {
+	case messageCreated:
+		b.handleCategoryMessage(job.category, &discordgo.MessageCreate{Message: job.message})
+	case messageEdited:
+		b.handleEditedMessage(job.category, job.message)
+	case messageDeleted:
+		b.handleDeletedMessage(job.message.ID)
+	}
+}
+
+// interactionHandler answers the slash commands. Handlers run on the gateway
+// connection, the answer is built aside.
+func (b *Bot) interactionHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+	go b.respond(s, i)
+}
+
+// respond answers a slash command
+func (b *Bot) respond(s *discordgo.Session, i *discordgo.InteractionCreate) {
+
+	data, err := b.commands.respond(i.ApplicationCommandData())
+	if err != nil {
+		log.Printf("Error answering /%s: %v", i.ApplicationCommandData().Name, err)
+	}
+
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: data,
+	}); err != nil {
+		log.Printf("Error responding to /%s: %v", i.ApplicationCommandData().Name, err)
 	}
 }
 
@@ -38,29 +98,98 @@ func (b *Bot) handleCategoryMessage(category string, m *discordgo.MessageCreate)
 		return
 	}
 
-	tweet := b.analyzer.ProcessMessage(m.Content, m.Author.Username, m.Timestamp)
+	tweet := b.analyzer.ProcessDiscordMessage(m.Message)
 
 	if tweet.IsValid {
 		b.processValidTweet(category, m, tweet)
 	} else {
 		b.logInvalidTweet(category, tweet)
 	}
+
+	if err := b.storer.SaveChannelCheckpoint(m.ChannelID, m.ID); err != nil {
+		log.Printf("Error saving checkpoint of channel %s: %v", m.ChannelID, err)
+	}
 }
 
 // processValidTweet handles the logic for valid tweets
 func (b *Bot) processValidTweet(category string, m *discordgo.MessageCreate, tweet *analyzer.Tweet) {
 
 	tt := storer.TransformToStorerTweet(*tweet)
+	tt.Category = category
+	tt.MessageID = m.ID
 
-	b.storer.InsertTweet(tt)
+	// The tweet and its mentions are stored in a single transaction, a
+	// failure leaves nothing behind
 	tickers := storer.ConvertTweetsToTickers([]storer.Tweet{tt}, b.influencers)
-	b.storer.InsertTickersBatch(tickers)
+	if err := b.storer.StoreTweet(tt, tickers); err != nil {
+		switch {
+		case errors.Is(err, storer.ErrDuplicateTweet):
+			// Another relay posted it first and recorded its mentions
+			b.analyzer.RecordDuplicate()
+			b.logger.Printf("DUPLICATE TWEET in %s from %s: %v", category, tweet.Author, err)
+		case errors.Is(err, storer.ErrTweetExists):
+			// A message seen before already recorded its mentions
+		default:
+			log.Printf("Error storing tweet of message %s: %v", m.ID, err)
+			if b.deadLetters != nil {
+				b.deadLetters.Add(storer.SourceDiscord, m.ID, storer.DeadTweet{Tweet: tt, Tickers: tickers}, err)
+			}
+		}
+		return
+	}
 
 	b.logInfluencerInfo(tweet.Author)
+}
+
+// handleEditedMessage replaces the stored tweet of an edited message along
+// with its ticker mentions
+func (b *Bot) handleEditedMessage(category string, m *discordgo.Message) {
+	stored, err := b.storer.GetTweetByMessageID(m.ID)
+	if errors.Is(err, storer.ErrTweetNotFound) {
+		// Invalid when it was posted, the edit may have made it valid
+		b.handleCategoryMessage(category, &discordgo.MessageCreate{Message: m})
+		return
+	}
+	if err != nil {
+		log.Printf("Error retrieving tweet of edited message %s: %v", m.ID, err)
+		return
+	}
 
-	// Collect the formatted tweet content
-	b.collectFormattedTweet(category, m)
+	tweet := b.analyzer.ProcessDiscordMessage(m)
+	if !tweet.IsValid {
+		b.logInvalidTweet(category, tweet)
+		b.handleDeletedMessage(m.ID)
+		return
+	}
 
+	// The tweet keeps its identity, only its content changed
+	tt := storer.TransformToStorerTweet(*tweet)
+	tt.ID = stored.ID
+	tt.Author = stored.Author
+	tt.Timestamp = stored.Timestamp
+	tt.Category = stored.Category
+	tt.MessageID = stored.MessageID
+
+	tickers := storer.ConvertTweetsToTickers([]storer.Tweet{tt}, b.influencers)
+	if err := b.storer.UpdateTweet(tt, tickers); err != nil {
+		log.Printf("Error updating tweet of edited message %s: %v", m.ID, err)
+		return
+	}
+	b.logger.Printf("Updated tweet %s of edited message %s: tickers %v", tt.ID, m.ID, tt.Tickers)
+}
+
+// handleDeletedMessage forgets a deleted message, and removes its tweet along
+// with its ticker mentions when no other message relays it
+func (b *Bot) handleDeletedMessage(messageID string) {
+	err := b.storer.DeleteTweet(messageID)
+	if errors.Is(err, storer.ErrTweetNotFound) {
+		return
+	}
+	if err != nil {
+		log.Printf("Error deleting tweet of message %s: %v", messageID, err)
+		return
+	}
+	b.logger.Printf("Deleted message %s", messageID)
 }
 
 // logInfluencerInfo logs information about the influencer
@@ -73,14 +202,6 @@ func (b *Bot) logInfluencerInfo(author string) {
 	}
 }
 
-// collectFormattedTweet formats and collects the tweet content
-func (b *Bot) collectFormattedTweet(category string, m *discordgo.MessageCreate) {
-	formattedTweet := fmt.Sprintf("%s: %s", m.Author.Username, m.Content)
-	b.tweetBatch[category] = append(b.tweetBatch[category], formattedTweet)
-	b.currentBatch = append(b.currentBatch, formattedTweet)
-
-}
-
 // logInvalidTweet logs information about invalid tweets
 func (b *Bot) logInvalidTweet(category string, tweet *analyzer.Tweet) {
 	b.logger.Printf("INVALID TWEET in %s from %s: too short (%d characters)\n",
@@ -0,0 +1,249 @@
+package collector
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"finowl-backend/pkg/events"
+	"finowl-backend/pkg/mindshare"
+	"finowl-backend/pkg/storer"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// maxMessageLength is the longest message content Discord accepts
+	maxMessageLength = 2000
+
+	// summaryPollInterval is how often new summaries are looked for
+	summaryPollInterval = time.Minute
+
+	// summaryBacklog bounds how old a summary missed while the bot was down
+	// can be and still get posted
+	summaryBacklog = 6 * time.Hour
+
+	// categoryRepostInterval is how long a ticker re-entering the top
+	// category stays quiet after it was announced
+	categoryRepostInterval = 24 * time.Hour
+)
+
+// publisherStore records what was posted, satisfied by *storer.Storer
+type publisherStore interface {
+	GetUnpostedSummaries(since time.Time) ([]mindshare.Summary, error)
+	ClaimDiscordPost(kind, ref string, postedAt, repostBefore time.Time) (bool, error)
+	ReleaseDiscordPost(kind, ref string) error
+}
+
+// messageSender posts messages to a channel, satisfied by *discordgo.Session
+type messageSender interface {
+	ChannelMessageSend(channelID string, content string, options ...discordgo.RequestOption) (*discordgo.Message, error)
+}
+
+// publisher posts new summaries and top category transitions to Discord
+// channels. Every post is claimed in the database first, so a message is
+// posted once even across restarts.
+type publisher struct {
+	store  publisherStore
+	sender messageSender
+	now    func() time.Time
+
+	summaryChannelID string // empty disables summary posts
+	alertsChannelID  string // empty disables category alerts
+
+	stop chan struct{}
+	done sync.WaitGroup
+}
+
+func newPublisher(store publisherStore, sender messageSender, summaryChannelID, alertsChannelID string) *publisher {
+	return &publisher{
+		store:            store,
+		sender:           sender,
+		now:              func() time.Time { return time.Now().UTC() },
+		summaryChannelID: summaryChannelID,
+		alertsChannelID:  alertsChannelID,
+		stop:             make(chan struct{}),
+	}
+}
+
+// enabled reports whether the publisher has anywhere to post
+func (p *publisher) enabled() bool {
+	return p.summaryChannelID != "" || p.alertsChannelID != ""
+}
+
+// start posts in the background until close is called. Category alerts are
+// only posted when broker is not nil.
+func (p *publisher) start(broker *events.Broker) {
+	p.done.Add(1)
+	go p.run(broker)
+}
+
+// close stops the publisher and waits for the post in progress
+func (p *publisher) close() {
+	close(p.stop)
+	p.done.Wait()
+}
+
+func (p *publisher) run(broker *events.Broker) {
+	defer p.done.Done()
+
+	subscribe := func() *events.Subscription {
+		if broker == nil || p.alertsChannelID == "" {
+			return nil
+		}
+		return broker.Subscribe(events.Filter{Category: topCategory()})
+	}
+
+	sub := subscribe()
+	var tickerEvents <-chan events.Event
+	if sub != nil {
+		tickerEvents = sub.Events()
+	}
+	defer func() {
+		if sub != nil {
+			broker.Unsubscribe(sub)
+		}
+	}()
+
+	poll := time.NewTicker(summaryPollInterval)
+	defer poll.Stop()
+
+	p.publishSummaries()
+	for {
+		select {
+		case <-p.stop:
+			return
+
+		case <-poll.C:
+			p.publishSummaries()
+
+		case e, open := <-tickerEvents:
+			if open {
+				p.publishCategoryAlert(e)
+				continue
+			}
+			// Fell too far behind: subscribe again, events missed in between are lost
+			tickerEvents = nil
+			if broker.Evicted(sub) {
+				log.Printf("Publisher fell behind on ticker events, subscribing again")
+				sub = subscribe()
+				tickerEvents = sub.Events()
+			}
+		}
+	}
+}
+
+// publishSummaries posts every recent summary that was not posted yet
+func (p *publisher) publishSummaries() {
+	if p.summaryChannelID == "" {
+		return
+	}
+
+	summaries, err := p.store.GetUnpostedSummaries(p.now().Add(-summaryBacklog))
+	if err != nil {
+		log.Printf("Error retrieving summaries to post: %v", err)
+		return
+	}
+
+	for _, summary := range summaries {
+		ref := strconv.Itoa(summary.ID)
+		if err := p.post(storer.DiscordPostSummary, ref, time.Time{}, p.summaryChannelID, formatSummaryMessage(summary)); err != nil {
+			log.Printf("Error posting summary %d: %v", summary.ID, err)
+		}
+	}
+}
+
+// publishCategoryAlert announces a ticker entering the top category
+func (p *publisher) publishCategoryAlert(e events.Event) {
+	top := topCategory()
+	if e.Category != top || e.PreviousCategory == top {
+		return
+	}
+	if e.Type != events.CategoryChanged && e.Type != events.TickerCreated {
+		return
+	}
+
+	message := fmt.Sprintf("🚨 **$%s** entered **%s** with a mindshare of %.2f", e.Ticker, top, e.MindshareScore)
+	if e.PreviousCategory != "" {
+		message += fmt.Sprintf(" (was %s)", e.PreviousCategory)
+	}
+
+	repostBefore := p.now().Add(-categoryRepostInterval)
+	if err := p.post(storer.DiscordPostCategory, e.Ticker, repostBefore, p.alertsChannelID, []string{message}); err != nil {
+		log.Printf("Error posting %s alert of %s: %v", top, e.Ticker, err)
+	}
+}
+
+// post claims a message and sends its chunks. The claim is released when
+// nothing could be sent, so that the message is tried again later.
+func (p *publisher) post(kind, ref string, repostBefore time.Time, channelID string, chunks []string) error {
+	claimed, err := p.store.ClaimDiscordPost(kind, ref, p.now(), repostBefore)
+	if err != nil || !claimed {
+		return err
+	}
+
+	for i, chunk := range chunks {
+		if _, err := p.sender.ChannelMessageSend(channelID, chunk); err != nil {
+			if i == 0 {
+				if releaseErr := p.store.ReleaseDiscordPost(kind, ref); releaseErr != nil {
+					log.Printf("Error releasing discord post %s/%s: %v", kind, ref, releaseErr)
+				}
+			}
+			return fmt.Errorf("failed to send part %d/%d: %w", i+1, len(chunks), err)
+		}
+	}
+	return nil
+}
+
+// topCategory returns the highest mindshare category, "High Alpha" by default
+func topCategory() string {
+	return mindshare.Scoring.Categories[0].Name
+}
+
+// formatSummaryMessage renders a summary as Discord messages
+func formatSummaryMessage(summary mindshare.Summary) []string {
+	header := fmt.Sprintf("📰 **Summary #%d**", summary.ID)
+	if summary.Category != "" {
+		header += " · " + summary.Category
+	}
+	header += fmt.Sprintf(" (%d tweets)", summary.TweetCount)
+
+	return splitMessage(header+"\n\n"+mindshare.StripSectionMarkers(summary.Content), maxMessageLength)
+}
+
+// splitMessage cuts content into chunks of at most limit characters,
+// preferring to cut between paragraphs, then between lines, then between
+// words. Separators in the first half of a chunk are ignored so that a short
+// header is not sent on its own.
+func splitMessage(content string, limit int) []string {
+	var chunks []string
+
+	for {
+		content = strings.TrimSpace(content)
+		runes := []rune(content)
+		if len(runes) <= limit {
+			if content != "" {
+				chunks = append(chunks, content)
+			}
+			return chunks
+		}
+
+		window := string(runes[:limit])
+		cut := -1
+		for _, separator := range []string{"\n\n", "\n", " "} {
+			if i := strings.LastIndex(window, separator); i > 0 && i >= len(window)/2 {
+				cut = i
+				break
+			}
+		}
+		if cut == -1 {
+			cut = len(window)
+		}
+
+		chunks = append(chunks, strings.TrimSpace(content[:cut]))
+		content = content[cut:]
+	}
+}
@@ -0,0 +1,149 @@
+package collector
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"finowl-backend/pkg/events"
+	"finowl-backend/pkg/mindshare"
+	"finowl-backend/pkg/storer"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePublisherStore keeps the claimed posts in memory like the discord_posts table
+type fakePublisherStore struct {
+	summaries []mindshare.Summary
+	posted    map[string]time.Time // kind/ref -> posted at
+}
+
+func (f *fakePublisherStore) GetUnpostedSummaries(since time.Time) ([]mindshare.Summary, error) {
+	var unposted []mindshare.Summary
+	for _, s := range f.summaries {
+		if _, ok := f.posted[storer.DiscordPostSummary+"/"+strconv.Itoa(s.ID)]; !ok && !s.Time.Before(since) {
+			unposted = append(unposted, s)
+		}
+	}
+	return unposted, nil
+}
+
+func (f *fakePublisherStore) ClaimDiscordPost(kind, ref string, postedAt, repostBefore time.Time) (bool, error) {
+	if previous, ok := f.posted[kind+"/"+ref]; ok && !previous.Before(repostBefore) {
+		return false, nil
+	}
+	f.posted[kind+"/"+ref] = postedAt
+	return true, nil
+}
+
+func (f *fakePublisherStore) ReleaseDiscordPost(kind, ref string) error {
+	delete(f.posted, kind+"/"+ref)
+	return nil
+}
+
+// fakeSender records the messages sent to every channel
+type fakeSender struct {
+	messages map[string][]string
+	err      error
+}
+
+func (f *fakeSender) ChannelMessageSend(channelID string, content string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.messages[channelID] = append(f.messages[channelID], content)
+	return &discordgo.Message{ChannelID: channelID, Content: content}, nil
+}
+
+func newTestPublisher(now time.Time) (*publisher, *fakePublisherStore, *fakeSender) {
+	store := &fakePublisherStore{posted: map[string]time.Time{}}
+	sender := &fakeSender{messages: map[string][]string{}}
+
+	p := newPublisher(store, sender, "summaries", "alerts")
+	p.now = func() time.Time { return now }
+	return p, store, sender
+}
+
+func TestSplitMessage(t *testing.T) {
+	assert.Equal(t, []string{"short"}, splitMessage("short", 20))
+	assert.Empty(t, splitMessage("  ", 20))
+
+	// Paragraphs are kept together when they fit
+	assert.Equal(t, []string{"first paragraph", "second one"}, splitMessage("first paragraph\n\nsecond one", 20))
+
+	// Then lines, then words
+	assert.Equal(t, []string{"first line", "second line here"}, splitMessage("first line\nsecond line here", 20))
+	assert.Equal(t, []string{"short\nline two is", "longer"}, splitMessage("short\nline two is longer", 20))
+	assert.Equal(t, []string{"several words that", "do not fit"}, splitMessage("several words that do not fit", 20))
+
+	// A single word longer than the limit is cut
+	assert.Equal(t, []string{"abcde", "fgh"}, splitMessage("abcdefgh", 5))
+
+	long := strings.Repeat("- a bullet of the featured tickers section\n", 100)
+	chunks := splitMessage(long, maxMessageLength)
+	require.Len(t, chunks, 3)
+	for _, chunk := range chunks {
+		assert.LessOrEqual(t, len([]rune(chunk)), maxMessageLength)
+		assert.True(t, strings.HasPrefix(chunk, "- a bullet"))
+	}
+}
+
+func TestPublishSummariesOnce(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	p, store, sender := newTestPublisher(now)
+
+	store.summaries = []mindshare.Summary{
+		{ID: 1, Time: now.Add(-time.Hour), Category: "MacroNews", TweetCount: 3, Content: "<!-- BEGIN KEY INSIGHTS FROM INFLUENCERS -->\n## Key Insights\n- Bullish\n<!-- END KEY INSIGHTS FROM INFLUENCERS -->"},
+		{ID: 2, Time: now.Add(-30 * time.Minute), TweetCount: 1, Content: strings.Repeat("word ", 500)},
+	}
+
+	p.publishSummaries()
+	messages := sender.messages["summaries"]
+	require.Len(t, messages, 3)
+	assert.Equal(t, "📰 **Summary #1** · MacroNews (3 tweets)\n\n## Key Insights\n- Bullish", messages[0])
+	assert.True(t, strings.HasPrefix(messages[1], "📰 **Summary #2** (1 tweets)"))
+
+	// A restart finds nothing left to post
+	p.publishSummaries()
+	assert.Len(t, sender.messages["summaries"], 3)
+}
+
+func TestPublishSummariesRetriesFailedPosts(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	p, store, sender := newTestPublisher(now)
+	store.summaries = []mindshare.Summary{{ID: 1, Time: now, Content: "content"}}
+
+	sender.err = errors.New("discord is down")
+	p.publishSummaries()
+	assert.Empty(t, store.posted)
+
+	sender.err = nil
+	p.publishSummaries()
+	assert.Len(t, sender.messages["summaries"], 1)
+}
+
+func TestPublishCategoryAlert(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	p, _, sender := newTestPublisher(now)
+
+	entered := events.Event{Type: events.CategoryChanged, Ticker: "AIXBT", Category: "High Alpha", PreviousCategory: "Alpha", MindshareScore: 612.4}
+
+	p.publishCategoryAlert(entered)
+	p.publishCategoryAlert(events.Event{Type: events.MentionStored, Ticker: "AIXBT", Category: "High Alpha"})
+	p.publishCategoryAlert(events.Event{Type: events.CategoryChanged, Ticker: "ETH", Category: "Alpha", PreviousCategory: "Trenches"})
+
+	require.Len(t, sender.messages["alerts"], 1)
+	assert.Equal(t, "🚨 **$AIXBT** entered **High Alpha** with a mindshare of 612.40 (was Alpha)", sender.messages["alerts"][0])
+
+	// Re-entering the same day is not announced again, the next day it is
+	p.publishCategoryAlert(entered)
+	assert.Len(t, sender.messages["alerts"], 1)
+
+	p.now = func() time.Time { return now.Add(categoryRepostInterval + time.Minute) }
+	p.publishCategoryAlert(entered)
+	assert.Len(t, sender.messages["alerts"], 2)
+}
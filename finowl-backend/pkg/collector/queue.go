@@ -0,0 +1,106 @@
+package collector
+
+import (
+	"hash/fnv"
+	"log"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// messageEvent is what happened to a message of a collected channel
+type messageEvent int
+
+const (
+	messageCreated messageEvent = iota
+	messageEdited
+	messageDeleted
+)
+
+// messageJob is a message event waiting to be processed
+type messageJob struct {
+	event    messageEvent
+	category string
+	message  *discordgo.Message
+}
+
+// workerPool processes message jobs with a fixed number of workers. Every
+// worker has its own bounded queue and the jobs of a channel always go to the
+// same worker, so its messages are processed in the order they were posted:
+// an edit never comes before the message it edits, and a channel checkpoint
+// never passes a message still waiting in the queue. Submitting to a full
+// queue blocks, pushing back on the Discord gateway.
+type workerPool struct {
+	mu     sync.RWMutex // held for reading while submitting, for writing while closing
+	closed bool
+
+	queues []chan messageJob
+	wg     sync.WaitGroup
+	handle func(messageJob)
+}
+
+// newWorkerPool creates a pool of workers sharing queueSize queued jobs
+func newWorkerPool(workers, queueSize int, handle func(messageJob)) *workerPool {
+	p := &workerPool{
+		queues: make([]chan messageJob, workers),
+		handle: handle,
+	}
+	for i := range p.queues {
+		p.queues[i] = make(chan messageJob, max(queueSize/workers, 1))
+	}
+	return p
+}
+
+// start runs the workers
+func (p *workerPool) start() {
+	for _, queue := range p.queues {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for job := range queue {
+				p.handle(job)
+			}
+		}()
+	}
+}
+
+// submit queues a job, waiting while the queue of its worker is full. It
+// reports false when the pool was closed and the job dropped.
+func (p *workerPool) submit(job messageJob) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return false
+	}
+
+	queue := p.queues[p.worker(job.message.ChannelID)]
+	select {
+	case queue <- job:
+	default:
+		log.Printf("Collector queue full, waiting to process message %s", job.message.ID)
+		queue <- job
+	}
+	return true
+}
+
+// worker picks the worker of a channel
+func (p *workerPool) worker(channelID string) int {
+	h := fnv.New32a()
+	h.Write([]byte(channelID))
+	return int(h.Sum32() % uint32(len(p.queues)))
+}
+
+// close stops accepting jobs and returns once the queued ones are processed
+func (p *workerPool) close() {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		for _, queue := range p.queues {
+			close(queue)
+		}
+	}
+	p.mu.Unlock()
+
+	p.wg.Wait()
+}
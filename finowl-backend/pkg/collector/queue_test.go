@@ -0,0 +1,144 @@
+package collector
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func job(event messageEvent, messageID string) messageJob {
+	return channelJob(event, "channel-"+messageID, messageID)
+}
+
+func channelJob(event messageEvent, channelID, messageID string) messageJob {
+	return messageJob{event: event, category: EarlyAlpha, message: &discordgo.Message{ID: messageID, ChannelID: channelID}}
+}
+
+func TestWorkerPoolKeepsMessageOrder(t *testing.T) {
+	var mu sync.Mutex
+	events := map[string][]messageEvent{}
+
+	pool := newWorkerPool(4, 16, func(j messageJob) {
+		mu.Lock()
+		defer mu.Unlock()
+		events[j.message.ID] = append(events[j.message.ID], j.event)
+	})
+	pool.start()
+
+	for i := 0; i < 50; i++ {
+		id := strconv.Itoa(i)
+		require.True(t, pool.submit(job(messageCreated, id)))
+		require.True(t, pool.submit(job(messageEdited, id)))
+		require.True(t, pool.submit(job(messageDeleted, id)))
+	}
+	pool.close()
+
+	// Every queued job was processed before close returned
+	require.Len(t, events, 50)
+	for id, got := range events {
+		assert.Equal(t, []messageEvent{messageCreated, messageEdited, messageDeleted}, got, id)
+	}
+}
+
+func TestWorkerPoolCheckpointsChannelsInOrder(t *testing.T) {
+	var mu sync.Mutex
+	processed := map[string]map[int]bool{} // channel -> processed messages
+	checkpoints := map[string]int{}
+	var passed []string
+
+	// Each job saves the checkpoint of its channel once processed, as
+	// handleCategoryMessage does. The first message of a channel is slow.
+	pool := newWorkerPool(2, 64, func(j messageJob) {
+		id, _ := strconv.Atoi(j.message.ID)
+		if id%10 == 0 {
+			time.Sleep(20 * time.Millisecond)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		channel := j.message.ChannelID
+		if processed[channel] == nil {
+			processed[channel] = map[int]bool{}
+		}
+		processed[channel][id] = true
+		checkpoints[channel] = max(checkpoints[channel], id)
+
+		// The checkpoint never passes a message of the channel still in the queue
+		for earlier := id - id%10; earlier < checkpoints[channel]; earlier++ {
+			if !processed[channel][earlier] {
+				passed = append(passed, fmt.Sprintf("%s checkpointed %d before processing %d", channel, checkpoints[channel], earlier))
+			}
+		}
+	})
+	pool.start()
+
+	for _, channel := range []int{0, 1} {
+		for i := 0; i < 10; i++ {
+			id := strconv.Itoa(channel*10 + i)
+			require.True(t, pool.submit(channelJob(messageCreated, fmt.Sprintf("channel-%d", channel), id)))
+		}
+	}
+	pool.close()
+
+	assert.Empty(t, passed)
+	assert.Equal(t, map[string]int{"channel-0": 9, "channel-1": 19}, checkpoints)
+}
+
+func TestWorkerPoolBoundsConcurrency(t *testing.T) {
+	var running, peak, processed atomic.Int32
+	release := make(chan struct{})
+
+	pool := newWorkerPool(2, 4, func(messageJob) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		<-release
+		running.Add(-1)
+		processed.Add(1)
+	})
+	pool.start()
+
+	// Both workers hold a job and their queues fill up, the next submit waits
+	submitted := make(chan struct{})
+	go func() {
+		defer close(submitted)
+		for i := 0; i < 7; i++ {
+			pool.submit(job(messageCreated, strconv.Itoa(i)))
+		}
+	}()
+
+	select {
+	case <-submitted:
+		t.Fatal("submit did not wait for room in the queues")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	<-submitted
+	pool.close()
+
+	assert.Equal(t, int32(7), processed.Load())
+	assert.LessOrEqual(t, peak.Load(), int32(2))
+}
+
+func TestWorkerPoolRejectsJobsOnceClosed(t *testing.T) {
+	processed := 0
+	pool := newWorkerPool(1, 1, func(messageJob) { processed++ })
+	pool.start()
+	pool.close()
+	pool.close()
+
+	assert.False(t, pool.submit(job(messageCreated, "1")))
+	assert.Zero(t, processed)
+}
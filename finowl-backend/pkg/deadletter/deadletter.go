@@ -0,0 +1,234 @@
+package deadletter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Status is the state of a dead letter
+type Status string
+
+const (
+	StatusPending  Status = "pending"  // waiting for its next retry
+	StatusResolved Status = "resolved" // stored by a retry or a replay
+	StatusFailed   Status = "failed"   // out of retries, only replayed on request
+)
+
+// ErrNotFound is returned when looking up a dead letter that does not exist
+var ErrNotFound = errors.New("dead letter not found")
+
+// ErrResolved is returned when replaying a dead letter that was already stored
+var ErrResolved = errors.New("dead letter already resolved")
+
+// Entry is a message that could not be stored, along with its retries
+type Entry struct {
+	ID            int64           `json:"id"`
+	Source        string          `json:"source"`               // where the message came from, e.g. discord
+	MessageID     string          `json:"message_id,omitempty"` // ID of the message at its source
+	Payload       json.RawMessage `json:"payload"`              // what is replayed
+	Status        Status          `json:"status"`
+	Attempts      int             `json:"attempts"` // including the one that failed first
+	LastError     string          `json:"last_error"`
+	CreatedAt     time.Time       `json:"created_at"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	ResolvedAt    *time.Time      `json:"resolved_at,omitempty"`
+}
+
+// Store persists the dead letters and replays them
+type Store interface {
+	InsertDeadLetter(e *Entry) error
+	UpdateDeadLetter(e *Entry) error
+	GetDeadLetter(id int64) (*Entry, error)
+	ListDeadLetters(status Status, limit int) ([]Entry, error)
+	// DueDeadLetters returns the pending dead letters whose next retry is due
+	DueDeadLetters(now time.Time, limit int) ([]Entry, error)
+	// ReplayDeadLetter stores the payload of a dead letter again
+	ReplayDeadLetter(e Entry) error
+}
+
+// Config tunes the retries of dead letters
+type Config struct {
+	Interval    time.Duration // How often due dead letters are retried
+	BatchSize   int           // Dead letters retried per interval
+	MaxAttempts int           // Attempts per dead letter, including the first one
+	Backoff     time.Duration // Delay before the first retry, doubled on every retry
+	MaxBackoff  time.Duration // Longest delay between two retries
+	SpoolPath   string        // File holding the dead letters while the database is down
+}
+
+// DefaultConfig returns the retry settings used in production
+func DefaultConfig() Config {
+	return Config{
+		Interval:    30 * time.Second,
+		BatchSize:   50,
+		MaxAttempts: 8,
+		Backoff:     time.Minute,
+		MaxBackoff:  time.Hour,
+		SpoolPath:   "logs/dead_letters.jsonl",
+	}
+}
+
+// Queue records the messages that could not be stored and retries them in
+// the background with an exponential backoff. Dead letters are kept in the
+// database, or spooled to a local file while it cannot be reached.
+type Queue struct {
+	store  Store
+	config Config
+	spool  *spool
+	now    func() time.Time
+
+	mu sync.Mutex // serializes retries and replays
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// NewQueue creates a Queue, Start runs its retrier
+func NewQueue(store Store, config Config) *Queue {
+	return &Queue{
+		store:  store,
+		config: config,
+		spool:  &spool{path: config.SpoolPath},
+		now:    func() time.Time { return time.Now().UTC() },
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start runs the retrier until Close
+func (q *Queue) Start() {
+	go func() {
+		defer close(q.done)
+
+		ticker := time.NewTicker(q.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			q.Retry()
+			select {
+			case <-ticker.C:
+			case <-q.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the retrier and waits for the running retries to finish
+func (q *Queue) Close() {
+	q.once.Do(func() {
+		close(q.stop)
+		<-q.done
+	})
+}
+
+// Add records a message that failed to be stored with cause. Its payload is
+// encoded as JSON and replayed by the store. Errors are logged: a dead letter
+// that can be neither stored nor spooled is lost.
+func (q *Queue) Add(source, messageID string, payload any, cause error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Error encoding dead letter of %s message %s: %v", source, messageID, err)
+		return
+	}
+
+	now := q.now()
+	e := &Entry{
+		Source:        source,
+		MessageID:     messageID,
+		Payload:       body,
+		Status:        StatusPending,
+		Attempts:      1,
+		LastError:     cause.Error(),
+		CreatedAt:     now,
+		NextAttemptAt: now.Add(q.backoff(1)),
+	}
+
+	if err := q.store.InsertDeadLetter(e); err != nil {
+		log.Printf("Error recording dead letter of %s message %s, spooling it: %v", source, messageID, err)
+		if err := q.spool.append(*e); err != nil {
+			log.Printf("Error spooling dead letter of %s message %s: %v", source, messageID, err)
+		}
+	}
+}
+
+// Retry moves the spooled dead letters to the store, then retries the due ones
+func (q *Queue) Retry() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.spool.flush(q.store.InsertDeadLetter); err != nil {
+		log.Printf("Error moving spooled dead letters to the database: %v", err)
+	}
+
+	due, err := q.store.DueDeadLetters(q.now(), q.config.BatchSize)
+	if err != nil {
+		log.Printf("Error retrieving dead letters: %v", err)
+		return
+	}
+
+	for i := range due {
+		q.replay(&due[i])
+		if err := q.store.UpdateDeadLetter(&due[i]); err != nil {
+			log.Printf("Error recording retry of dead letter %d: %v", due[i].ID, err)
+		}
+	}
+}
+
+// Replay replays a dead letter right away, whether it is pending or out of
+// retries, and returns it updated with the outcome
+func (q *Queue) Replay(id int64) (*Entry, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	e, err := q.store.GetDeadLetter(id)
+	if err != nil {
+		return nil, err
+	}
+	if e.Status == StatusResolved {
+		return e, fmt.Errorf("%w: %d", ErrResolved, id)
+	}
+
+	q.replay(e)
+	if err := q.store.UpdateDeadLetter(e); err != nil {
+		return e, err
+	}
+	return e, nil
+}
+
+// List returns the latest dead letters, only those of status unless empty
+func (q *Queue) List(status Status, limit int) ([]Entry, error) {
+	return q.store.ListDeadLetters(status, limit)
+}
+
+// replay runs one attempt of a dead letter and records its outcome in e
+func (q *Queue) replay(e *Entry) {
+	e.Attempts++
+	now := q.now()
+
+	if err := q.store.ReplayDeadLetter(*e); err != nil {
+		e.LastError = err.Error()
+		e.NextAttemptAt = now.Add(q.backoff(e.Attempts))
+		if e.Attempts >= q.config.MaxAttempts {
+			e.Status = StatusFailed
+		}
+		return
+	}
+
+	e.Status = StatusResolved
+	e.ResolvedAt = &now
+}
+
+// backoff returns the delay before the retry following the given attempt
+func (q *Queue) backoff(attempts int) time.Duration {
+	delay := q.config.Backoff
+	for i := 1; i < attempts && delay < q.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, q.config.MaxBackoff)
+}
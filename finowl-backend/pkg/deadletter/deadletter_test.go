@@ -0,0 +1,246 @@
+package deadletter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore keeps the dead letters in memory and replays them with replayErrs, in order
+type memoryStore struct {
+	entries    []*Entry
+	insertErr  error
+	replayErrs []error
+	replayed   []int64
+}
+
+func (s *memoryStore) InsertDeadLetter(e *Entry) error {
+	if s.insertErr != nil {
+		return s.insertErr
+	}
+	e.ID = int64(len(s.entries) + 1)
+	stored := *e
+	s.entries = append(s.entries, &stored)
+	return nil
+}
+
+func (s *memoryStore) UpdateDeadLetter(e *Entry) error {
+	stored := *e
+	s.entries[e.ID-1] = &stored
+	return nil
+}
+
+func (s *memoryStore) GetDeadLetter(id int64) (*Entry, error) {
+	if id < 1 || int(id) > len(s.entries) {
+		return nil, fmt.Errorf("%w: %d", ErrNotFound, id)
+	}
+	e := *s.entries[id-1]
+	return &e, nil
+}
+
+func (s *memoryStore) ListDeadLetters(status Status, limit int) ([]Entry, error) {
+	var entries []Entry
+	for _, e := range s.entries {
+		if status == "" || e.Status == status {
+			entries = append(entries, *e)
+		}
+	}
+	return entries, nil
+}
+
+func (s *memoryStore) DueDeadLetters(now time.Time, limit int) ([]Entry, error) {
+	var due []Entry
+	for _, e := range s.entries {
+		if e.Status == StatusPending && !e.NextAttemptAt.After(now) {
+			due = append(due, *e)
+		}
+	}
+	return due, nil
+}
+
+func (s *memoryStore) ReplayDeadLetter(e Entry) error {
+	s.replayed = append(s.replayed, e.ID)
+	if len(s.replayErrs) == 0 {
+		return nil
+	}
+	err := s.replayErrs[0]
+	s.replayErrs = s.replayErrs[1:]
+	return err
+}
+
+// newTestQueue returns a queue spooling to a temporary directory, whose clock is set by the returned pointer
+func newTestQueue(t *testing.T, store Store) (*Queue, *time.Time) {
+	config := DefaultConfig()
+	config.MaxAttempts = 3
+	config.SpoolPath = filepath.Join(t.TempDir(), "spool", "dead_letters.jsonl")
+
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	q := NewQueue(store, config)
+	q.now = func() time.Time { return now }
+	return q, &now
+}
+
+type payload struct {
+	Content string `json:"content"`
+}
+
+func TestAdd(t *testing.T) {
+	store := &memoryStore{}
+	q, now := newTestQueue(t, store)
+
+	q.Add("discord", "1234", payload{Content: "$AIXBT"}, errors.New("connection refused"))
+
+	require.Len(t, store.entries, 1)
+	e := store.entries[0]
+	assert.Equal(t, StatusPending, e.Status)
+	assert.Equal(t, 1, e.Attempts)
+	assert.Equal(t, "connection refused", e.LastError)
+	assert.Equal(t, "1234", e.MessageID)
+	assert.JSONEq(t, `{"content": "$AIXBT"}`, string(e.Payload))
+	assert.Equal(t, now.Add(time.Minute), e.NextAttemptAt)
+}
+
+func TestAddSpoolsWhileStoreIsDown(t *testing.T) {
+	store := &memoryStore{insertErr: errors.New("connection refused")}
+	q, _ := newTestQueue(t, store)
+
+	q.Add("discord", "1", payload{Content: "first"}, errors.New("connection refused"))
+	q.Add("discord", "2", payload{Content: "second"}, errors.New("connection refused"))
+	assert.Empty(t, store.entries)
+
+	data, err := os.ReadFile(q.config.SpoolPath)
+	require.NoError(t, err)
+	lines := bytes.Split(bytes.TrimSpace(data), []byte("\n"))
+	require.Len(t, lines, 2)
+	var spooled Entry
+	require.NoError(t, json.Unmarshal(lines[0], &spooled))
+	assert.Equal(t, "1", spooled.MessageID)
+
+	// Still down, the spool is kept
+	q.Retry()
+	assert.FileExists(t, q.config.SpoolPath)
+
+	// Back up, the spooled dead letters move to the store
+	store.insertErr = nil
+	q.Retry()
+	require.Len(t, store.entries, 2)
+	assert.Equal(t, "1", store.entries[0].MessageID)
+	assert.Equal(t, "2", store.entries[1].MessageID)
+	assert.NoFileExists(t, q.config.SpoolPath)
+}
+
+func TestSpoolKeepsWhatWasNotInserted(t *testing.T) {
+	s := &spool{path: filepath.Join(t.TempDir(), "dead_letters.jsonl")}
+	for _, id := range []string{"1", "2", "3"} {
+		require.NoError(t, s.append(Entry{MessageID: id, Payload: json.RawMessage(`{}`)}))
+	}
+
+	var inserted []string
+	err := s.flush(func(e *Entry) error {
+		if e.MessageID == "2" {
+			return errors.New("connection refused")
+		}
+		inserted = append(inserted, e.MessageID)
+		return nil
+	})
+	assert.Error(t, err)
+	assert.Equal(t, []string{"1"}, inserted)
+
+	inserted = nil
+	require.NoError(t, s.flush(func(e *Entry) error {
+		inserted = append(inserted, e.MessageID)
+		return nil
+	}))
+	assert.Equal(t, []string{"2", "3"}, inserted)
+
+	// Nothing left to flush
+	require.NoError(t, s.flush(func(*Entry) error { t.Fatal("flushed twice"); return nil }))
+}
+
+func TestRetryBacksOff(t *testing.T) {
+	store := &memoryStore{replayErrs: []error{errors.New("deadlock detected"), errors.New("connection reset")}}
+	q, now := newTestQueue(t, store)
+	start := *now
+
+	q.Add("discord", "1234", payload{}, errors.New("connection refused"))
+
+	// Not due yet
+	q.Retry()
+	assert.Empty(t, store.replayed)
+
+	*now = start.Add(time.Minute)
+	q.Retry()
+	e := store.entries[0]
+	assert.Equal(t, StatusPending, e.Status)
+	assert.Equal(t, 2, e.Attempts)
+	assert.Equal(t, "deadlock detected", e.LastError)
+	assert.Equal(t, now.Add(2*time.Minute), e.NextAttemptAt)
+
+	// The third attempt is the last one
+	*now = e.NextAttemptAt
+	q.Retry()
+	e = store.entries[0]
+	assert.Equal(t, StatusFailed, e.Status)
+	assert.Equal(t, 3, e.Attempts)
+	assert.Equal(t, "connection reset", e.LastError)
+
+	*now = now.Add(24 * time.Hour)
+	q.Retry()
+	assert.Len(t, store.replayed, 2)
+}
+
+func TestRetryResolves(t *testing.T) {
+	store := &memoryStore{}
+	q, now := newTestQueue(t, store)
+
+	q.Add("discord", "1234", payload{}, errors.New("connection refused"))
+	*now = now.Add(time.Hour)
+	q.Retry()
+
+	e := store.entries[0]
+	assert.Equal(t, StatusResolved, e.Status)
+	assert.Equal(t, 2, e.Attempts)
+	require.NotNil(t, e.ResolvedAt)
+	assert.Equal(t, *now, *e.ResolvedAt)
+}
+
+func TestReplay(t *testing.T) {
+	store := &memoryStore{replayErrs: []error{errors.New("still down")}}
+	q, _ := newTestQueue(t, store)
+
+	// Replays do not wait for the next retry
+	q.Add("discord", "1234", payload{}, errors.New("connection refused"))
+	e, err := q.Replay(1)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, e.Status)
+	assert.Equal(t, "still down", e.LastError)
+
+	e, err = q.Replay(1)
+	require.NoError(t, err)
+	assert.Equal(t, StatusResolved, e.Status)
+	assert.Equal(t, StatusResolved, store.entries[0].Status)
+
+	_, err = q.Replay(1)
+	assert.ErrorIs(t, err, ErrResolved)
+
+	_, err = q.Replay(2)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestBackoff(t *testing.T) {
+	q := NewQueue(&memoryStore{}, DefaultConfig())
+
+	assert.Equal(t, time.Minute, q.backoff(1))
+	assert.Equal(t, 2*time.Minute, q.backoff(2))
+	assert.Equal(t, 32*time.Minute, q.backoff(6))
+	assert.Equal(t, time.Hour, q.backoff(7))
+	assert.Equal(t, time.Hour, q.backoff(50))
+}
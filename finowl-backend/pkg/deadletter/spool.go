@@ -0,0 +1,84 @@
+package deadletter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// spool keeps dead letters in a JSON lines file while the database is down
+type spool struct {
+	path string
+	mu   sync.Mutex
+}
+
+// append adds a dead letter at the end of the spool
+func (s *spool) append(e Entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
+
+// flush hands every spooled dead letter to insert, in order. The spool is
+// removed once all of them were inserted; when insert fails, the remaining
+// ones are kept for the next flush.
+func (s *spool) flush(insert func(e *Entry) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	for i, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var e Entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			log.Printf("Skipping malformed line %d of dead letter spool %s: %v", i+1, s.path, err)
+			continue
+		}
+		e.ID = 0
+		if err := insert(&e); err != nil {
+			return errors.Join(err, s.rewrite(bytes.Join(lines[i:], nil)))
+		}
+	}
+
+	return os.Remove(s.path)
+}
+
+// rewrite replaces the content of the spool
+func (s *spool) rewrite(data []byte) error {
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
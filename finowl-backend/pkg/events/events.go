@@ -0,0 +1,192 @@
+package events
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"finowl-backend/pkg/ticker"
+)
+
+// Type identifies what happened to a ticker
+type Type string
+
+const (
+	MentionStored   Type = "mention_stored"   // An influencer mention was recorded
+	TickerCreated   Type = "ticker_created"   // A ticker was mentioned for the first time
+	CategoryChanged Type = "category_changed" // A ticker moved to another mindshare category
+)
+
+// Event is a change to a ticker pushed to stream subscribers
+type Event struct {
+	ID               uint64          `json:"id"`
+	Type             Type            `json:"type"`
+	Ticker           string          `json:"ticker_symbol"`
+	Time             time.Time       `json:"time"`
+	Category         string          `json:"category,omitempty"`          // Mindshare category after the change
+	PreviousCategory string          `json:"previous_category,omitempty"` // Only set on category changes
+	MindshareScore   float64         `json:"mindshare_score,omitempty"`
+	Mention          *ticker.Mention `json:"mention,omitempty"` // Only set on stored mentions
+}
+
+// Filter selects the events a subscriber receives. Empty fields match everything.
+type Filter struct {
+	Tickers []string // Ticker symbols, compared case-insensitively
+
+	// Category matches either the mindshare category of the event or the
+	// channel category of its mention
+	Category string
+}
+
+// Matches reports whether the event passes the filter
+func (f Filter) Matches(e Event) bool {
+	if len(f.Tickers) > 0 {
+		found := false
+		for _, symbol := range f.Tickers {
+			if strings.EqualFold(symbol, e.Ticker) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if f.Category != "" {
+		if e.Category == f.Category {
+			return true
+		}
+		return e.Mention != nil && e.Mention.Category == f.Category
+	}
+
+	return true
+}
+
+// Subscription receives the events of a Broker matching its filter
+type Subscription struct {
+	events chan Event
+	filter Filter
+
+	evicted bool // guarded by the broker lock
+}
+
+// Events returns the channel events are delivered on. It is closed when the
+// subscription is cancelled or evicted.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Broker fans events out to subscribers. Every subscriber has a bounded
+// buffer; a subscriber whose buffer is full when an event is published is
+// considered too slow and evicted, so a stuck client never blocks ingestion.
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+	bufferSize  int
+	lastID      uint64
+	closed      bool
+}
+
+// NewBroker creates a Broker giving every subscriber a buffer of bufferSize events
+func NewBroker(bufferSize int) *Broker {
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+	return &Broker{
+		subscribers: make(map[*Subscription]struct{}),
+		bufferSize:  bufferSize,
+	}
+}
+
+// Subscribe registers a subscriber receiving the events matching filter. The
+// returned subscription must be cancelled with Unsubscribe.
+func (b *Broker) Subscribe(filter Filter) *Subscription {
+	sub := &Subscription{
+		events: make(chan Event, b.bufferSize),
+		filter: filter,
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		close(sub.events)
+		return sub
+	}
+	b.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// Unsubscribe cancels a subscription and closes its channel
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.remove(sub)
+}
+
+// Evicted reports whether the subscription was dropped for being too slow
+func (b *Broker) Evicted(sub *Subscription) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return sub.evicted
+}
+
+// Publish assigns the event an ID and delivers it to every matching subscriber without blocking
+func (b *Broker) Publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.lastID++
+	e.ID = b.lastID
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+
+	for sub := range b.subscribers {
+		if !sub.filter.Matches(e) {
+			continue
+		}
+
+		select {
+		case sub.events <- e:
+		default:
+			sub.evicted = true
+			b.remove(sub)
+		}
+	}
+}
+
+// Subscribers returns the number of active subscriptions
+func (b *Broker) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.subscribers)
+}
+
+// Close drops every subscriber; later publications are discarded
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subscribers {
+		b.remove(sub)
+	}
+	b.closed = true
+}
+
+// remove must be called with the lock held
+func (b *Broker) remove(sub *Subscription) {
+	if _, ok := b.subscribers[sub]; !ok {
+		return
+	}
+	delete(b.subscribers, sub)
+	close(sub.events)
+}
@@ -0,0 +1,100 @@
+package events
+
+import (
+	"testing"
+
+	"finowl-backend/pkg/ticker"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterMatches(t *testing.T) {
+	mention := Event{Type: MentionStored, Ticker: "AIXBT", Category: "Alpha", Mention: &ticker.Mention{Category: "EarlyAlpha"}}
+	change := Event{Type: CategoryChanged, Ticker: "ETH", Category: "High Alpha", PreviousCategory: "Alpha"}
+
+	tests := []struct {
+		name    string
+		filter  Filter
+		event   Event
+		matches bool
+	}{
+		{"empty filter", Filter{}, mention, true},
+		{"ticker case-insensitive", Filter{Tickers: []string{"aixbt"}}, mention, true},
+		{"other ticker", Filter{Tickers: []string{"BTC", "SOL"}}, mention, false},
+		{"channel category", Filter{Category: "EarlyAlpha"}, mention, true},
+		{"mindshare category", Filter{Category: "High Alpha"}, change, true},
+		{"other category", Filter{Category: "MacroNews"}, mention, false},
+		{"ticker and category", Filter{Tickers: []string{"ETH"}, Category: "High Alpha"}, change, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.matches, tt.filter.Matches(tt.event))
+		})
+	}
+}
+
+func TestBrokerFanOut(t *testing.T) {
+	broker := NewBroker(4)
+
+	all := broker.Subscribe(Filter{})
+	eth := broker.Subscribe(Filter{Tickers: []string{"ETH"}})
+	defer broker.Unsubscribe(all)
+	defer broker.Unsubscribe(eth)
+
+	broker.Publish(Event{Type: TickerCreated, Ticker: "AIXBT"})
+	broker.Publish(Event{Type: TickerCreated, Ticker: "ETH"})
+
+	first := <-all.Events()
+	second := <-all.Events()
+	assert.Equal(t, "AIXBT", first.Ticker)
+	assert.Equal(t, "ETH", second.Ticker)
+	assert.Less(t, first.ID, second.ID)
+	assert.False(t, first.Time.IsZero())
+
+	only := <-eth.Events()
+	assert.Equal(t, "ETH", only.Ticker)
+	assert.Empty(t, eth.Events())
+}
+
+func TestBrokerEvictsSlowSubscribers(t *testing.T) {
+	broker := NewBroker(2)
+
+	slow := broker.Subscribe(Filter{})
+	fast := broker.Subscribe(Filter{})
+	defer broker.Unsubscribe(fast)
+
+	for i := 0; i < 3; i++ {
+		broker.Publish(Event{Type: MentionStored, Ticker: "AIXBT"})
+		<-fast.Events()
+	}
+
+	// The slow subscriber keeps what was buffered, then its channel is closed
+	assert.True(t, broker.Evicted(slow))
+	assert.Equal(t, 1, broker.Subscribers())
+
+	received := 0
+	for range slow.Events() {
+		received++
+	}
+	assert.Equal(t, 2, received)
+	assert.False(t, broker.Evicted(fast))
+
+	// Unsubscribing an evicted subscription is harmless
+	broker.Unsubscribe(slow)
+}
+
+func TestBrokerClose(t *testing.T) {
+	broker := NewBroker(1)
+	sub := broker.Subscribe(Filter{})
+
+	broker.Close()
+	_, open := <-sub.Events()
+	require.False(t, open)
+
+	// Publishing and subscribing after Close do not panic
+	broker.Publish(Event{Type: TickerCreated, Ticker: "AIXBT"})
+	_, open = <-broker.Subscribe(Filter{}).Events()
+	assert.False(t, open)
+}
@@ -63,3 +63,12 @@ func (r *InfluencerRankings) FindInfluencer(query string) (*Influencer, string)
 	// Return nil if no match is found
 	return nil, ""
 }
+
+// TierCounts returns the number of influencers in every tier.
+func (r *InfluencerRankings) TierCounts() map[int]int {
+	counts := make(map[int]int)
+	for _, influencer := range r.Accounts {
+		counts[influencer.Tier]++
+	}
+	return counts
+}
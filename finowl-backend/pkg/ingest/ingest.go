@@ -0,0 +1,163 @@
+// Package ingest runs posts from sources other than the collected Discord
+// channels, such as Telegram relays, RSS scrapers or manual curation, through
+// the same validation, ticker extraction and mindshare pipeline.
+package ingest
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"finowl-backend/pkg/analyzer"
+	"finowl-backend/pkg/influencer"
+	"finowl-backend/pkg/storer"
+	"finowl-backend/pkg/ticker"
+)
+
+const (
+	maxAuthorLength   = 255
+	maxCategoryLength = 50
+
+	// maxClockSkew bounds how far in the future an item may be timestamped
+	maxClockSkew = 5 * time.Minute
+)
+
+// sourcePattern matches a source name, e.g. "telegram" or "rss-coindesk"
+var sourcePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// Item is a post to ingest
+type Item struct {
+	Author    string    `json:"author"`
+	Content   string    `json:"content"`
+	Timestamp time.Time `json:"timestamp"` // when it was posted, the time it is ingested when omitted
+	URL       string    `json:"url"`       // link to the post, optional
+	Source    string    `json:"source"`
+	Category  string    `json:"category"` // channel category of its mentions, optional
+}
+
+// Validate checks the fields of an item. Content too short to be a tweet is
+// not an error, the item is reported invalid once ingested.
+func (it Item) Validate(now time.Time) error {
+	if strings.TrimSpace(it.Author) == "" {
+		return fmt.Errorf("author is required")
+	}
+	if len(it.Author) > maxAuthorLength {
+		return fmt.Errorf("author is longer than %d characters", maxAuthorLength)
+	}
+	if strings.TrimSpace(it.Content) == "" {
+		return fmt.Errorf("content is required")
+	}
+	if !sourcePattern.MatchString(it.Source) {
+		return fmt.Errorf("invalid source %q, expected up to 32 lowercase letters, digits, '-' or '_'", it.Source)
+	}
+	if len(it.Category) > maxCategoryLength {
+		return fmt.Errorf("category is longer than %d characters", maxCategoryLength)
+	}
+	if it.Timestamp.After(now.Add(maxClockSkew)) {
+		return fmt.Errorf("timestamp %s is in the future", it.Timestamp.Format(time.RFC3339))
+	}
+	if it.URL != "" {
+		u, err := url.Parse(it.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid url %q", it.URL)
+		}
+	}
+	return nil
+}
+
+// Status is what became of an ingested item
+type Status string
+
+const (
+	StatusStored    Status = "stored"    // stored along with its mentions
+	StatusInvalid   Status = "invalid"   // too short to be a tweet
+	StatusDuplicate Status = "duplicate" // the same tweet was stored before
+	StatusFailed    Status = "failed"    // could not be stored
+)
+
+// Result reports the outcome of an ingested item
+type Result struct {
+	Status  Status   `json:"status"`
+	TweetID string   `json:"tweet_id,omitempty"`
+	Tickers []string `json:"tickers,omitempty"` // tickers the stored mentions were recorded for
+	Error   string   `json:"error,omitempty"`
+}
+
+// Store stores the ingested tweets, satisfied by *storer.Storer
+type Store interface {
+	StoreTweet(tweet storer.Tweet, tickers []ticker.Ticker) error
+}
+
+// Pipeline stores ingested items like the collector stores relayed messages
+type Pipeline struct {
+	store       Store
+	influencers influencer.InfluencerRankings
+}
+
+func NewPipeline(store Store, influencers influencer.InfluencerRankings) *Pipeline {
+	return &Pipeline{
+		store:       store,
+		influencers: influencers,
+	}
+}
+
+// Ingest extracts the tweet of every item and stores it with its mentions.
+// Items are expected to be validated, one failing does not stop the batch.
+func (p *Pipeline) Ingest(items []Item) []Result {
+	ta := analyzer.NewTweetAnalyzer()
+
+	results := make([]Result, len(items))
+	for i, item := range items {
+		results[i] = p.ingest(ta, item)
+	}
+	return results
+}
+
+// ingest stores a single item
+func (p *Pipeline) ingest(ta *analyzer.TweetAnalyzer, item Item) Result {
+	timestamp := item.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	tweet := ta.ProcessMessage(item.Content, item.Author, timestamp.UTC())
+	if !tweet.IsValid {
+		return Result{Status: StatusInvalid}
+	}
+
+	// The link of the post becomes the tweet link of its mentions
+	if item.URL != "" {
+		links := []string{item.URL}
+		for _, link := range tweet.Links {
+			if link != item.URL {
+				links = append(links, link)
+			}
+		}
+		tweet.Links = links
+		tweet.Handle = analyzer.HandleFromStatusURL(item.URL)
+	}
+
+	tt := storer.TransformToStorerTweet(*tweet)
+	tt.Category = item.Category
+	tt.Source = item.Source
+
+	// The tweet and its mentions are stored together or not at all
+	tickers := storer.ConvertTweetsToTickers([]storer.Tweet{tt}, p.influencers)
+	if err := p.store.StoreTweet(tt, tickers); err != nil {
+		if errors.Is(err, storer.ErrTweetExists) {
+			return Result{Status: StatusDuplicate}
+		}
+		log.Printf("Error storing tweet ingested from %s: %v", item.Source, err)
+		return Result{Status: StatusFailed, Error: "failed to store tweet"}
+	}
+
+	result := Result{Status: StatusStored, TweetID: tt.ID}
+	for _, t := range tickers {
+		result.Tickers = append(result.Tickers, t.TickerSymbol)
+	}
+	return result
+}
@@ -0,0 +1,149 @@
+package ingest
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"finowl-backend/pkg/influencer"
+	"finowl-backend/pkg/mindshare"
+	"finowl-backend/pkg/storer"
+	"finowl-backend/pkg/ticker"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	if err := mindshare.Configure(mindshare.DefaultScoringConfig(), map[int]int{1: 5, 2: 15, 3: 33}); err != nil {
+		log.Fatalf("failed to configure scoring: %v", err)
+	}
+	os.Exit(m.Run())
+}
+
+// memoryStore keeps the stored tweets and mentions in memory
+type memoryStore struct {
+	tweets  []storer.Tweet
+	tickers []ticker.Ticker
+	err     error
+}
+
+func (s *memoryStore) StoreTweet(tweet storer.Tweet, tickers []ticker.Ticker) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.tweets = append(s.tweets, tweet)
+	s.tickers = append(s.tickers, tickers...)
+	return nil
+}
+
+var rankings = influencer.InfluencerRankings{Accounts: map[string]influencer.Influencer{
+	"aixbt_agent": {Tier: 1, Category: "AI"},
+}}
+
+func TestValidate(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	valid := Item{Author: "aixbt", Content: "$AIXBT mindshare keeps climbing", Source: "telegram"}
+
+	tests := []struct {
+		name   string
+		modify func(*Item)
+		err    string
+	}{
+		{"valid", func(*Item) {}, ""},
+		{"with url and timestamp", func(it *Item) {
+			it.URL = "https://x.com/aixbt_agent/status/1879491000000000000"
+			it.Timestamp = now.Add(-time.Hour)
+		}, ""},
+		{"missing author", func(it *Item) { it.Author = " " }, "author is required"},
+		{"long author", func(it *Item) { it.Author = strings.Repeat("a", 256) }, "author is longer"},
+		{"missing content", func(it *Item) { it.Content = "" }, "content is required"},
+		{"missing source", func(it *Item) { it.Source = "" }, "invalid source"},
+		{"uppercase source", func(it *Item) { it.Source = "Telegram" }, "invalid source"},
+		{"long category", func(it *Item) { it.Category = strings.Repeat("c", 51) }, "category is longer"},
+		{"future timestamp", func(it *Item) { it.Timestamp = now.Add(time.Hour) }, "in the future"},
+		{"relative url", func(it *Item) { it.URL = "/status/1" }, "invalid url"},
+		{"ftp url", func(it *Item) { it.URL = "ftp://example.com/post" }, "invalid url"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := valid
+			tt.modify(&item)
+
+			err := item.Validate(now)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestIngest(t *testing.T) {
+	store := &memoryStore{}
+	pipeline := NewPipeline(store, rankings)
+
+	posted := time.Date(2025, 3, 1, 9, 30, 0, 0, time.FixedZone("CET", 3600))
+	results := pipeline.Ingest([]Item{
+		{
+			Author:    "aixbt_agent",
+			Content:   "$AIXBT mindshare keeps climbing, $VIRTUAL agents everywhere",
+			Timestamp: posted,
+			URL:       "https://x.com/aixbt_agent/status/1879491000000000000",
+			Source:    "telegram",
+			Category:  "EarlyAlpha",
+		},
+		{Author: "degen", Content: "gm", Source: "rss"},
+		{Author: "degen", Content: "$SOL is the only chain that matters", Source: "rss"},
+	})
+
+	require.Len(t, results, 3)
+	assert.Equal(t, StatusStored, results[0].Status)
+	assert.Equal(t, []string{"AIXBT", "VIRTUAL"}, results[0].Tickers)
+	assert.Equal(t, Result{Status: StatusInvalid}, results[1])
+
+	// $SOL is an excluded coin, the tweet is stored without mentions
+	assert.Equal(t, StatusStored, results[2].Status)
+	assert.Empty(t, results[2].Tickers)
+
+	require.Len(t, store.tweets, 2)
+	tweet := store.tweets[0]
+	assert.Equal(t, results[0].TweetID, tweet.ID)
+	assert.Equal(t, "2025-03-01T08:30:00Z", tweet.Timestamp)
+	assert.Equal(t, "aixbt_agent", tweet.Handle)
+	assert.Equal(t, "telegram", tweet.Source)
+	assert.Equal(t, "EarlyAlpha", tweet.Category)
+	assert.Equal(t, []string{"https://x.com/aixbt_agent/status/1879491000000000000"}, tweet.Links)
+	assert.Empty(t, tweet.MessageID)
+
+	// Omitted timestamps default to the time of ingestion
+	ingested, err := time.Parse(time.RFC3339, store.tweets[1].Timestamp)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), ingested, time.Minute)
+
+	require.Len(t, store.tickers, 2)
+	mention := store.tickers[0].MentionDetails.Influencers["aixbt_agent"]
+	assert.Equal(t, 1, mention.Tier)
+	assert.Equal(t, "https://x.com/aixbt_agent/status/1879491000000000000", mention.TweetLink)
+}
+
+func TestIngestFailures(t *testing.T) {
+	item := Item{Author: "aixbt_agent", Content: "$AIXBT mindshare keeps climbing", Source: "telegram"}
+
+	// Nothing was stored, the item can be sent again
+	store := &memoryStore{err: errors.New("connection refused")}
+	results := NewPipeline(store, rankings).Ingest([]Item{item})
+	assert.Equal(t, Result{Status: StatusFailed, Error: "failed to store tweet"}, results[0])
+
+	// Another source relayed the same tweet before, its mentions are not recorded twice
+	store = &memoryStore{err: fmt.Errorf("%w (status:1879491000000000000)", storer.ErrDuplicateTweet)}
+	results = NewPipeline(store, rankings).Ingest([]Item{item})
+	assert.Equal(t, Result{Status: StatusDuplicate}, results[0])
+	assert.Empty(t, store.tickers)
+}
@@ -0,0 +1,176 @@
+package mindshare
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+// TierScoring holds the weight and bonus multipliers of one influencer tier
+type TierScoring struct {
+	Weight        float64 `yaml:"weight"`         // Score added per influencer of the tier
+	PresenceBonus float64 `yaml:"presence_bonus"` // Multiplier once the tier mentions the ticker at all
+	MultipleBonus float64 `yaml:"multiple_bonus"` // Multiplier once MultipleMin influencers of the tier mention it
+	MultipleMin   int     `yaml:"multiple_min"`
+}
+
+// CategoryBoundary assigns Name to scores strictly above Above
+type CategoryBoundary struct {
+	Name  string  `yaml:"name"`
+	Above float64 `yaml:"above"`
+}
+
+// ScoringConfig is the tunable mindshare scoring model
+type ScoringConfig struct {
+	Tiers map[int]TierScoring `yaml:"tiers"`
+
+	// The theoretical maximum raw score is reached when ThresholdRatio of every
+	// tier mentions a ticker, boosted by ThresholdBonus. It normalizes to MaxScore.
+	ThresholdRatio float64 `yaml:"threshold_ratio"`
+	ThresholdBonus float64 `yaml:"threshold_bonus"`
+	MaxScore       float64 `yaml:"max_score"`
+
+	// ScoreCap bounds the normalized score, 0 leaves it uncapped
+	ScoreCap float64 `yaml:"score_cap"`
+
+	// Categories ordered from the highest boundary down; the last one catches every other score
+	Categories []CategoryBoundary `yaml:"categories"`
+}
+
+// MaxCategoryNameLength bounds category names, the size of the category columns
+const MaxCategoryNameLength = 50
+
+// Scoring is the scoring model used to compute mindshare, replaced at startup by Configure
+var Scoring = DefaultScoringConfig()
+
+// TotalTierCounts represents the total number of influencers per tier, computed
+// from the influencer rankings at startup by Configure
+var TotalTierCounts map[int]int
+
+// DefaultScoringConfig returns the scoring model the service shipped with
+func DefaultScoringConfig() ScoringConfig {
+	return ScoringConfig{
+		Tiers: map[int]TierScoring{
+			1: {Weight: 95, PresenceBonus: 5.5, MultipleBonus: 1.3, MultipleMin: 2}, // Top influencers
+			2: {Weight: 55, PresenceBonus: 3.9, MultipleBonus: 1.6, MultipleMin: 2}, // Mid influencers
+			3: {Weight: 15, PresenceBonus: 1, MultipleBonus: 1.3, MultipleMin: 3},   // Regular influencers
+		},
+		ThresholdRatio: 0.25,
+		ThresholdBonus: 1.2 * 1.3,
+		MaxScore:       1000,
+		Categories: []CategoryBoundary{
+			{Name: "High Alpha", Above: 600},
+			{Name: "Alpha", Above: 250},
+			{Name: "Trenches", Above: 0},
+		},
+	}
+}
+
+// LoadScoringConfig reads a scoring config file and validates it
+func LoadScoringConfig(filePath string) (*ScoringConfig, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading scoring config file: %w", err)
+	}
+
+	var config ScoringConfig
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, fmt.Errorf("error unmarshaling scoring config: %w", err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid scoring config: %w", err)
+	}
+
+	return &config, nil
+}
+
+// Validate checks that the scoring model is consistent
+func (c ScoringConfig) Validate() error {
+	if len(c.Tiers) == 0 {
+		return fmt.Errorf("no tiers configured")
+	}
+	for tier, scoring := range c.Tiers {
+		if scoring.Weight <= 0 {
+			return fmt.Errorf("tier %d: weight must be positive", tier)
+		}
+		if scoring.PresenceBonus <= 0 || scoring.MultipleBonus <= 0 {
+			return fmt.Errorf("tier %d: bonus multipliers must be positive", tier)
+		}
+		if scoring.MultipleMin < 2 {
+			return fmt.Errorf("tier %d: multiple_min must be at least 2", tier)
+		}
+	}
+
+	if c.ThresholdRatio <= 0 || c.ThresholdRatio > 1 {
+		return fmt.Errorf("threshold_ratio must be in (0, 1], got %v", c.ThresholdRatio)
+	}
+	if c.ThresholdBonus <= 0 {
+		return fmt.Errorf("threshold_bonus must be positive")
+	}
+	if c.MaxScore <= 0 {
+		return fmt.Errorf("max_score must be positive")
+	}
+	if c.ScoreCap < 0 {
+		return fmt.Errorf("score_cap must not be negative")
+	}
+
+	if len(c.Categories) == 0 {
+		return fmt.Errorf("no categories configured")
+	}
+	for i, category := range c.Categories {
+		if category.Name == "" {
+			return fmt.Errorf("category %d has no name", i)
+		}
+		if len(category.Name) > MaxCategoryNameLength {
+			return fmt.Errorf("category %q is longer than %d characters", category.Name, MaxCategoryNameLength)
+		}
+		if i > 0 && category.Above >= c.Categories[i-1].Above {
+			return fmt.Errorf("category %q must have a lower boundary than %q", category.Name, c.Categories[i-1].Name)
+		}
+	}
+	if last := c.Categories[len(c.Categories)-1]; last.Above != 0 {
+		return fmt.Errorf("lowest category %q must start above 0", last.Name)
+	}
+
+	return nil
+}
+
+// theoreticalMaxRawScore is the raw score normalized to MaxScore
+func (c ScoringConfig) theoreticalMaxRawScore(totalTierCounts map[int]int) float64 {
+	var threshold float64
+	for tier, scoring := range c.Tiers {
+		threshold += math.Ceil(c.ThresholdRatio*float64(totalTierCounts[tier])) * scoring.Weight
+	}
+	return threshold * c.ThresholdBonus
+}
+
+// Configure validates the scoring model against the influencer tier counts and
+// makes both the ones used to compute mindshare
+func Configure(config ScoringConfig, totalTierCounts map[int]int) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	tiers := make([]int, 0, len(totalTierCounts))
+	for tier := range totalTierCounts {
+		tiers = append(tiers, tier)
+	}
+	sort.Ints(tiers)
+	for _, tier := range tiers {
+		if _, ok := config.Tiers[tier]; !ok && totalTierCounts[tier] > 0 {
+			return fmt.Errorf("influencers of tier %d have no scoring weight", tier)
+		}
+	}
+
+	if config.theoreticalMaxRawScore(totalTierCounts) <= 0 {
+		return fmt.Errorf("no influencers in any scored tier")
+	}
+
+	Scoring = config
+	TotalTierCounts = totalTierCounts
+	return nil
+}
@@ -0,0 +1,101 @@
+package mindshare
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"finowl-backend/pkg/ticker"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadScoringConfigMatchesDefaults(t *testing.T) {
+	config, err := LoadScoringConfig("../../scoring.yaml")
+	require.NoError(t, err)
+
+	defaults := DefaultScoringConfig()
+	assert.Equal(t, defaults.Tiers, config.Tiers)
+	assert.Equal(t, defaults.Categories, config.Categories)
+	assert.InDelta(t, defaults.ThresholdBonus, config.ThresholdBonus, 1e-9)
+	assert.Equal(t, defaults.ThresholdRatio, config.ThresholdRatio)
+	assert.Equal(t, defaults.MaxScore, config.MaxScore)
+	assert.Zero(t, config.ScoreCap)
+}
+
+func TestLoadScoringConfigRejectsUnknownFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scoring.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("max_scor: 1000\n"), 0o600))
+
+	_, err := LoadScoringConfig(path)
+	assert.Error(t, err)
+}
+
+func TestScoringConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *ScoringConfig)
+	}{
+		{"no tiers", func(c *ScoringConfig) { c.Tiers = nil }},
+		{"zero weight", func(c *ScoringConfig) { c.Tiers[1] = TierScoring{PresenceBonus: 1, MultipleBonus: 1, MultipleMin: 2} }},
+		{"zero bonus", func(c *ScoringConfig) { c.Tiers[2] = TierScoring{Weight: 55, MultipleBonus: 1, MultipleMin: 2} }},
+		{"multiple_min below 2", func(c *ScoringConfig) {
+			c.Tiers[3] = TierScoring{Weight: 15, PresenceBonus: 1, MultipleBonus: 1, MultipleMin: 1}
+		}},
+		{"threshold ratio above 1", func(c *ScoringConfig) { c.ThresholdRatio = 1.5 }},
+		{"zero threshold bonus", func(c *ScoringConfig) { c.ThresholdBonus = 0 }},
+		{"zero max score", func(c *ScoringConfig) { c.MaxScore = 0 }},
+		{"negative cap", func(c *ScoringConfig) { c.ScoreCap = -1 }},
+		{"no categories", func(c *ScoringConfig) { c.Categories = nil }},
+		{"unnamed category", func(c *ScoringConfig) { c.Categories[1].Name = "" }},
+		{"category name too long", func(c *ScoringConfig) { c.Categories[1].Name = strings.Repeat("Alpha", 11) }},
+		{"boundaries out of order", func(c *ScoringConfig) { c.Categories[1].Above = 700 }},
+		{"lowest category above 0", func(c *ScoringConfig) { c.Categories[2].Above = 10 }},
+	}
+
+	require.NoError(t, DefaultScoringConfig().Validate())
+
+	// Categories are not limited to the default names
+	renamed := DefaultScoringConfig()
+	renamed.Categories = append([]CategoryBoundary{{Name: "Mega Alpha", Above: 900}}, renamed.Categories...)
+	require.NoError(t, renamed.Validate())
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultScoringConfig()
+			tt.modify(&config)
+			assert.Error(t, config.Validate())
+		})
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	previousScoring, previousCounts := Scoring, TotalTierCounts
+	defer func() { Scoring, TotalTierCounts = previousScoring, previousCounts }()
+
+	// Influencers of a tier without a weight could never be scored
+	assert.Error(t, Configure(DefaultScoringConfig(), map[int]int{1: 5, 4: 2}))
+
+	// Nobody to normalize against
+	assert.Error(t, Configure(DefaultScoringConfig(), map[int]int{}))
+
+	config := DefaultScoringConfig()
+	config.ScoreCap = 500
+	config.Categories = []CategoryBoundary{{Name: "Hot", Above: 100}, {Name: "Cold", Above: 0}}
+	require.NoError(t, Configure(config, testTierCounts))
+	assert.Equal(t, testTierCounts, TotalTierCounts)
+
+	mindshare, err := CalculateMindshare(ticker.MentionDetails{}, ticker.MentionDetails{Influencers: map[string]ticker.MentionDetail{
+		"whale1": {Tier: 1},
+		"whale2": {Tier: 1},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, 500.0, mindshare.Score, "score should be capped")
+	assert.Equal(t, "Hot", mindshare.Category)
+
+	category, err := DetermineCategory(0)
+	require.NoError(t, err)
+	assert.Equal(t, "Cold", category)
+}
@@ -0,0 +1,26 @@
+package mindshare
+
+import (
+	"math"
+	"time"
+)
+
+// DecayHalfLife is the time after which a mention counts for half of its
+// weight. Zero disables decay. It is set once at startup from the environment.
+var DecayHalfLife time.Duration
+
+// DecayFactor returns the weight, between 0 and 1, left to a mention made at
+// mentionedAt when scored at now. Mentions without a timestamp, or from the
+// future, keep their full weight.
+func DecayFactor(mentionedAt, now time.Time, halfLife time.Duration) float64 {
+	if halfLife <= 0 || mentionedAt.IsZero() || now.IsZero() {
+		return 1
+	}
+
+	age := now.Sub(mentionedAt)
+	if age <= 0 {
+		return 1
+	}
+
+	return math.Pow(0.5, float64(age)/float64(halfLife))
+}
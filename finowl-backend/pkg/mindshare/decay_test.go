@@ -0,0 +1,108 @@
+package mindshare
+
+import (
+	"testing"
+	"time"
+
+	"finowl-backend/pkg/ticker"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecayFactor(t *testing.T) {
+	now := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	halfLife := 72 * time.Hour
+
+	tests := []struct {
+		name        string
+		mentionedAt time.Time
+		halfLife    time.Duration
+		expected    float64
+	}{
+		{"fresh mention", now, halfLife, 1},
+		{"one half-life old", now.Add(-halfLife), halfLife, 0.5},
+		{"two half-lives old", now.Add(-2 * halfLife), halfLife, 0.25},
+		{"decay disabled", now.Add(-6 * 7 * 24 * time.Hour), 0, 1},
+		{"unknown mention time", time.Time{}, halfLife, 1},
+		{"future mention", now.Add(time.Hour), halfLife, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.expected, DecayFactor(tt.mentionedAt, now, tt.halfLife), 1e-9)
+		})
+	}
+}
+
+func TestCalculateDecayedScore(t *testing.T) {
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	halfLife := 72 * time.Hour
+
+	mentionAt := func(at time.Time) ticker.MentionDetails {
+		return ticker.MentionDetails{Influencers: map[string]ticker.MentionDetail{
+			"whale1": {Tier: 1, MentionedAt: at},
+		}}
+	}
+
+	fresh, err := CalculateDecayedScore(mentionAt(now), testTierCounts, now, halfLife)
+	require.NoError(t, err)
+	undecayed, err := CalculateScore(mentionAt(now), testTierCounts)
+	require.NoError(t, err)
+	assert.InDelta(t, undecayed, fresh, 1e-9)
+
+	sixWeeksOld, err := CalculateDecayedScore(mentionAt(now.Add(-6*7*24*time.Hour)), testTierCounts, now, halfLife)
+	require.NoError(t, err)
+
+	// A tier-1 shill from six weeks ago no longer ranks as High Alpha
+	category, err := DetermineCategory(sixWeeksOld)
+	require.NoError(t, err)
+	assert.Equal(t, "Trenches", category)
+	assert.Less(t, sixWeeksOld, fresh/1000)
+}
+
+func TestCalculateDecayedScorePerMention(t *testing.T) {
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	halfLife := 72 * time.Hour
+
+	score := func(times ...time.Time) float64 {
+		details := ticker.MentionDetails{Influencers: map[string]ticker.MentionDetail{}}
+		for _, at := range times {
+			details.AddMention("whale1", ticker.MentionDetail{Tier: 1, MentionedAt: at})
+		}
+		score, err := CalculateDecayedScore(details, testTierCounts, now, halfLife)
+		require.NoError(t, err)
+		return score
+	}
+
+	once := score(now.Add(-2 * halfLife))
+
+	// Every mention decays on its own and adds up: two mentions two half-lives
+	// old weigh as much as a single one made a half-life ago
+	assert.InDelta(t, score(now.Add(-halfLife)), score(now.Add(-2*halfLife), now.Add(-2*halfLife)), 1e-6)
+	assert.Greater(t, score(now.Add(-2*halfLife), now.Add(-3*halfLife)), once)
+
+	// A new mention refreshes the influencer without the old ones disappearing
+	assert.InDelta(t, score(now), score(now, now.Add(-2*halfLife)), 1e-9)
+
+	// An influencer never weighs more than their tier weight, however often they post
+	spammed := make([]time.Time, 10)
+	for i := range spammed {
+		spammed[i] = now.Add(-time.Duration(i) * time.Minute)
+	}
+	assert.InDelta(t, score(now), score(spammed...), 1e-9)
+}
+
+func TestMentionDetailsAddMention(t *testing.T) {
+	at := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	details := ticker.MentionDetails{Influencers: map[string]ticker.MentionDetail{}}
+
+	details.AddMention("whale1", ticker.MentionDetail{Tier: 1, TweetID: "new", MentionedAt: at})
+	details.AddMention("whale1", ticker.MentionDetail{Tier: 2, TweetID: "old", MentionedAt: at.Add(-time.Hour)})
+
+	// The latest mention describes the influencer, every one is kept for decay
+	mention := details.Influencers["whale1"]
+	assert.Equal(t, "new", mention.TweetID)
+	assert.Equal(t, 1, mention.Tier)
+	assert.Equal(t, []time.Time{at, at.Add(-time.Hour)}, mention.MentionTimes)
+}
@@ -4,6 +4,7 @@ import (
 	"finowl-backend/pkg/ticker"
 	"fmt"
 	"math"
+	"time"
 )
 
 // Mindshare holds the computed mindshare metrics
@@ -14,11 +15,14 @@ type Mindshare struct {
 
 // CalculateMindshare computes final mindshare score and category from existing and new mentions
 func CalculateMindshare(existing, new ticker.MentionDetails) (*Mindshare, error) {
-	// Merge mention details
-	mergedDetails := MergeMentionDetails(existing, new)
+	return CalculateMindshareAt(MergeMentionDetails(existing, new), time.Now())
+}
 
+// CalculateMindshareAt computes the mindshare score and category of mentions as of now,
+// decaying each mention by DecayHalfLife
+func CalculateMindshareAt(details ticker.MentionDetails, now time.Time) (*Mindshare, error) {
 	// Calculate score
-	score, err := CalculateScore(mergedDetails, TotalTierCounts)
+	score, err := CalculateDecayedScore(details, TotalTierCounts, now, DecayHalfLife)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate score: %w", err)
 	}
@@ -56,96 +60,81 @@ func DetermineCategory(score float64) (string, error) {
 		return "", fmt.Errorf("invalid score: %f (must be non-negative)", score)
 	}
 
-	switch {
-	case score > 600:
-		return "High Alpha", nil
-	case score > 250:
-		return "Alpha", nil
-	default:
-		return "Trenches", nil
+	categories := Scoring.Categories
+	for _, category := range categories[:len(categories)-1] {
+		if score > category.Above {
+			return category.Name, nil
+		}
 	}
+	return categories[len(categories)-1].Name, nil
 }
 
-const (
-	MaxScore    = 1000.0
-	Tier1Weight = 95.0 // Top influencers weight
-	Tier2Weight = 55.0 // Mid influencers weight
-	Tier3Weight = 15.0 // Regular influencers weight
-)
-
-// TotalTierCounts represents the total number of influencers per tier
-var TotalTierCounts = map[int]int{
-	1: 5,  // CZ, Sreeram, Balaji, cygaar, YQ
-	2: 15, // Andrew Kang, overdose, crash, Bull.BnB, etc.
-	3: 33, // All tier 3 influencers
+// CalculateScore computes the mindshare score of mentions without any time decay
+func CalculateScore(details ticker.MentionDetails, totalTierCounts map[int]int) (float64, error) {
+	return CalculateDecayedScore(details, totalTierCounts, time.Time{}, 0)
 }
 
-func CalculateScore(details ticker.MentionDetails, totalTierCounts map[int]int) (float64, error) {
+// CalculateDecayedScore computes the mindshare score of mentions as of now. The
+// tier weight of every influencer is scaled by their attention, see
+// influencerAttention, so it fades with a half-life of halfLife. A zero
+// halfLife disables decay.
+func CalculateDecayedScore(details ticker.MentionDetails, totalTierCounts map[int]int, now time.Time, halfLife time.Duration) (float64, error) {
 	if len(details.Influencers) == 0 {
 		return 0, fmt.Errorf("no influencer mentions found")
 	}
 
-	// Initialize counters for each tier
-	var (
-		tier1Count float64
-		tier2Count float64
-		tier3Count float64
-		rawScore   float64
-	)
+	config := Scoring
 
 	// Count mentions by tier and calculate raw score
+	tierCounts := make(map[int]int)
+	var rawScore float64
 	for _, mention := range details.Influencers {
-		switch mention.Tier {
-		case 1:
-			tier1Count++
-			rawScore += Tier1Weight
-		case 2:
-			tier2Count++
-			rawScore += Tier2Weight
-		case 3:
-			tier3Count++
-			rawScore += Tier3Weight
-		default:
+		scoring, ok := config.Tiers[mention.Tier]
+		if !ok {
 			return 0, fmt.Errorf("invalid tier found: %d", mention.Tier)
 		}
-	}
 
-	// Apply bonuses for tier 1 influencers
-	if tier1Count > 0 {
-		rawScore *= 5.5 // Base bonus for tier 1 presence
-	}
-	if tier1Count > 1 {
-		rawScore *= 1.3 // Additional bonus for multiple tier 1s
+		tierCounts[mention.Tier]++
+		rawScore += scoring.Weight * influencerAttention(mention, now, halfLife)
 	}
 
-	// Apply bonuses for tier 2 influencers
-	if tier2Count > 0 {
-		rawScore *= 3.9 // Base bonus for tier 1 presence
-	}
-	if tier2Count > 1 {
-		rawScore *= 1.6 // Additional bonus for multiple tier 2s
+	// Apply the presence and multiple-influencer bonuses of every tier
+	for tier, count := range tierCounts {
+		scoring := config.Tiers[tier]
+		rawScore *= scoring.PresenceBonus
+		if count >= scoring.MultipleMin {
+			rawScore *= scoring.MultipleBonus
+		}
 	}
 
-	// Apply bonuses for tier 3 influencers
-	if tier3Count > 2 {
-		rawScore *= 1.3 // Additional bonus for multiple tier 3s
+	// Normalize the score against the theoretical maximum based on the tier thresholds
+	theoreticalMaxRawScore := config.theoreticalMaxRawScore(totalTierCounts)
+	if theoreticalMaxRawScore <= 0 {
+		return 0, fmt.Errorf("no influencers in any scored tier")
 	}
+	normalizedScore := (rawScore / theoreticalMaxRawScore) * config.MaxScore
 
-	// Calculate the theoretical maximum raw score based on 25% thresholds
-	tier1Threshold := math.Ceil(0.25*float64(totalTierCounts[1])) * Tier1Weight // 25% of tier 1
-	tier2Threshold := math.Ceil(0.25*float64(totalTierCounts[2])) * Tier2Weight // 25% of tier 2
-	tier3Threshold := math.Ceil(0.25*float64(totalTierCounts[3])) * Tier3Weight // 25% of tier 3
-
-	// Calculate theoretical max score (with bonuses)
-	theoreticalMaxRawScore := (tier1Threshold + tier2Threshold + tier3Threshold) * 1.2 * 1.3
+	if config.ScoreCap > 0 && normalizedScore > config.ScoreCap {
+		normalizedScore = config.ScoreCap
+	}
 
-	// Normalize the score to MaxScore (1000)
-	normalizedScore := (rawScore / theoreticalMaxRawScore) * MaxScore
+	return normalizedScore, nil
+}
 
-	// Cap at MaxScore
-	// if normalizedScore > MaxScore {
-	// 	normalizedScore = MaxScore
-	// }
+// influencerAttention returns the share, between 0 and 1, of its tier weight an
+// influencer adds: the decayed weight of every one of their mentions, added up.
+// Each mention fades on its own and mentioning a ticker again keeps it in mind,
+// but the total is capped at 1. The model weighs distinct influencers, which
+// normalization and the multiple-influencer bonuses count: an influencer
+// posting a ticker ten times is still one influencer.
+func influencerAttention(mention ticker.MentionDetail, now time.Time, halfLife time.Duration) float64 {
+	if len(mention.MentionTimes) == 0 {
+		return DecayFactor(mention.MentionedAt, now, halfLife)
+	}
 
-	return normalizedScore, nil
+	var attention float64
+	for _, at := range mention.MentionTimes {
+		attention += DecayFactor(at, now, halfLife)
+	}
+	return math.Min(attention, 1)
 }
@@ -8,6 +8,9 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+// testTierCounts mirrors the tiers of influencers.yaml the expected scores were computed with
+var testTierCounts = map[int]int{1: 5, 2: 15, 3: 33}
+
 func TestMergeMentionDetails(t *testing.T) {
 	// Test input data
 	existingJSON := `{
@@ -261,7 +264,7 @@ func TestCalculateScore(t *testing.T) {
 			err := json.Unmarshal([]byte(tt.mentionsJSON), &mentions)
 			assert.NoError(t, err, "Failed to unmarshal test JSON")
 
-			score, err := CalculateScore(mentions, TotalTierCounts)
+			score, err := CalculateScore(mentions, testTierCounts)
 
 			if tt.shouldError {
 				assert.Error(t, err)
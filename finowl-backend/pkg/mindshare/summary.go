@@ -4,7 +4,28 @@ import "time"
 
 // Summary represents the structure of a summary to be stored in the database
 type Summary struct {
-	ID      int       `json:"id"`
-	Time    time.Time `json:"timestamp"`
-	Content string    `json:"content"`
+	ID       int       `json:"id"`
+	Time     time.Time `json:"timestamp"`
+	Content  string    `json:"content"`
+	Category string    `json:"category"`
+
+	// Window of tweets the summary was generated from. Summaries created before
+	// incremental windows existed have no bounds and an empty tweet range.
+	FirstTweetID string     `json:"first_tweet_id"`
+	LastTweetID  string     `json:"last_tweet_id"`
+	TweetCount   int        `json:"tweet_count"`
+	WindowStart  *time.Time `json:"window_start,omitempty"`
+	WindowEnd    *time.Time `json:"window_end,omitempty"`
+
+	// Sections is nil when the summary could not be parsed into sections
+	Sections *SummarySections `json:"sections,omitempty"`
+}
+
+// SummaryReference points to a summary featuring a ticker
+type SummaryReference struct {
+	SummaryID   int       `json:"summary_id"`
+	Time        time.Time `json:"timestamp"`
+	Category    string    `json:"category"`
+	ProjectName string    `json:"project_name"`
+	Description string    `json:"description"`
 }
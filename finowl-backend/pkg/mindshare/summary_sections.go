@@ -0,0 +1,149 @@
+package mindshare
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Section markers the summary prompt requires the model to emit. Each section
+// is wrapped in <!-- BEGIN name --> ... <!-- END name --> comments.
+const (
+	SectionFeaturedTickers = "FEATURED TICKERS AND PROJECTS"
+	SectionKeyInsights     = "KEY INSIGHTS FROM INFLUENCERS"
+	SectionMarketSentiment = "MARKET SENTIMENT AND DIRECTIONS"
+)
+
+// ErrMissingSections is returned when a summary lacks required section markers
+var ErrMissingSections = errors.New("summary is missing required sections")
+
+// SummarySections holds the structured content of an AI summary
+type SummarySections struct {
+	FeaturedTickers string          `json:"featured_tickers"`
+	KeyInsights     string          `json:"key_insights"`
+	MarketSentiment string          `json:"market_sentiment"`
+	Tickers         []SummaryTicker `json:"tickers"`
+}
+
+// SummaryTicker is a single ticker bullet of the featured tickers section
+type SummaryTicker struct {
+	TickerSymbol string `json:"ticker_symbol"`
+	ProjectName  string `json:"project_name"`
+	Description  string `json:"description"`
+}
+
+// MaxSummaryTickerLength is the longest ticker symbol a summary_tickers row holds
+const MaxSummaryTickerLength = 20
+
+// tickerBulletPattern matches "- **$SYMBOL (Project Name)**" optionally
+// followed by the description on the same line.
+var tickerBulletPattern = regexp.MustCompile(`^[-*•]\s*\*\*\$([A-Za-z0-9]+)\s*(?:\(([^)]*)\))?\s*\*\*\s*[:\-–—]?\s*(.*)$`)
+
+// ParseSummarySections validates the section markers of an AI summary and
+// extracts each section along with the featured ticker bullets.
+func ParseSummarySections(content string) (*SummarySections, error) {
+	var missing []string
+	section := func(name string) string {
+		body, ok := extractSection(content, name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return body
+	}
+
+	sections := &SummarySections{
+		FeaturedTickers: section(SectionFeaturedTickers),
+		KeyInsights:     section(SectionKeyInsights),
+		MarketSentiment: section(SectionMarketSentiment),
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrMissingSections, strings.Join(missing, ", "))
+	}
+
+	sections.Tickers = parseSummaryTickers(sections.FeaturedTickers)
+	return sections, nil
+}
+
+// sectionMarkerPattern matches a line holding a single BEGIN or END section marker
+var sectionMarkerPattern = regexp.MustCompile(`(?m)^[ \t]*<!-- (?:BEGIN|END) [A-Z ]+ -->[ \t]*\n?`)
+
+// StripSectionMarkers removes the section marker comments of a summary, for
+// displays that do not hide HTML comments
+func StripSectionMarkers(content string) string {
+	return strings.TrimSpace(sectionMarkerPattern.ReplaceAllString(content, ""))
+}
+
+// extractSection returns the body between the BEGIN and END markers of a
+// section with its "## " heading removed.
+func extractSection(content, name string) (string, bool) {
+	begin := "<!-- BEGIN " + name + " -->"
+	end := "<!-- END " + name + " -->"
+
+	start := strings.Index(content, begin)
+	if start == -1 {
+		return "", false
+	}
+	start += len(begin)
+
+	stop := strings.Index(content[start:], end)
+	if stop == -1 {
+		return "", false
+	}
+
+	body := strings.TrimSpace(content[start : start+stop])
+	if strings.HasPrefix(body, "## ") {
+		if idx := strings.Index(body, "\n"); idx != -1 {
+			body = strings.TrimSpace(body[idx+1:])
+		} else {
+			body = ""
+		}
+	}
+	return body, true
+}
+
+// parseSummaryTickers splits the featured tickers section into one entry per
+// "**$SYMBOL (Project)**" bullet. Lines following a bullet become its description.
+// Bullets of symbols longer than MaxSummaryTickerLength are skipped along with
+// their description, they could not be stored.
+func parseSummaryTickers(section string) []SummaryTicker {
+	tickers := []SummaryTicker{}
+	var description []string
+	skipping := false
+
+	flush := func() {
+		if len(tickers) > 0 && !skipping {
+			tickers[len(tickers)-1].Description = strings.Join(description, " ")
+		}
+		description = nil
+	}
+
+	for _, line := range strings.Split(section, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if match := tickerBulletPattern.FindStringSubmatch(line); match != nil {
+			flush()
+			if skipping = len(match[1]) > MaxSummaryTickerLength; skipping {
+				continue
+			}
+			tickers = append(tickers, SummaryTicker{
+				TickerSymbol: strings.ToUpper(match[1]),
+				ProjectName:  strings.TrimSpace(match[2]),
+			})
+			if rest := strings.TrimSpace(match[3]); rest != "" {
+				description = append(description, rest)
+			}
+			continue
+		}
+
+		if len(tickers) > 0 && !skipping {
+			description = append(description, strings.TrimSpace(strings.TrimLeft(line, "-*• ")))
+		}
+	}
+	flush()
+
+	return tickers
+}
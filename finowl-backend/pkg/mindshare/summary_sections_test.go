@@ -0,0 +1,101 @@
+package mindshare
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleSummary = `<!-- BEGIN FEATURED TICKERS AND PROJECTS -->
+## Featured Tickers and Projects
+- **$AIXBT (aixbt)**
+  - AI agent token trending after a tier-1 shoutout.
+  - Volume up 40% in 24h.
+- **$HASHAI (Hashai)**: DePIN play with $1M+ rewards paid out.
+<!-- END FEATURED TICKERS AND PROJECTS -->
+
+<!-- BEGIN KEY INSIGHTS FROM INFLUENCERS -->
+## Key Insights from Influencers
+- **moneyl0rd**
+  High conviction on $HASHAI.
+<!-- END KEY INSIGHTS FROM INFLUENCERS -->
+
+<!-- BEGIN MARKET SENTIMENT AND DIRECTIONS -->
+## Market Sentiment and Directions
+- Bullish on AI agents
+<!-- END MARKET SENTIMENT AND DIRECTIONS -->`
+
+func TestParseSummarySections(t *testing.T) {
+	sections, err := ParseSummarySections(sampleSummary)
+	require.NoError(t, err)
+
+	assert.Equal(t, "- **moneyl0rd**\n  High conviction on $HASHAI.", sections.KeyInsights)
+	assert.Equal(t, "- Bullish on AI agents", sections.MarketSentiment)
+	assert.Contains(t, sections.FeaturedTickers, "**$AIXBT (aixbt)**")
+	assert.NotContains(t, sections.FeaturedTickers, "## Featured Tickers")
+
+	assert.Equal(t, []SummaryTicker{
+		{
+			TickerSymbol: "AIXBT",
+			ProjectName:  "aixbt",
+			Description:  "AI agent token trending after a tier-1 shoutout. Volume up 40% in 24h.",
+		},
+		{
+			TickerSymbol: "HASHAI",
+			ProjectName:  "Hashai",
+			Description:  "DePIN play with $1M+ rewards paid out.",
+		},
+	}, sections.Tickers)
+}
+
+func TestParseSummaryTickersSkipsOversizedSymbols(t *testing.T) {
+	section := `- **$AIXBT (aixbt)**: AI agent token.
+- **$` + strings.Repeat("X", MaxSummaryTickerLength+1) + ` (Spam)**
+  - Not a ticker anyone trades.
+- **$` + strings.Repeat("Y", MaxSummaryTickerLength) + `**: Longest storable symbol.`
+
+	assert.Equal(t, []SummaryTicker{
+		{TickerSymbol: "AIXBT", ProjectName: "aixbt", Description: "AI agent token."},
+		{TickerSymbol: strings.Repeat("Y", MaxSummaryTickerLength), Description: "Longest storable symbol."},
+	}, parseSummaryTickers(section))
+}
+
+func TestParseSummarySectionsMissingMarkers(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		missing string
+	}{
+		{
+			name:    "no markers at all",
+			content: "## Featured Tickers\n- $ABC is pumping",
+			missing: SectionFeaturedTickers,
+		},
+		{
+			name: "unterminated section",
+			content: "<!-- BEGIN FEATURED TICKERS AND PROJECTS -->\n" +
+				"<!-- BEGIN KEY INSIGHTS FROM INFLUENCERS --><!-- END KEY INSIGHTS FROM INFLUENCERS -->\n" +
+				"<!-- BEGIN MARKET SENTIMENT AND DIRECTIONS --><!-- END MARKET SENTIMENT AND DIRECTIONS -->",
+			missing: SectionFeaturedTickers,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sections, err := ParseSummarySections(tt.content)
+			assert.ErrorIs(t, err, ErrMissingSections)
+			assert.ErrorContains(t, err, tt.missing)
+			assert.Nil(t, sections)
+		})
+	}
+}
+
+func TestStripSectionMarkers(t *testing.T) {
+	stripped := StripSectionMarkers(sampleSummary)
+
+	assert.NotContains(t, stripped, "<!--")
+	assert.True(t, strings.HasPrefix(stripped, "## Featured Tickers and Projects\n- **$AIXBT (aixbt)**"))
+	assert.Contains(t, stripped, "rewards paid out.\n\n## Key Insights from Influencers")
+}
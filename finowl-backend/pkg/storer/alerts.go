@@ -0,0 +1,169 @@
+package storer
+
+import (
+	"database/sql"
+	"errors"
+	"finowl-backend/pkg/alerts"
+	"finowl-backend/pkg/mindshare"
+	"finowl-backend/pkg/ticker"
+	"fmt"
+	"time"
+)
+
+// AlertEvaluator checks the alert rules against every ticker change made by the storer
+type AlertEvaluator interface {
+	Evaluate(c alerts.Change)
+}
+
+// SetAlertEvaluator makes the storer evaluate the alert rules whenever a ticker is created or rescored from new mentions
+func (s *Storer) SetAlertEvaluator(evaluator AlertEvaluator) {
+	s.alerts = evaluator
+}
+
+// evaluateAlerts forwards a change to the alert evaluator, if any
+func (s *Storer) evaluateAlerts(c alerts.Change) {
+	if s.alerts != nil {
+		s.alerts.Evaluate(c)
+	}
+}
+
+// GetEnabledAlertRules returns every enabled alert rule
+func (s *Storer) GetEnabledAlertRules() ([]alerts.Rule, error) {
+	rows, err := s.db.Query(`
+		SELECT id, name, rule_type, ticker, category, threshold, tier, window_seconds,
+		       cooldown_seconds, webhook_url, secret, enabled, created_at, updated_at
+		FROM alert_rules
+		WHERE enabled
+		ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve alert rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []alerts.Rule
+	for rows.Next() {
+		var r alerts.Rule
+		if err := rows.Scan(&r.ID, &r.Name, &r.Type, &r.Ticker, &r.Category, &r.Threshold, &r.Tier, &r.WindowSeconds,
+			&r.CooldownSeconds, &r.WebhookURL, &r.Secret, &r.Enabled, &r.CreatedAt, &r.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan alert rule: %w", err)
+		}
+		rules = append(rules, r)
+	}
+
+	return rules, rows.Err()
+}
+
+// ScoreAt recomputes the mindshare score a ticker had at the given time from
+// the mentions recorded up to then. A ticker without mentions scores 0.
+func (s *Storer) ScoreAt(symbol string, at time.Time) (float64, error) {
+	rows, err := s.db.Query(`
+		SELECT author, tier, mentioned_at
+		FROM ticker_mentions
+		WHERE ticker = $1 AND mentioned_at <= $2
+		ORDER BY author, mentioned_at DESC`, symbol, at)
+	if err != nil {
+		return 0, fmt.Errorf("failed to retrieve mentions: %w", err)
+	}
+	defer rows.Close()
+
+	details := ticker.MentionDetails{Influencers: make(map[string]ticker.MentionDetail)}
+	for rows.Next() {
+		var author string
+		var detail ticker.MentionDetail
+		if err := rows.Scan(&author, &detail.Tier, &detail.MentionedAt); err != nil {
+			return 0, fmt.Errorf("failed to scan mention: %w", err)
+		}
+		details.AddMention(author, detail)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if len(details.Influencers) == 0 {
+		return 0, nil
+	}
+
+	mindShare, err := mindshare.CalculateMindshareAt(details, at)
+	if err != nil {
+		return 0, fmt.Errorf("failed to score %s at %s: %w", symbol, at.Format(time.RFC3339), err)
+	}
+	return mindShare.Score, nil
+}
+
+// alertDeliveryLockClass keys the transaction locks serializing the alerts
+// of a rule for a ticker, apart from the other advisory locks
+const alertDeliveryLockClass int32 = 1
+
+// InsertAlertDelivery records a fired alert in the delivery log and sets its
+// ID, unless the rule fired for the ticker within cooldown before it. The
+// check and the insert hold a lock on the rule and ticker, so concurrent
+// alerts cannot both pass the cooldown.
+func (s *Storer) InsertAlertDelivery(d *alerts.Delivery, cooldown time.Duration) (bool, error) {
+	inserted := false
+	err := s.inTx(func(tx *sql.Tx, _ *txEffects) error {
+		if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1, hashtext($2::text || ':' || $3))`,
+			alertDeliveryLockClass, d.RuleID, d.Ticker); err != nil {
+			return fmt.Errorf("failed to lock alert rule %d for %s: %w", d.RuleID, d.Ticker, err)
+		}
+
+		err := tx.QueryRow(`
+			INSERT INTO alert_deliveries (rule_id, ticker, payload, status, attempts, created_at)
+			SELECT $1::BIGINT, $2::VARCHAR, $3::JSONB, $4::VARCHAR, $5::INTEGER, $6::TIMESTAMP
+			WHERE NOT EXISTS (
+				SELECT 1 FROM alert_deliveries
+				WHERE rule_id = $1 AND ticker = $2 AND created_at > $7
+			)
+			RETURNING id`,
+			d.RuleID, d.Ticker, []byte(d.Payload), d.Status, d.Attempts, d.CreatedAt, d.CreatedAt.Add(-cooldown),
+		).Scan(&d.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to insert alert delivery: %w", err)
+		}
+		inserted = true
+		return nil
+	})
+	return inserted, err
+}
+
+// PendingAlertDeliveries returns the deliveries still waiting to be sent, oldest first
+func (s *Storer) PendingAlertDeliveries() ([]alerts.Delivery, error) {
+	rows, err := s.db.Query(`
+		SELECT id, rule_id, ticker, payload, status, attempts, response_status, last_error, created_at
+		FROM alert_deliveries
+		WHERE status = $1
+		ORDER BY id`, alerts.DeliveryPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve pending alert deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []alerts.Delivery
+	for rows.Next() {
+		var d alerts.Delivery
+		var payload []byte
+		if err := rows.Scan(&d.ID, &d.RuleID, &d.Ticker, &payload, &d.Status, &d.Attempts, &d.ResponseStatus,
+			&d.LastError, &d.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan alert delivery: %w", err)
+		}
+		d.Payload = payload
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// UpdateAlertDelivery records the outcome of the latest attempt of a delivery
+func (s *Storer) UpdateAlertDelivery(d *alerts.Delivery) error {
+	_, err := s.db.Exec(`
+		UPDATE alert_deliveries
+		SET status = $1, attempts = $2, response_status = $3, last_error = $4, delivered_at = $5
+		WHERE id = $6`,
+		d.Status, d.Attempts, d.ResponseStatus, d.LastError, d.DeliveredAt, d.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update alert delivery %d: %w", d.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package storer
+
+import (
+	"testing"
+	"time"
+
+	"finowl-backend/pkg/alerts"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingEvaluator keeps every change the storer asks to evaluate
+type recordingEvaluator struct {
+	changes []alerts.Change
+}
+
+func (e *recordingEvaluator) Evaluate(c alerts.Change) {
+	e.changes = append(e.changes, c)
+}
+
+func TestScoreAt(t *testing.T) {
+	s, mock := newMockStorer(t)
+	defer s.db.Close()
+
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT author, tier, mentioned_at FROM ticker_mentions").
+		WithArgs("AIXBT", at).
+		WillReturnRows(sqlmock.NewRows([]string{"author", "tier", "mentioned_at"}).
+			AddRow("whale", 1, at).
+			AddRow("degen", 3, at.Add(-time.Hour)))
+
+	score, err := s.ScoreAt("AIXBT", at)
+	require.NoError(t, err)
+	assert.Greater(t, score, 0.0)
+
+	// No mention yet at that time
+	mock.ExpectQuery("SELECT author, tier, mentioned_at FROM ticker_mentions").
+		WithArgs("AIXBT", at.Add(-48*time.Hour)).
+		WillReturnRows(sqlmock.NewRows([]string{"author", "tier", "mentioned_at"}))
+
+	score, err = s.ScoreAt("AIXBT", at.Add(-48*time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, score)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertAlertDelivery(t *testing.T) {
+	s, mock := newMockStorer(t)
+	defer s.db.Close()
+
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	d := &alerts.Delivery{RuleID: 3, Ticker: "AIXBT", Payload: []byte(`{}`), Status: alerts.DeliveryPending, CreatedAt: at}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT pg_advisory_xact_lock").
+		WithArgs(alertDeliveryLockClass, int64(3), "AIXBT").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("INSERT INTO alert_deliveries .* WHERE NOT EXISTS").
+		WithArgs(int64(3), "AIXBT", []byte(`{}`), alerts.DeliveryPending, 0, at, at.Add(-time.Hour)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12))
+	mock.ExpectCommit()
+
+	inserted, err := s.InsertAlertDelivery(d, time.Hour)
+	require.NoError(t, err)
+	assert.True(t, inserted)
+	assert.Equal(t, int64(12), d.ID)
+
+	// The rule fired for the ticker within the cooldown
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT pg_advisory_xact_lock").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("INSERT INTO alert_deliveries").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectCommit()
+
+	inserted, err = s.InsertAlertDelivery(&alerts.Delivery{RuleID: 3, Ticker: "AIXBT", CreatedAt: at}, time.Hour)
+	require.NoError(t, err)
+	assert.False(t, inserted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPendingAlertDeliveries(t *testing.T) {
+	s, mock := newMockStorer(t)
+	defer s.db.Close()
+
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT id, rule_id, ticker, payload, status, attempts, response_status, last_error, created_at FROM alert_deliveries").
+		WithArgs(alerts.DeliveryPending).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "rule_id", "ticker", "payload", "status", "attempts", "response_status", "last_error", "created_at"}).
+			AddRow(4, 3, "AIXBT", []byte(`{"ticker_symbol":"AIXBT"}`), "pending", 2, 502, "webhook responded 502 Bad Gateway", at))
+
+	pending, err := s.PendingAlertDeliveries()
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, int64(3), pending[0].RuleID)
+	assert.Equal(t, 2, pending[0].Attempts)
+	assert.JSONEq(t, `{"ticker_symbol":"AIXBT"}`, string(pending[0].Payload))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
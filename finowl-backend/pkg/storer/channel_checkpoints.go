@@ -0,0 +1,38 @@
+package storer
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// GetChannelCheckpoint returns the ID of the last message processed in a
+// channel, or an empty string when none was
+func (s *Storer) GetChannelCheckpoint(channelID string) (string, error) {
+	var messageID string
+	err := s.db.QueryRow(`SELECT last_message_id FROM channel_checkpoints WHERE channel_id = $1`, channelID).Scan(&messageID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve checkpoint of channel %s: %w", channelID, err)
+	}
+	return messageID, nil
+}
+
+// SaveChannelCheckpoint records a processed message of a channel. Callers
+// process the messages of a channel in order, a checkpoint past a message
+// still being processed would lose it on a restart. Message IDs are snowflakes
+// growing with time, so a message seen again never moves it backwards.
+func (s *Storer) SaveChannelCheckpoint(channelID, messageID string) error {
+	_, err := s.db.Exec(`
+		INSERT INTO channel_checkpoints (channel_id, last_message_id, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (channel_id) DO UPDATE
+		SET last_message_id = EXCLUDED.last_message_id, updated_at = EXCLUDED.updated_at
+		WHERE channel_checkpoints.last_message_id < EXCLUDED.last_message_id`,
+		channelID, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint of channel %s: %w", channelID, err)
+	}
+	return nil
+}
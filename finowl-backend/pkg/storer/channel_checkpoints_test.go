@@ -0,0 +1,67 @@
+package storer
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannelCheckpoint(t *testing.T) {
+	s, mock := newMockStorer(t)
+	defer s.db.Close()
+
+	mock.ExpectQuery("SELECT last_message_id FROM channel_checkpoints").
+		WithArgs("channel").
+		WillReturnRows(sqlmock.NewRows([]string{"last_message_id"}))
+
+	checkpoint, err := s.GetChannelCheckpoint("channel")
+	require.NoError(t, err)
+	assert.Empty(t, checkpoint)
+
+	// Saving an older message leaves the checkpoint untouched, which is not an error
+	mock.ExpectExec("INSERT INTO channel_checkpoints").
+		WithArgs("channel", "1345678901234567890").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	require.NoError(t, s.SaveChannelCheckpoint("channel", "1345678901234567890"))
+
+	mock.ExpectQuery("SELECT last_message_id FROM channel_checkpoints").
+		WithArgs("channel").
+		WillReturnRows(sqlmock.NewRows([]string{"last_message_id"}).AddRow(int64(1345678901234567891)))
+
+	checkpoint, err = s.GetChannelCheckpoint("channel")
+	require.NoError(t, err)
+	assert.Equal(t, "1345678901234567891", checkpoint)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertTweetSkipsStoredMessages(t *testing.T) {
+	s, mock := newMockStorer(t)
+	defer s.db.Close()
+
+	tweet := Tweet{ID: "tweet-1", Author: "whale", Timestamp: "2025-03-01T12:00:00Z", Content: "$AIXBT", MessageID: "1234"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO tweets .* ON CONFLICT DO NOTHING").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO tweet_messages").
+		WithArgs("1234", "tweet-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	require.NoError(t, s.InsertTweet(tweet))
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO tweets").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT EXISTS \\(SELECT 1 FROM tweet_messages WHERE discord_message_id").
+		WithArgs("1234").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+	err := s.InsertTweet(tweet)
+	assert.ErrorIs(t, err, ErrTweetExists)
+	assert.NotErrorIs(t, err, ErrDuplicateTweet)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,126 @@
+package storer
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"finowl-backend/pkg/deadletter"
+	"finowl-backend/pkg/ticker"
+	"fmt"
+	"time"
+)
+
+// DeadTweet is the payload of the dead letter of a tweet that could not be
+// stored: the tweet and the tickers extracted from it
+type DeadTweet struct {
+	Tweet   Tweet           `json:"tweet"`
+	Tickers []ticker.Ticker `json:"tickers"`
+}
+
+// InsertDeadLetter records a dead letter and sets its ID
+func (s *Storer) InsertDeadLetter(e *deadletter.Entry) error {
+	err := s.db.QueryRow(`
+		INSERT INTO dead_letters (source, message_id, payload, status, attempts, last_error, created_at, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id`,
+		e.Source, e.MessageID, []byte(e.Payload), e.Status, e.Attempts, e.LastError, e.CreatedAt, e.NextAttemptAt,
+	).Scan(&e.ID)
+	if err != nil {
+		return fmt.Errorf("failed to insert dead letter: %w", err)
+	}
+	return nil
+}
+
+// UpdateDeadLetter records the outcome of the latest attempt of a dead letter
+func (s *Storer) UpdateDeadLetter(e *deadletter.Entry) error {
+	_, err := s.db.Exec(`
+		UPDATE dead_letters
+		SET status = $1, attempts = $2, last_error = $3, next_attempt_at = $4, resolved_at = $5
+		WHERE id = $6`,
+		e.Status, e.Attempts, e.LastError, e.NextAttemptAt, e.ResolvedAt, e.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update dead letter %d: %w", e.ID, err)
+	}
+	return nil
+}
+
+// GetDeadLetter returns a dead letter by ID
+func (s *Storer) GetDeadLetter(id int64) (*deadletter.Entry, error) {
+	e, err := scanDeadLetter(s.db.QueryRow(`SELECT `+deadLetterColumns+` FROM dead_letters WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %d", deadletter.ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve dead letter %d: %w", id, err)
+	}
+	return &e, nil
+}
+
+// ListDeadLetters returns the latest dead letters, only those of status unless empty
+func (s *Storer) ListDeadLetters(status deadletter.Status, limit int) ([]deadletter.Entry, error) {
+	return s.queryDeadLetters(`
+		SELECT `+deadLetterColumns+` FROM dead_letters
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY id DESC
+		LIMIT $2`, status, limit)
+}
+
+// DueDeadLetters returns the pending dead letters whose next retry is due, oldest first
+func (s *Storer) DueDeadLetters(now time.Time, limit int) ([]deadletter.Entry, error) {
+	return s.queryDeadLetters(`
+		SELECT `+deadLetterColumns+` FROM dead_letters
+		WHERE status = $1 AND next_attempt_at <= $2
+		ORDER BY next_attempt_at
+		LIMIT $3`, deadletter.StatusPending, now, limit)
+}
+
+// ReplayDeadLetter stores the tweet of a dead letter along with its mentions.
+// A tweet stored meanwhile, e.g. by a backfill, needs no replay.
+func (s *Storer) ReplayDeadLetter(e deadletter.Entry) error {
+	var dead DeadTweet
+	if err := json.Unmarshal(e.Payload, &dead); err != nil {
+		return fmt.Errorf("failed to decode dead letter %d: %w", e.ID, err)
+	}
+
+	if err := s.StoreTweet(dead.Tweet, dead.Tickers); err != nil && !errors.Is(err, ErrTweetExists) {
+		return err
+	}
+	return nil
+}
+
+// deadLetterColumns are the columns read by scanDeadLetter
+const deadLetterColumns = `id, source, message_id, payload, status, attempts, last_error, created_at, next_attempt_at, resolved_at`
+
+// queryDeadLetters runs a query returning dead letter rows
+func (s *Storer) queryDeadLetters(query string, args ...any) ([]deadletter.Entry, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []deadletter.Entry{}
+	for rows.Next() {
+		e, err := scanDeadLetter(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan dead letter: %w", err)
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// scanDeadLetter reads a row of the deadLetterColumns
+func scanDeadLetter(row interface{ Scan(...any) error }) (deadletter.Entry, error) {
+	var e deadletter.Entry
+	var payload []byte
+	var resolvedAt sql.NullTime
+	err := row.Scan(&e.ID, &e.Source, &e.MessageID, &payload, &e.Status, &e.Attempts, &e.LastError,
+		&e.CreatedAt, &e.NextAttemptAt, &resolvedAt)
+	e.Payload = payload
+	if resolvedAt.Valid {
+		e.ResolvedAt = &resolvedAt.Time
+	}
+	return e, err
+}
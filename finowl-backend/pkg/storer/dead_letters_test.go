@@ -0,0 +1,109 @@
+package storer
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"finowl-backend/pkg/deadletter"
+	"finowl-backend/pkg/ticker"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var deadLetterRowColumns = []string{"id", "source", "message_id", "payload", "status", "attempts", "last_error",
+	"created_at", "next_attempt_at", "resolved_at"}
+
+func TestInsertDeadLetter(t *testing.T) {
+	s, mock := newMockStorer(t)
+	defer s.db.Close()
+
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	e := &deadletter.Entry{
+		Source:        SourceDiscord,
+		MessageID:     "1234",
+		Payload:       json.RawMessage(`{"tweet": {}}`),
+		Status:        deadletter.StatusPending,
+		Attempts:      1,
+		LastError:     "connection refused",
+		CreatedAt:     at,
+		NextAttemptAt: at.Add(time.Minute),
+	}
+
+	mock.ExpectQuery("INSERT INTO dead_letters").
+		WithArgs("discord", "1234", []byte(`{"tweet": {}}`), deadletter.StatusPending, 1, "connection refused", at, at.Add(time.Minute)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+
+	require.NoError(t, s.InsertDeadLetter(e))
+	assert.Equal(t, int64(7), e.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDeadLetter(t *testing.T) {
+	s, mock := newMockStorer(t)
+	defer s.db.Close()
+
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT id, source, message_id, payload, status, attempts, last_error, created_at, next_attempt_at, resolved_at FROM dead_letters WHERE id").
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows(deadLetterRowColumns).
+			AddRow(7, "discord", "1234", []byte(`{}`), "resolved", 2, "", at, at, at.Add(time.Minute)))
+
+	e, err := s.GetDeadLetter(7)
+	require.NoError(t, err)
+	assert.Equal(t, deadletter.StatusResolved, e.Status)
+	require.NotNil(t, e.ResolvedAt)
+	assert.Equal(t, at.Add(time.Minute), *e.ResolvedAt)
+
+	mock.ExpectQuery("FROM dead_letters WHERE id").
+		WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows(deadLetterRowColumns))
+	_, err = s.GetDeadLetter(8)
+	assert.ErrorIs(t, err, deadletter.ErrNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReplayDeadLetter(t *testing.T) {
+	s, mock := newMockStorer(t)
+	defer s.db.Close()
+
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tweetID := "e33fbc74-74e8-447b-9c0b-d02771ff7495"
+	payload, err := json.Marshal(DeadTweet{
+		Tweet:   sampleTweet(tweetID),
+		Tickers: []ticker.Ticker{sampleMentionTicker("Stats", tweetID, at)},
+	})
+	require.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO tweets").
+		WithArgs(tweetID, "Stats", "2025-01-01T12:00:00Z", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+			"", "msg-"+tweetID, "", "discord", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO tweet_messages").
+		WithArgs("msg-"+tweetID, tweetID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO Tickers_1_0").
+		WithArgs("AIXBT", "Trenches", 17.6, at, at).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO ticker_mentions").
+		WithArgs("AIXBT", tweetID, "Stats", 3, at, "", "", "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, s.ReplayDeadLetter(deadletter.Entry{ID: 7, Payload: payload}))
+
+	// Stored meanwhile, e.g. by a backfill
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO tweets").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT EXISTS").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	require.NoError(t, s.ReplayDeadLetter(deadletter.Entry{ID: 7, Payload: payload}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	assert.ErrorContains(t, s.ReplayDeadLetter(deadletter.Entry{ID: 8, Payload: json.RawMessage(`[]`)}), "failed to decode dead letter 8")
+}
@@ -0,0 +1,37 @@
+package storer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"finowl-backend/pkg/analyzer"
+)
+
+// dedupKey identifies the tweet a message relays, so that a tweet relayed by
+// several messages is stored once. Tweets linking to their status are keyed
+// by its ID, whatever domain or relay shows it. Others are keyed by a hash of
+// their author and normalized content on the day they were posted, which
+// tells the same words posted again on a later day apart.
+func dedupKey(tweet Tweet) string {
+	for _, link := range tweet.Links {
+		if id := analyzer.StatusIDFromURL(link); id != "" {
+			return "status:" + id
+		}
+	}
+
+	day := tweet.Timestamp
+	if t, err := time.Parse(time.RFC3339, tweet.Timestamp); err == nil {
+		day = t.UTC().Format(time.DateOnly)
+	}
+
+	sum := sha256.Sum256([]byte(normalizeText(tweet.Author) + "\x00" + normalizeText(tweet.Content) + "\x00" + day))
+	return "content:" + hex.EncodeToString(sum[:])
+}
+
+// normalizeText lowercases text and collapses its whitespace, relays differ
+// in both
+func normalizeText(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
@@ -0,0 +1,88 @@
+package storer
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDedupKey(t *testing.T) {
+	relayed := Tweet{
+		Author:    "aixbt",
+		Timestamp: "2025-03-01T12:00:00Z",
+		Content:   "$AIXBT mindshare keeps climbing",
+		Links:     []string{"https://x.com/aixbt_agent/status/1879491000000000000"},
+	}
+	assert.Equal(t, "status:1879491000000000000", dedupKey(relayed))
+
+	// Another relay linking the tweet on another domain
+	other := relayed
+	other.Author = "aixbt (@aixbt_agent)"
+	other.Links = []string{"https://fxtwitter.com/aixbt_agent/status/1879491000000000000"}
+	assert.Equal(t, dedupKey(relayed), dedupKey(other))
+
+	// Without a status, the author and content identify the tweet
+	plain := relayed
+	plain.Links = nil
+	key := dedupKey(plain)
+	assert.Regexp(t, "^content:[0-9a-f]{64}$", key)
+
+	copied := plain
+	copied.Author = "AIXBT"
+	copied.Content = " $aixbt  mindshare\nkeeps climbing "
+	copied.Timestamp = "2025-03-01T18:30:00+01:00"
+	assert.Equal(t, key, dedupKey(copied), "case, whitespace and the time of day do not matter")
+
+	for name, modify := range map[string]func(*Tweet){
+		"author":  func(t *Tweet) { t.Author = "degen" },
+		"content": func(t *Tweet) { t.Content = "$AIXBT mindshare keeps falling" },
+		"day":     func(t *Tweet) { t.Timestamp = "2025-03-02T12:00:00Z" },
+	} {
+		changed := plain
+		modify(&changed)
+		assert.NotEqual(t, key, dedupKey(changed), name)
+	}
+}
+
+func TestInsertTweetSkipsDuplicates(t *testing.T) {
+	s, mock := newMockStorer(t)
+	defer s.db.Close()
+
+	tweet := Tweet{
+		ID: "tweet-2", Author: "aixbt", Timestamp: "2025-03-01T12:00:00Z", Content: "$AIXBT mindshare keeps climbing",
+		Links: []string{"https://x.com/aixbt_agent/status/1879491000000000000"}, MessageID: "5678",
+	}
+
+	// Another message relayed the tweet before, this one is recorded as a
+	// relay of the stored tweet
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO tweets").
+		WithArgs("tweet-2", "aixbt", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "",
+			"5678", "", SourceDiscord, "status:1879491000000000000").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs("5678").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec("INSERT INTO tweet_messages .* WHERE dedup_key").
+		WithArgs("5678", "status:1879491000000000000").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	assert.ErrorIs(t, s.InsertTweet(tweet), ErrDuplicateTweet)
+
+	// Tweets of other sources have no message to look for
+	tweet.MessageID = ""
+	tweet.Source = "telegram"
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO tweets").
+		WithArgs("tweet-2", "aixbt", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "",
+			nil, "", "telegram", "status:1879491000000000000").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	err := s.InsertTweet(tweet)
+	assert.ErrorIs(t, err, ErrDuplicateTweet)
+	assert.ErrorIs(t, err, ErrTweetExists)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,77 @@
+package storer
+
+import (
+	"finowl-backend/pkg/mindshare"
+	"fmt"
+	"time"
+)
+
+// Kinds of messages posted to Discord by the bot
+const (
+	DiscordPostSummary  = "summary"
+	DiscordPostCategory = "category" // A ticker entering the top mindshare category
+)
+
+// ClaimDiscordPost records that a message is about to be posted and reports
+// whether the caller should post it. A message posted before is only claimed
+// again when its previous post is older than repostBefore; the zero time never
+// allows a repost. Claims are atomic, so concurrent posters never both win.
+func (s *Storer) ClaimDiscordPost(kind, ref string, postedAt, repostBefore time.Time) (bool, error) {
+	result, err := s.db.Exec(`
+		INSERT INTO discord_posts (kind, ref, posted_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (kind, ref) DO UPDATE SET posted_at = EXCLUDED.posted_at
+		WHERE discord_posts.posted_at < $4`,
+		kind, ref, postedAt, repostBefore)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim discord post %s/%s: %w", kind, ref, err)
+	}
+
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return claimed == 1, nil
+}
+
+// ReleaseDiscordPost forgets a claimed message that could not be posted, so it is tried again
+func (s *Storer) ReleaseDiscordPost(kind, ref string) error {
+	if _, err := s.db.Exec(`DELETE FROM discord_posts WHERE kind = $1 AND ref = $2`, kind, ref); err != nil {
+		return fmt.Errorf("failed to release discord post %s/%s: %w", kind, ref, err)
+	}
+	return nil
+}
+
+// GetUnpostedSummaries returns the summaries created since the given time that
+// were not posted to Discord yet, oldest first
+func (s *Storer) GetUnpostedSummaries(since time.Time) ([]mindshare.Summary, error) {
+	rows, err := s.db.Query(`
+		SELECT id, timestamp, content, category,
+		       COALESCE(first_tweet_id::text, ''), COALESCE(last_tweet_id::text, ''),
+		       tweet_count, window_start, window_end
+		FROM Summaries s
+		WHERE s.timestamp >= $1
+		  AND NOT EXISTS (
+		      SELECT 1 FROM discord_posts p
+		      WHERE p.kind = $2 AND p.ref = s.id::text)
+		ORDER BY s.timestamp, s.id`, since, DiscordPostSummary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve unposted summaries: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []mindshare.Summary
+	for rows.Next() {
+		var summary mindshare.Summary
+		if err := rows.Scan(
+			&summary.ID, &summary.Time, &summary.Content, &summary.Category,
+			&summary.FirstTweetID, &summary.LastTweetID,
+			&summary.TweetCount, &summary.WindowStart, &summary.WindowEnd,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan summary: %w", err)
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, rows.Err()
+}
@@ -0,0 +1,57 @@
+package storer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimDiscordPost(t *testing.T) {
+	s, mock := newMockStorer(t)
+	defer s.db.Close()
+
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	repostBefore := now.Add(-24 * time.Hour)
+
+	mock.ExpectExec("INSERT INTO discord_posts").
+		WithArgs(DiscordPostCategory, "AIXBT", now, repostBefore).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	claimed, err := s.ClaimDiscordPost(DiscordPostCategory, "AIXBT", now, repostBefore)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	// Posted recently: the conflicting row is left untouched
+	mock.ExpectExec("INSERT INTO discord_posts").
+		WithArgs(DiscordPostCategory, "AIXBT", now, repostBefore).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	claimed, err = s.ClaimDiscordPost(DiscordPostCategory, "AIXBT", now, repostBefore)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetUnpostedSummaries(t *testing.T) {
+	s, mock := newMockStorer(t)
+	defer s.db.Close()
+
+	since := time.Date(2025, 3, 1, 6, 0, 0, 0, time.UTC)
+	at := since.Add(time.Hour)
+
+	mock.ExpectQuery("SELECT id, timestamp, content, category").
+		WithArgs(since, DiscordPostSummary).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "timestamp", "content", "category", "first_tweet_id", "last_tweet_id", "tweet_count", "window_start", "window_end"}).
+			AddRow(4, at, "content", "MacroNews", "10", "12", 3, at.Add(-time.Hour), at))
+
+	summaries, err := s.GetUnpostedSummaries(since)
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+	assert.Equal(t, 4, summaries[0].ID)
+	assert.Equal(t, 3, summaries[0].TweetCount)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,97 @@
+package storer
+
+import (
+	"finowl-backend/pkg/ticker"
+	"fmt"
+)
+
+// insertMentions records every influencer mention carried by a ticker and
+// returns the ones that were new. A tweet mentioning the same ticker twice is
+// only recorded once.
+func insertMentions(q querier, t ticker.Ticker) ([]ticker.Mention, error) {
+	var inserted []ticker.Mention
+	for author, mention := range t.MentionDetails.Influencers {
+		mentionedAt := mention.MentionedAt
+		if mentionedAt.IsZero() {
+			mentionedAt = t.LastMentionedAt
+		}
+
+		result, err := q.Exec(buildInsertMentionQuery(),
+			t.TickerSymbol,
+			nullIfEmpty(mention.TweetID),
+			author,
+			mention.Tier,
+			mentionedAt,
+			mention.TweetLink,
+			mention.Content,
+			mention.Category,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert mention of %s by %s: %w", t.TickerSymbol, author, err)
+		}
+
+		if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+			continue
+		}
+		inserted = append(inserted, ticker.Mention{
+			Author:      author,
+			Tier:        mention.Tier,
+			TweetLink:   mention.TweetLink,
+			Content:     mention.Content,
+			Category:    mention.Category,
+			TweetID:     mention.TweetID,
+			MentionedAt: mentionedAt,
+		})
+	}
+	return inserted, nil
+}
+
+// getMentionDetails builds the per-influencer view of a ticker's mentions: the
+// latest mention of every influencer along with their mention count.
+func getMentionDetails(q querier, symbol string) (ticker.MentionDetails, error) {
+	details := ticker.MentionDetails{Influencers: make(map[string]ticker.MentionDetail)}
+
+	rows, err := q.Query(buildGetMentionDetailsQuery(), symbol)
+	if err != nil {
+		return details, fmt.Errorf("failed to retrieve mentions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var author string
+		var detail ticker.MentionDetail
+		if err := rows.Scan(
+			&author,
+			&detail.Tier,
+			&detail.TweetLink,
+			&detail.Content,
+			&detail.Category,
+			&detail.TweetID,
+			&detail.MentionedAt,
+			&detail.MentionCount,
+		); err != nil {
+			return details, fmt.Errorf("failed to scan mention: %w", err)
+		}
+		details.AddMention(author, detail)
+	}
+
+	return details, rows.Err()
+}
+
+// buildInsertMentionQuery constructs the SQL query for recording a mention.
+func buildInsertMentionQuery() string {
+	return `INSERT INTO ticker_mentions (ticker, tweet_id, author, tier, mentioned_at, tweet_link, content, category)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+            ON CONFLICT (ticker, tweet_id) DO NOTHING`
+}
+
+// buildGetMentionDetailsQuery constructs the SQL query returning every mention
+// of a ticker, latest first for every influencer.
+func buildGetMentionDetailsQuery() string {
+	return `SELECT author, tier, tweet_link, content, category,
+                   COALESCE(tweet_id::text, ''), mentioned_at,
+                   COUNT(*) OVER (PARTITION BY author)
+            FROM ticker_mentions
+            WHERE ticker = $1
+            ORDER BY author, mentioned_at DESC`
+}
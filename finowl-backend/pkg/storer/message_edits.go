@@ -0,0 +1,272 @@
+package storer
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"finowl-backend/pkg/events"
+	"finowl-backend/pkg/mindshare"
+	"finowl-backend/pkg/ticker"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ErrTweetNotFound is returned when no tweet was stored from a Discord message
+var ErrTweetNotFound = errors.New("no tweet found")
+
+// GetTweetByMessageID returns the tweet a Discord message relays, whether it
+// was stored from that message or from another relay of the same tweet
+func (s *Storer) GetTweetByMessageID(messageID string) (*Tweet, error) {
+	var tweet Tweet
+	var timestamp time.Time
+	var linksJSON, tickersJSON []byte
+
+	err := s.db.QueryRow(buildGetTweetByMessageIDQuery(), messageID).Scan(
+		&tweet.ID,
+		&tweet.Author,
+		&tweet.Handle,
+		&timestamp,
+		&tweet.Content,
+		&linksJSON,
+		&tickersJSON,
+		&tweet.Category,
+		&tweet.MessageID,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w for message %s", ErrTweetNotFound, messageID)
+		}
+		return nil, fmt.Errorf("failed to retrieve tweet: %w", err)
+	}
+
+	tweet.Timestamp = timestamp.UTC().Format(time.RFC3339)
+	if err := json.Unmarshal(linksJSON, &tweet.Links); err != nil {
+		return nil, fmt.Errorf("failed to decode links of tweet %s: %w", tweet.ID, err)
+	}
+	if err := json.Unmarshal(tickersJSON, &tweet.Tickers); err != nil {
+		return nil, fmt.Errorf("failed to decode tickers of tweet %s: %w", tweet.ID, err)
+	}
+	return &tweet, nil
+}
+
+// UpdateTweet replaces a stored tweet after its Discord message was edited.
+// tickers are the tickers extracted from the new content: mentions of the
+// tickers the tweet no longer carries are removed, new ones are recorded,
+// and every affected ticker is rescored, all in a single transaction. The
+// dedup key is kept, the message still relays the same tweet.
+func (s *Storer) UpdateTweet(tweet Tweet, tickers []ticker.Ticker) error {
+	linksJSON, _ := json.Marshal(tweet.Links)
+	tickersJSON, _ := json.Marshal(tweet.Tickers)
+
+	return s.inTx(func(tx *sql.Tx, fx *txEffects) error {
+		if _, err := tx.Exec(buildUpdateTweetQuery(), tweet.ID, tweet.Content, linksJSON, tickersJSON); err != nil {
+			return fmt.Errorf("failed to update tweet %s: %w", tweet.ID, err)
+		}
+
+		kept := make([]string, 0, len(tickers))
+		for _, t := range tickers {
+			kept = append(kept, t.TickerSymbol)
+		}
+		removed, err := deleteTweetMentions(tx, tweet.ID, kept)
+		if err != nil {
+			return err
+		}
+
+		// The remaining mentions quote the tweet, they follow the edit
+		if _, err := tx.Exec(buildUpdateTweetMentionsQuery(), tweet.ID, tweet.Content, getFirstLink(tweet.Links)); err != nil {
+			return fmt.Errorf("failed to update mentions of tweet %s: %w", tweet.ID, err)
+		}
+
+		// Mentions already recorded are skipped, new ones create or rescore their ticker
+		if err := upsertTickers(tx, fx, tickers); err != nil {
+			return fmt.Errorf("failed to record tickers of tweet %s: %w", tweet.ID, err)
+		}
+
+		return rescoreTickers(tx, fx, removed)
+	})
+}
+
+// DeleteTweet forgets a deleted Discord message. The tweet it relayed is
+// kept while other messages still relay it; along with the last one, the
+// tweet and its mentions are removed and the tickers it mentioned rescored.
+func (s *Storer) DeleteTweet(messageID string) error {
+	return s.inTx(func(tx *sql.Tx, fx *txEffects) error {
+		// Relays of a tweet deleted concurrently wait for each other, so
+		// that the last one always sees the others gone
+		var tweetID string
+		err := tx.QueryRow(buildLockTweetOfMessageQuery(), messageID).Scan(&tweetID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("%w for message %s", ErrTweetNotFound, messageID)
+			}
+			return fmt.Errorf("failed to lock tweet of message %s: %w", messageID, err)
+		}
+
+		var remaining int
+		if err := tx.QueryRow(buildDeleteTweetMessageQuery(), messageID, tweetID).Scan(&remaining); err != nil {
+			return fmt.Errorf("failed to delete message %s: %w", messageID, err)
+		}
+		if remaining > 0 {
+			return nil
+		}
+
+		if _, err := tx.Exec(`DELETE FROM tweets WHERE id = $1`, tweetID); err != nil {
+			return fmt.Errorf("failed to delete tweet %s: %w", tweetID, err)
+		}
+
+		removed, err := deleteTweetMentions(tx, tweetID, []string{})
+		if err != nil {
+			return err
+		}
+		return rescoreTickers(tx, fx, removed)
+	})
+}
+
+// deleteTweetMentions removes the mentions made by a tweet of every ticker
+// but the kept ones and returns the tickers they belonged to
+func deleteTweetMentions(q querier, tweetID string, kept []string) ([]string, error) {
+	// A nil slice is sent as NULL, against which ANY matches no row
+	if kept == nil {
+		kept = []string{}
+	}
+	rows, err := q.Query(buildDeleteTweetMentionsQuery(), tweetID, pq.Array(kept))
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete mentions of tweet %s: %w", tweetID, err)
+	}
+	defer rows.Close()
+
+	var removed []string
+	for rows.Next() {
+		var symbol string
+		if err := rows.Scan(&symbol); err != nil {
+			return nil, fmt.Errorf("failed to scan deleted mention: %w", err)
+		}
+		removed = append(removed, symbol)
+	}
+	return removed, rows.Err()
+}
+
+// rescoreTickers recomputes the given tickers from their remaining mentions
+func rescoreTickers(q querier, fx *txEffects, symbols []string) error {
+	slices.Sort(symbols)
+	for _, symbol := range slices.Compact(symbols) {
+		if err := rescoreTicker(q, fx, symbol, time.Now().UTC()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rescoreTicker recomputes the score, category and mention dates of a ticker
+// whose mentions were removed. A ticker nobody mentions anymore is deleted.
+func rescoreTicker(q querier, fx *txEffects, symbol string, now time.Time) error {
+	existing, err := lockTicker(q, symbol)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock existing ticker: %w", err)
+	}
+
+	details, err := getMentionDetails(q, symbol)
+	if err != nil {
+		return err
+	}
+
+	if len(details.Influencers) == 0 {
+		if _, err := q.Exec(`DELETE FROM Tickers_1_0 WHERE ticker_symbol = $1`, symbol); err != nil {
+			return fmt.Errorf("failed to delete ticker %s: %w", symbol, err)
+		}
+		return nil
+	}
+
+	mindShare, err := mindshare.CalculateMindshareAt(details, now)
+	if err != nil {
+		return fmt.Errorf("failed to rescore %s: %w", symbol, err)
+	}
+
+	if _, err := q.Exec(buildRecomputeTickerQuery(), mindShare.Score, mindShare.Category, symbol); err != nil {
+		return fmt.Errorf("failed to update score of %s: %w", symbol, err)
+	}
+
+	if mindShare.Category != existing.Category {
+		fx.publish(events.Event{
+			Type:             events.CategoryChanged,
+			Ticker:           symbol,
+			Category:         mindShare.Category,
+			PreviousCategory: existing.Category,
+			MindshareScore:   mindShare.Score,
+		})
+	}
+	return nil
+}
+
+// buildGetTweetByMessageIDQuery constructs the SQL query for retrieving the tweet relayed by a Discord message.
+func buildGetTweetByMessageIDQuery() string {
+	return `SELECT t.id, t.author, t.handle, t.timestamp, t.content, t.links, t.tickers, t.category, m.discord_message_id
+            FROM tweet_messages m
+            JOIN tweets t ON t.id = m.tweet_id
+            WHERE m.discord_message_id = $1`
+}
+
+// buildLockTweetOfMessageQuery constructs the SQL query locking the tweet relayed by a Discord message.
+func buildLockTweetOfMessageQuery() string {
+	return `SELECT t.id
+            FROM tweet_messages m
+            JOIN tweets t ON t.id = m.tweet_id
+            WHERE m.discord_message_id = $1
+            FOR UPDATE OF t`
+}
+
+// buildDeleteTweetMessageQuery constructs the SQL query for deleting a relay of a tweet and
+// counting the relays left. The message the tweet was stored from moves to one of those.
+func buildDeleteTweetMessageQuery() string {
+	return `WITH deleted AS (
+                DELETE FROM tweet_messages WHERE discord_message_id = $1
+            ), remaining AS (
+                SELECT discord_message_id FROM tweet_messages
+                WHERE tweet_id = $2 AND discord_message_id <> $1
+            ), moved AS (
+                UPDATE tweets SET discord_message_id = (SELECT MIN(discord_message_id) FROM remaining)
+                WHERE id = $2 AND discord_message_id = $1 AND EXISTS (SELECT 1 FROM remaining)
+            )
+            SELECT COUNT(*) FROM remaining`
+}
+
+// buildUpdateTweetQuery constructs the SQL query for replacing the content of a tweet.
+func buildUpdateTweetQuery() string {
+	return `UPDATE tweets
+            SET content = $2,
+                links = $3,
+                tickers = $4
+            WHERE id = $1`
+}
+
+// buildDeleteTweetMentionsQuery constructs the SQL query for deleting the
+// mentions of a tweet, except those of the given tickers.
+func buildDeleteTweetMentionsQuery() string {
+	return `DELETE FROM ticker_mentions
+            WHERE tweet_id = $1 AND NOT (ticker = ANY($2))
+            RETURNING ticker`
+}
+
+// buildUpdateTweetMentionsQuery constructs the SQL query for updating the quoted content of a tweet's mentions.
+func buildUpdateTweetMentionsQuery() string {
+	return `UPDATE ticker_mentions
+            SET content = $2,
+                tweet_link = $3
+            WHERE tweet_id = $1`
+}
+
+// buildRecomputeTickerQuery constructs the SQL query for updating a ticker from its remaining mentions.
+func buildRecomputeTickerQuery() string {
+	return `UPDATE Tickers_1_0
+            SET mindshare_score = $1,
+                category = $2,
+                first_mentioned_at = (SELECT MIN(mentioned_at) FROM ticker_mentions WHERE ticker = $3),
+                last_mentioned_at = (SELECT MAX(mentioned_at) FROM ticker_mentions WHERE ticker = $3)
+            WHERE ticker_symbol = $3`
+}
@@ -0,0 +1,150 @@
+package storer
+
+import (
+	"testing"
+	"time"
+
+	"finowl-backend/pkg/events"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var mentionDetailColumns = []string{"author", "tier", "tweet_link", "content", "category", "tweet_id", "mentioned_at", "count"}
+
+func TestGetTweetByMessageID(t *testing.T) {
+	s, mock := newMockStorer(t)
+	defer s.db.Close()
+
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT t.id, t.author, t.handle, .* FROM tweet_messages m JOIN tweets t").
+		WithArgs("1234").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "author", "handle", "timestamp", "content", "links", "tickers", "category", "discord_message_id"}).
+			AddRow("tweet-1", "whale", "whale_eth", at, "$AIXBT to the moon", []byte(`["https://x.com/whale/status/1"]`), []byte(`["$AIXBT"]`), "EarlyAlpha", "1234"))
+
+	tweet, err := s.GetTweetByMessageID("1234")
+	require.NoError(t, err)
+	assert.Equal(t, "2025-03-01T12:00:00Z", tweet.Timestamp)
+	assert.Equal(t, []string{"https://x.com/whale/status/1"}, tweet.Links)
+	assert.Equal(t, []string{"$AIXBT"}, tweet.Tickers)
+
+	mock.ExpectQuery("FROM tweet_messages m JOIN tweets t").
+		WithArgs("999").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err = s.GetTweetByMessageID("999")
+	assert.ErrorIs(t, err, ErrTweetNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateTweetRemovesDroppedTickers(t *testing.T) {
+	s, mock := newMockStorer(t)
+	defer s.db.Close()
+
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	tweet := Tweet{ID: "tweet-1", Content: "nothing to see here", Links: []string{"https://x.com/whale/status/1"}, MessageID: "1234"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE tweets").
+		WithArgs("tweet-1", "nothing to see here", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("DELETE FROM ticker_mentions").
+		WithArgs("tweet-1", "{}").
+		WillReturnRows(sqlmock.NewRows([]string{"ticker"}).AddRow("SPAM"))
+	mock.ExpectExec("UPDATE ticker_mentions").
+		WithArgs("tweet-1", "nothing to see here", "https://x.com/whale/status/1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// SPAM was only mentioned by this tweet and disappears
+	mock.ExpectQuery("SELECT ticker_symbol, category, mindshare_score, last_mentioned_at .* FOR UPDATE").
+		WithArgs("SPAM").
+		WillReturnRows(sqlmock.NewRows([]string{"ticker_symbol", "category", "mindshare_score", "last_mentioned_at"}).
+			AddRow("SPAM", "Trenches", 17.6, at))
+	mock.ExpectQuery("SELECT author, tier, tweet_link").
+		WithArgs("SPAM").
+		WillReturnRows(sqlmock.NewRows(mentionDetailColumns))
+	mock.ExpectExec("DELETE FROM Tickers_1_0").
+		WithArgs("SPAM").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, s.UpdateTweet(tweet, nil))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteTweetRescoresTickers(t *testing.T) {
+	s, mock := newMockStorer(t)
+	defer s.db.Close()
+
+	at := time.Now().UTC()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT t.id FROM tweet_messages .* FOR UPDATE OF t").
+		WithArgs("1234").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tweet-1"))
+	mock.ExpectQuery("DELETE FROM tweet_messages").
+		WithArgs("1234", "tweet-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectExec("DELETE FROM tweets WHERE id").
+		WithArgs("tweet-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Every mention of the tweet goes, an empty array rather than NULL
+	mock.ExpectQuery("DELETE FROM ticker_mentions").
+		WithArgs("tweet-1", "{}").
+		WillReturnRows(sqlmock.NewRows([]string{"ticker"}).AddRow("AIXBT"))
+
+	// A single tier 3 mention is left, far from High Alpha
+	mock.ExpectQuery("SELECT ticker_symbol, category, mindshare_score, last_mentioned_at .* FOR UPDATE").
+		WithArgs("AIXBT").
+		WillReturnRows(sqlmock.NewRows([]string{"ticker_symbol", "category", "mindshare_score", "last_mentioned_at"}).
+			AddRow("AIXBT", "High Alpha", 614.0, at))
+	mock.ExpectQuery("SELECT author, tier, tweet_link").
+		WithArgs("AIXBT").
+		WillReturnRows(sqlmock.NewRows(mentionDetailColumns).
+			AddRow("degen", 3, "", "", "EarlyAlpha", "tweet-2", at, 1))
+	mock.ExpectExec("UPDATE Tickers_1_0").
+		WithArgs(sqlmock.AnyArg(), "Trenches", "AIXBT").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	publisher := &recordingPublisher{}
+	s.SetEventPublisher(publisher)
+
+	require.NoError(t, s.DeleteTweet("1234"))
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, events.CategoryChanged, publisher.events[0].Type)
+	assert.Equal(t, "High Alpha", publisher.events[0].PreviousCategory)
+
+	// Messages that never became a tweet
+	mock.ExpectBegin()
+	mock.ExpectQuery("FOR UPDATE OF t").
+		WithArgs("999").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+	assert.ErrorIs(t, s.DeleteTweet("999"), ErrTweetNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteTweetKeepsRelayedTweets(t *testing.T) {
+	s, mock := newMockStorer(t)
+	defer s.db.Close()
+
+	// Another message still relays the tweet: only this one is forgotten
+	mock.ExpectBegin()
+	mock.ExpectQuery("FOR UPDATE OF t").
+		WithArgs("1234").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("tweet-1"))
+	mock.ExpectQuery("DELETE FROM tweet_messages").
+		WithArgs("1234", "tweet-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectCommit()
+
+	publisher := &recordingPublisher{}
+	s.SetEventPublisher(publisher)
+
+	require.NoError(t, s.DeleteTweet("1234"))
+	assert.Empty(t, publisher.events)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
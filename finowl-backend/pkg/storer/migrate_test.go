@@ -0,0 +1,144 @@
+package storer
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations(fstest.MapFS{
+		"migrations/0010_later.sql":    {Data: []byte("ALTER TABLE tweets ADD COLUMN x TEXT;")},
+		"migrations/0002_second.sql":   {Data: []byte("CREATE TABLE b (id INT);")},
+		"migrations/0001_baseline.sql": {Data: []byte("CREATE TABLE a (id INT);")},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, migrations, 3)
+	assert.Equal(t, Migration{Version: 1, Name: "baseline", SQL: "CREATE TABLE a (id INT);"}, migrations[0])
+	assert.Equal(t, 2, migrations[1].Version)
+	assert.Equal(t, 10, migrations[2].Version)
+	assert.Equal(t, "later", migrations[2].Name)
+}
+
+func TestLoadMigrationsRejectsBadFiles(t *testing.T) {
+	_, err := loadMigrations(fstest.MapFS{
+		"migrations/0001_baseline.sql": {Data: []byte("")},
+		"migrations/001_again.sql":     {Data: []byte("")},
+	})
+	assert.ErrorContains(t, err, "share version 1")
+
+	_, err = loadMigrations(fstest.MapFS{"migrations/baseline.sql": {Data: []byte("")}})
+	assert.ErrorContains(t, err, "invalid migration name")
+
+	_, err = loadMigrations(fstest.MapFS{"migrations/0000_zero.sql": {Data: []byte("")}})
+	assert.ErrorContains(t, err, "invalid migration version")
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := loadMigrations(migrationFiles)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, migrations)
+	for i, m := range migrations {
+		assert.Equal(t, i+1, m.Version, "migration versions must have no gaps")
+		assert.NotEmpty(t, m.SQL)
+	}
+}
+
+// expectMigrationLock expects the migration lock and the schema_migrations
+// table, followed by the read of the applied versions
+func expectMigrationLock(mock sqlmock.Sqlmock, applied ...int) {
+	mock.ExpectExec(`SELECT pg_advisory_lock\(\$1\)`).WithArgs(migrationLockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	rows := sqlmock.NewRows([]string{"version", "name", "applied_at"})
+	for _, version := range applied {
+		rows.AddRow(version, "applied", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	}
+	mock.ExpectQuery(`SELECT version, name, applied_at FROM schema_migrations`).WillReturnRows(rows)
+}
+
+func TestMigrateAppliesPending(t *testing.T) {
+	s, mock := newMockStorer(t)
+	migrations, err := loadMigrations(migrationFiles)
+	require.NoError(t, err)
+
+	expectMigrationLock(mock, 1)
+	for _, m := range migrations[1:] {
+		mock.ExpectBegin()
+		mock.ExpectExec(`.+`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`INSERT INTO schema_migrations`).WithArgs(m.Version, m.Name).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+	}
+	mock.ExpectExec(`SELECT pg_advisory_unlock\(\$1\)`).WithArgs(migrationLockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	applied, err := s.Migrate()
+	require.NoError(t, err)
+
+	assert.Equal(t, migrations[1:], applied)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrateUpToDate(t *testing.T) {
+	s, mock := newMockStorer(t)
+	migrations, err := loadMigrations(migrationFiles)
+	require.NoError(t, err)
+
+	var versions []int
+	for _, m := range migrations {
+		versions = append(versions, m.Version)
+	}
+	expectMigrationLock(mock, versions...)
+	mock.ExpectExec(`SELECT pg_advisory_unlock`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	applied, err := s.Migrate()
+	require.NoError(t, err)
+
+	assert.Empty(t, applied)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrateStopsAtFailure(t *testing.T) {
+	s, mock := newMockStorer(t)
+
+	expectMigrationLock(mock)
+	mock.ExpectBegin()
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS tweets`).WillReturnError(errors.New("syntax error"))
+	mock.ExpectRollback()
+	mock.ExpectExec(`SELECT pg_advisory_unlock`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	applied, err := s.Migrate()
+	assert.ErrorContains(t, err, "failed to apply migration 0001_baseline")
+	assert.Empty(t, applied)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrationStatus(t *testing.T) {
+	s, mock := newMockStorer(t)
+	migrations, err := loadMigrations(migrationFiles)
+	require.NoError(t, err)
+	future := len(migrations) + 1
+
+	expectMigrationLock(mock, 1, future)
+	mock.ExpectExec(`SELECT pg_advisory_unlock`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	states, err := s.MigrationStatus()
+	require.NoError(t, err)
+
+	require.Len(t, states, len(migrations)+1)
+	assert.Equal(t, "baseline", states[0].Name)
+	require.NotNil(t, states[0].AppliedAt)
+	assert.False(t, states[0].Unknown)
+	assert.Nil(t, states[1].AppliedAt)
+
+	last := states[len(states)-1]
+	assert.Equal(t, future, last.Version)
+	assert.True(t, last.Unknown)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
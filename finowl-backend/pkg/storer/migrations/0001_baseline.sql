@@ -0,0 +1,183 @@
+-- Schema created by CreateTables before migrations were versioned. Every
+-- statement is idempotent, so databases created by it are brought up to date.
+
+CREATE TABLE IF NOT EXISTS tweets (
+	id UUID PRIMARY KEY,
+	author VARCHAR(255),
+	timestamp TIMESTAMP,
+	content TEXT,
+	links JSONB,
+	tickers JSONB,
+	category VARCHAR(50) NOT NULL DEFAULT '',
+	discord_message_id VARCHAR(32),
+	handle VARCHAR(50) NOT NULL DEFAULT '',
+	source VARCHAR(32) NOT NULL DEFAULT 'discord',
+	dedup_key VARCHAR(80)
+);
+
+-- Tables created before channel categories existed lack the column
+ALTER TABLE tweets ADD COLUMN IF NOT EXISTS category VARCHAR(50) NOT NULL DEFAULT '';
+
+-- Edits and deletions of a relayed message are matched by its ID. Tweets
+-- stored before it was recorded keep a NULL and cannot be edited.
+ALTER TABLE tweets ADD COLUMN IF NOT EXISTS discord_message_id VARCHAR(32);
+CREATE UNIQUE INDEX IF NOT EXISTS tweets_discord_message_id_idx ON tweets (discord_message_id);
+
+-- Only relays embedding the tweet show the Twitter handle of its author
+ALTER TABLE tweets ADD COLUMN IF NOT EXISTS handle VARCHAR(50) NOT NULL DEFAULT '';
+
+-- Tweets stored before the ingest API existed were all relayed on Discord
+ALTER TABLE tweets ADD COLUMN IF NOT EXISTS source VARCHAR(32) NOT NULL DEFAULT 'discord';
+
+-- A tweet relayed by several messages is stored once. Tweets stored
+-- before it was recorded keep a NULL and are not matched.
+ALTER TABLE tweets ADD COLUMN IF NOT EXISTS dedup_key VARCHAR(80);
+CREATE UNIQUE INDEX IF NOT EXISTS tweets_dedup_key_idx ON tweets (dedup_key);
+
+CREATE TABLE IF NOT EXISTS Tickers_1_0 (
+	ticker_symbol VARCHAR(10) PRIMARY KEY,
+	category VARCHAR(20) CHECK (category IN ('High Alpha', 'Alpha', 'Trenches')),
+	mindshare_score DECIMAL(10,2),
+	last_mentioned_at TIMESTAMP,
+	first_mentioned_at TIMESTAMP,
+	mention_details JSONB -- legacy influencer map, superseded by ticker_mentions
+);
+
+-- One row per ticker mentioned in a tweet
+CREATE TABLE IF NOT EXISTS ticker_mentions (
+	id BIGSERIAL PRIMARY KEY,
+	ticker VARCHAR(20) NOT NULL,
+	tweet_id UUID,
+	author VARCHAR(255) NOT NULL,
+	tier INTEGER NOT NULL,
+	mentioned_at TIMESTAMP NOT NULL,
+	tweet_link TEXT NOT NULL DEFAULT '',
+	content TEXT NOT NULL DEFAULT '',
+	category VARCHAR(50) NOT NULL DEFAULT '',
+	UNIQUE (ticker, tweet_id)
+);
+CREATE INDEX IF NOT EXISTS ticker_mentions_ticker_idx ON ticker_mentions (ticker, mentioned_at);
+CREATE INDEX IF NOT EXISTS ticker_mentions_mentioned_at_idx ON ticker_mentions (mentioned_at);
+
+-- Copy the influencer map of Tickers_1_0.mention_details into ticker_mentions.
+-- It only runs while ticker_mentions is empty. Tweet IDs and timestamps are
+-- recovered from the tweets table through the tweet link when possible.
+INSERT INTO ticker_mentions (ticker, tweet_id, author, tier, mentioned_at, tweet_link, content, category)
+SELECT t.ticker_symbol,
+       tw.id,
+       m.key,
+       COALESCE((m.value->>'tier')::INTEGER, 3),
+       COALESCE(tw.timestamp, t.last_mentioned_at, NOW()),
+       COALESCE(m.value->>'tweet_link', ''),
+       COALESCE(m.value->>'content', ''),
+       COALESCE(m.value->>'category', '')
+FROM Tickers_1_0 t
+CROSS JOIN LATERAL jsonb_each(t.mention_details->'influencers') AS m
+LEFT JOIN LATERAL (
+	SELECT id, timestamp FROM tweets
+	WHERE COALESCE(m.value->>'tweet_link', '') <> ''
+	  AND links @> jsonb_build_array(m.value->>'tweet_link')
+	ORDER BY timestamp DESC
+	LIMIT 1
+) AS tw ON TRUE
+WHERE jsonb_typeof(t.mention_details->'influencers') = 'object'
+  AND NOT EXISTS (SELECT 1 FROM ticker_mentions)
+ON CONFLICT (ticker, tweet_id) DO NOTHING;
+
+-- Hourly and daily history of every ticker
+CREATE TABLE IF NOT EXISTS ticker_snapshots (
+	ticker VARCHAR(20) NOT NULL,
+	bucket_interval VARCHAR(10) NOT NULL CHECK (bucket_interval IN ('hour', 'day')),
+	bucket_start TIMESTAMP NOT NULL,
+	mindshare_score DECIMAL(10,2) NOT NULL,
+	category VARCHAR(20) NOT NULL,
+	mention_count INTEGER NOT NULL,
+	influencer_count INTEGER NOT NULL,
+	recorded_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (ticker, bucket_interval, bucket_start)
+);
+
+CREATE TABLE IF NOT EXISTS Summaries (
+	id SERIAL PRIMARY KEY,
+	timestamp TIMESTAMP NOT NULL,
+	content TEXT NOT NULL,
+	category VARCHAR(50) NOT NULL DEFAULT '',
+	first_tweet_id UUID,
+	last_tweet_id UUID,
+	tweet_count INTEGER NOT NULL DEFAULT 0,
+	window_start TIMESTAMP,
+	window_end TIMESTAMP,
+	featured_tickers TEXT,
+	key_insights TEXT,
+	market_sentiment TEXT
+);
+
+-- Bring tables created by older versions up to date
+ALTER TABLE Summaries ADD COLUMN IF NOT EXISTS category VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE Summaries ADD COLUMN IF NOT EXISTS first_tweet_id UUID;
+ALTER TABLE Summaries ADD COLUMN IF NOT EXISTS last_tweet_id UUID;
+ALTER TABLE Summaries ADD COLUMN IF NOT EXISTS tweet_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE Summaries ADD COLUMN IF NOT EXISTS window_start TIMESTAMP;
+ALTER TABLE Summaries ADD COLUMN IF NOT EXISTS window_end TIMESTAMP;
+ALTER TABLE Summaries ADD COLUMN IF NOT EXISTS featured_tickers TEXT;
+ALTER TABLE Summaries ADD COLUMN IF NOT EXISTS key_insights TEXT;
+ALTER TABLE Summaries ADD COLUMN IF NOT EXISTS market_sentiment TEXT;
+
+-- Featured ticker bullets parsed from each summary
+CREATE TABLE IF NOT EXISTS summary_tickers (
+	summary_id INTEGER NOT NULL REFERENCES Summaries(id) ON DELETE CASCADE,
+	position INTEGER NOT NULL,
+	ticker_symbol VARCHAR(20) NOT NULL,
+	project_name TEXT NOT NULL DEFAULT '',
+	description TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY (summary_id, position)
+);
+CREATE INDEX IF NOT EXISTS summary_tickers_symbol_idx ON summary_tickers (ticker_symbol);
+
+CREATE TABLE IF NOT EXISTS alert_rules (
+	id BIGSERIAL PRIMARY KEY,
+	name VARCHAR(100) NOT NULL,
+	rule_type VARCHAR(30) NOT NULL,
+	ticker VARCHAR(20) NOT NULL DEFAULT '',
+	category VARCHAR(20) NOT NULL DEFAULT '',
+	threshold DOUBLE PRECISION NOT NULL DEFAULT 0,
+	tier INTEGER NOT NULL DEFAULT 0,
+	window_seconds INTEGER NOT NULL DEFAULT 0,
+	cooldown_seconds INTEGER NOT NULL DEFAULT 0,
+	webhook_url TEXT NOT NULL,
+	secret TEXT NOT NULL,
+	enabled BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Log of the webhooks fired by the alert rules
+CREATE TABLE IF NOT EXISTS alert_deliveries (
+	id BIGSERIAL PRIMARY KEY,
+	rule_id BIGINT NOT NULL REFERENCES alert_rules (id) ON DELETE CASCADE,
+	ticker VARCHAR(20) NOT NULL,
+	payload JSONB NOT NULL,
+	status VARCHAR(10) NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	response_status INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL,
+	delivered_at TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS alert_deliveries_rule_idx ON alert_deliveries (rule_id, ticker, created_at);
+
+-- What the bot already posted, so that restarts do not post it again
+CREATE TABLE IF NOT EXISTS discord_posts (
+	kind VARCHAR(20) NOT NULL,
+	ref VARCHAR(50) NOT NULL,
+	posted_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (kind, ref)
+);
+
+-- Last Discord message processed in every collected channel, from which
+-- missed messages are backfilled
+CREATE TABLE IF NOT EXISTS channel_checkpoints (
+	channel_id VARCHAR(32) PRIMARY KEY,
+	last_message_id BIGINT NOT NULL,
+	updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,14 @@
+-- Messages that could not be stored, until a retry stores them
+CREATE TABLE IF NOT EXISTS dead_letters (
+	id BIGSERIAL PRIMARY KEY,
+	source VARCHAR(32) NOT NULL,
+	message_id VARCHAR(64) NOT NULL DEFAULT '',
+	payload JSONB NOT NULL,
+	status VARCHAR(10) NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL,
+	next_attempt_at TIMESTAMP NOT NULL,
+	resolved_at TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS dead_letters_due_idx ON dead_letters (status, next_attempt_at);
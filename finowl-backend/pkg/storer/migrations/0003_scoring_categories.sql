@@ -0,0 +1,6 @@
+-- Mindshare categories are read from scoring.yaml: any configured name is
+-- stored, up to mindshare.MaxCategoryNameLength characters
+ALTER TABLE Tickers_1_0 DROP CONSTRAINT IF EXISTS tickers_1_0_category_check;
+ALTER TABLE Tickers_1_0 ALTER COLUMN category TYPE VARCHAR(50);
+ALTER TABLE ticker_snapshots ALTER COLUMN category TYPE VARCHAR(50);
+ALTER TABLE alert_rules ALTER COLUMN category TYPE VARCHAR(50);
@@ -0,0 +1,8 @@
+-- When a tweet was stored, which summaries window on: backfilled, imported
+-- and retried tweets are stored long after they were posted. Tweets stored
+-- before it was recorded are deemed stored when they were posted.
+ALTER TABLE tweets ADD COLUMN IF NOT EXISTS inserted_at TIMESTAMP;
+UPDATE tweets SET inserted_at = COALESCE(timestamp, NOW() AT TIME ZONE 'UTC') WHERE inserted_at IS NULL;
+ALTER TABLE tweets ALTER COLUMN inserted_at SET DEFAULT (NOW() AT TIME ZONE 'UTC');
+ALTER TABLE tweets ALTER COLUMN inserted_at SET NOT NULL;
+CREATE INDEX IF NOT EXISTS tweets_inserted_at_idx ON tweets (inserted_at);
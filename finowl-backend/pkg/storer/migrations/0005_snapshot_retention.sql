@@ -0,0 +1,2 @@
+-- Expired hourly snapshots are deleted by bucket, across tickers
+CREATE INDEX IF NOT EXISTS ticker_snapshots_bucket_idx ON ticker_snapshots (bucket_interval, bucket_start);
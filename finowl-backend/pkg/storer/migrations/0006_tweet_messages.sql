@@ -0,0 +1,12 @@
+-- Every Discord message relaying a tweet: the message that stored it and the
+-- duplicate relays skipped by its dedup key. A tweet is only deleted along
+-- with the last of them. Relays skipped before they were recorded are unknown.
+CREATE TABLE IF NOT EXISTS tweet_messages (
+	discord_message_id VARCHAR(32) PRIMARY KEY,
+	tweet_id UUID NOT NULL REFERENCES tweets (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS tweet_messages_tweet_id_idx ON tweet_messages (tweet_id);
+
+INSERT INTO tweet_messages (discord_message_id, tweet_id)
+SELECT discord_message_id, id FROM tweets WHERE discord_message_id IS NOT NULL
+ON CONFLICT DO NOTHING;
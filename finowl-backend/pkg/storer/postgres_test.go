@@ -0,0 +1,152 @@
+package storer
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"finowl-backend/pkg/ticker"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPostgresStorer returns a storer on a fresh schema of the database at
+// TEST_DATABASE_URL, migrated to the latest version and dropped once the test
+// is over. Tests using it are skipped when the variable is unset.
+func newPostgresStorer(t *testing.T) *Storer {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	admin, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { admin.Close() })
+
+	schema := fmt.Sprintf("finowl_test_%d", time.Now().UnixNano())
+	_, err = admin.Exec(`CREATE SCHEMA ` + schema)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if _, err := admin.Exec(`DROP SCHEMA ` + schema + ` CASCADE`); err != nil {
+			t.Logf("failed to drop schema %s: %v", schema, err)
+		}
+	})
+
+	s, err := NewStorer(withSearchPath(dsn, schema))
+	require.NoError(t, err)
+	t.Cleanup(func() { s.db.Close() })
+
+	_, err = s.Migrate()
+	require.NoError(t, err)
+	return s
+}
+
+// withSearchPath makes the connections of a URL or key=value DSN use schema
+func withSearchPath(dsn, schema string) string {
+	if !strings.Contains(dsn, "://") {
+		return dsn + " search_path=" + schema
+	}
+	if strings.Contains(dsn, "?") {
+		return dsn + "&search_path=" + schema
+	}
+	return dsn + "?search_path=" + schema
+}
+
+func TestStoreTweetConcurrentMentionsPostgres(t *testing.T) {
+	s := newPostgresStorer(t)
+
+	const tweets = 20
+	at := time.Now().UTC().Truncate(time.Second)
+
+	// Every tweet mentions AIXBT, which none of them knows to exist: they
+	// race to create it, then for its row
+	var wg sync.WaitGroup
+	errs := make(chan error, tweets)
+	for i := 0; i < tweets; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id := uuid.NewString()
+			author := fmt.Sprintf("influencer%d", i%5)
+			tweet := Tweet{
+				ID:        id,
+				Author:    author,
+				Timestamp: at.Format(time.RFC3339),
+				Content:   fmt.Sprintf("$AIXBT call number %d", i),
+				MessageID: fmt.Sprintf("%d", 1000+i),
+			}
+			errs <- s.StoreTweet(tweet, []ticker.Ticker{sampleMentionTicker(author, id, at)})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	// No mention is lost and the ticker exists once, scored from all of them
+	var mentions, tickers int
+	require.NoError(t, s.db.QueryRow(`SELECT COUNT(*) FROM ticker_mentions WHERE ticker = 'AIXBT'`).Scan(&mentions))
+	require.NoError(t, s.db.QueryRow(`SELECT COUNT(*) FROM Tickers_1_0 WHERE ticker_symbol = 'AIXBT'`).Scan(&tickers))
+	assert.Equal(t, tweets, mentions)
+	assert.Equal(t, 1, tickers)
+
+	details, err := getMentionDetails(s.db, "AIXBT")
+	require.NoError(t, err)
+	assert.Len(t, details.Influencers, 5)
+
+	var score float64
+	require.NoError(t, s.db.QueryRow(`SELECT mindshare_score FROM Tickers_1_0 WHERE ticker_symbol = 'AIXBT'`).Scan(&score))
+	assert.Greater(t, score, 17.6)
+}
+
+func TestDeleteTweetRelaysPostgres(t *testing.T) {
+	s := newPostgresStorer(t)
+
+	at := time.Now().UTC().Truncate(time.Second)
+	relay := func(messageID string) Tweet {
+		return Tweet{
+			ID:        uuid.NewString(),
+			Author:    "Stats",
+			Timestamp: at.Format(time.RFC3339),
+			Content:   "$AIXBT is back",
+			Links:     []string{"https://x.com/stats/status/1879491000000000000"},
+			MessageID: messageID,
+		}
+	}
+
+	first, second := relay("1001"), relay("1002")
+	require.NoError(t, s.StoreTweet(first, []ticker.Ticker{sampleMentionTicker("Stats", first.ID, at)}))
+	require.ErrorIs(t, s.StoreTweet(second, []ticker.Ticker{sampleMentionTicker("Stats", second.ID, at)}), ErrDuplicateTweet)
+
+	// The second relay finds the tweet of the first
+	stored, err := s.GetTweetByMessageID("1002")
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, stored.ID)
+
+	// Deleting the first relay keeps the tweet the second still carries
+	require.NoError(t, s.DeleteTweet("1001"))
+	assert.ErrorIs(t, s.DeleteTweet("1001"), ErrTweetNotFound)
+	stored, err = s.GetTweetByMessageID("1002")
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, stored.ID)
+
+	var mentions int
+	require.NoError(t, s.db.QueryRow(`SELECT COUNT(*) FROM ticker_mentions WHERE tweet_id = $1`, first.ID).Scan(&mentions))
+	assert.Equal(t, 1, mentions)
+
+	// Along with the last relay the tweet goes
+	require.NoError(t, s.DeleteTweet("1002"))
+	var tweets int
+	require.NoError(t, s.db.QueryRow(`SELECT COUNT(*) FROM tweets WHERE id = $1`, first.ID).Scan(&tweets))
+	require.NoError(t, s.db.QueryRow(`SELECT COUNT(*) FROM ticker_mentions WHERE tweet_id = $1`, first.ID).Scan(&mentions))
+	assert.Zero(t, tweets)
+	assert.Zero(t, mentions)
+}
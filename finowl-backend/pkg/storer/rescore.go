@@ -0,0 +1,183 @@
+package storer
+
+import (
+	"database/sql"
+	"finowl-backend/pkg/events"
+	"finowl-backend/pkg/mindshare"
+	"finowl-backend/pkg/ticker"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// tickerScore holds the stored score of a ticker
+type tickerScore struct {
+	score    float64
+	category string
+}
+
+// RescoreTickers recomputes the decayed mindshare score and category of every
+// ticker as of now and persists the ones that changed. It returns how many
+// tickers were updated.
+//
+// Scores are first computed from a snapshot of every ticker, then each one
+// that drifted is rescored again from its locked row, so that mentions stored
+// meanwhile are neither overwritten nor announced from a stale category.
+func (s *Storer) RescoreTickers(now time.Time) (int, error) {
+	current, err := s.getTickerScores()
+	if err != nil {
+		return 0, err
+	}
+
+	mentions, err := s.getAllMentionDetails()
+	if err != nil {
+		return 0, err
+	}
+
+	symbols := make([]string, 0, len(mentions))
+	for symbol, details := range mentions {
+		stored, ok := current[symbol]
+		if !ok {
+			continue
+		}
+
+		mindShare, err := mindshare.CalculateMindshareAt(details, now)
+		if err != nil {
+			return 0, fmt.Errorf("failed to rescore %s: %w", symbol, err)
+		}
+		if scoreChanged(stored, mindShare) {
+			symbols = append(symbols, symbol)
+		}
+	}
+	// Tickers are locked in turn, in the order upserts lock them
+	sort.Strings(symbols)
+
+	updated := 0
+	for _, symbol := range symbols {
+		var changed bool
+		err := s.inTx(func(tx *sql.Tx, fx *txEffects) error {
+			var err error
+			changed, err = rescoreDecayedTicker(tx, fx, symbol, now)
+			return err
+		})
+		if err != nil {
+			return updated, err
+		}
+		if changed {
+			updated++
+		}
+	}
+
+	return updated, nil
+}
+
+// rescoreDecayedTicker recomputes the decayed score of a ticker from its
+// locked row and current mentions, and reports whether it had to be updated.
+// A ticker deleted meanwhile, or left without mentions, is left to the
+// message edits removing them.
+func rescoreDecayedTicker(q querier, fx *txEffects, symbol string, now time.Time) (bool, error) {
+	existing, err := lockTicker(q, symbol)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to lock existing ticker: %w", err)
+	}
+
+	details, err := getMentionDetails(q, symbol)
+	if err != nil {
+		return false, err
+	}
+	if len(details.Influencers) == 0 {
+		return false, nil
+	}
+
+	mindShare, err := mindshare.CalculateMindshareAt(details, now)
+	if err != nil {
+		return false, fmt.Errorf("failed to rescore %s: %w", symbol, err)
+	}
+	if !scoreChanged(tickerScore{score: existing.MindshareScore, category: existing.Category}, mindShare) {
+		return false, nil
+	}
+
+	if _, err := q.Exec(buildRescoreTickerQuery(), mindShare.Score, mindShare.Category, symbol); err != nil {
+		return false, fmt.Errorf("failed to update score of %s: %w", symbol, err)
+	}
+
+	if mindShare.Category != existing.Category {
+		fx.publish(events.Event{
+			Type:             events.CategoryChanged,
+			Ticker:           symbol,
+			Category:         mindShare.Category,
+			PreviousCategory: existing.Category,
+			MindshareScore:   mindShare.Score,
+		})
+	}
+	return true, nil
+}
+
+// scoreChanged reports whether a computed score is worth a write. Scores are
+// stored with two decimals, smaller drifts are not.
+func scoreChanged(stored tickerScore, computed *mindshare.Mindshare) bool {
+	return math.Abs(computed.Score-stored.score) >= 0.005 || computed.Category != stored.category
+}
+
+// getTickerScores returns the stored score and category of every ticker
+func (s *Storer) getTickerScores() (map[string]tickerScore, error) {
+	rows, err := s.db.Query(`SELECT ticker_symbol, category, mindshare_score FROM Tickers_1_0`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve ticker scores: %w", err)
+	}
+	defer rows.Close()
+
+	scores := make(map[string]tickerScore)
+	for rows.Next() {
+		var symbol string
+		var score tickerScore
+		if err := rows.Scan(&symbol, &score.category, &score.score); err != nil {
+			return nil, fmt.Errorf("failed to scan ticker score: %w", err)
+		}
+		scores[symbol] = score
+	}
+
+	return scores, rows.Err()
+}
+
+// getAllMentionDetails builds the per-influencer mention view of every ticker in a single query
+func (s *Storer) getAllMentionDetails() (map[string]ticker.MentionDetails, error) {
+	rows, err := s.db.Query(`
+		SELECT ticker, author, tier, mentioned_at
+		FROM ticker_mentions
+		ORDER BY ticker, author, mentioned_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve mentions: %w", err)
+	}
+	defer rows.Close()
+
+	mentions := make(map[string]ticker.MentionDetails)
+	for rows.Next() {
+		var symbol, author string
+		var detail ticker.MentionDetail
+		if err := rows.Scan(&symbol, &author, &detail.Tier, &detail.MentionedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan mention: %w", err)
+		}
+
+		details, ok := mentions[symbol]
+		if !ok {
+			details = ticker.MentionDetails{Influencers: make(map[string]ticker.MentionDetail)}
+			mentions[symbol] = details
+		}
+		details.AddMention(author, detail)
+	}
+
+	return mentions, rows.Err()
+}
+
+// buildRescoreTickerQuery constructs the SQL query for updating the score of a ticker.
+func buildRescoreTickerQuery() string {
+	return `UPDATE Tickers_1_0
+            SET mindshare_score = $1,
+                category = $2
+            WHERE ticker_symbol = $3`
+}
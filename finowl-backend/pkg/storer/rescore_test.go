@@ -0,0 +1,138 @@
+package storer
+
+import (
+	"testing"
+	"time"
+
+	"finowl-backend/pkg/events"
+	"finowl-backend/pkg/mindshare"
+	"finowl-backend/pkg/ticker"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRescoreTickers(t *testing.T) {
+	s, mock := newMockStorer(t)
+	defer s.db.Close()
+
+	previousHalfLife := mindshare.DecayHalfLife
+	mindshare.DecayHalfLife = 72 * time.Hour
+	defer func() { mindshare.DecayHalfLife = previousHalfLife }()
+
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	// NEW is stored with its up to date score and needs no write
+	fresh, err := mindshare.CalculateMindshareAt(ticker.MentionDetails{Influencers: map[string]ticker.MentionDetail{
+		"whale1": {Tier: 1, MentionedAt: now},
+	}}, now)
+	require.NoError(t, err)
+
+	mock.ExpectQuery("SELECT ticker_symbol, category, mindshare_score FROM Tickers_1_0").
+		WillReturnRows(sqlmock.NewRows([]string{"ticker_symbol", "category", "mindshare_score"}).
+			AddRow("OLD", "High Alpha", 614.0).
+			AddRow("NEW", fresh.Category, fresh.Score))
+
+	mock.ExpectQuery("SELECT ticker, author, tier, mentioned_at").
+		WillReturnRows(sqlmock.NewRows([]string{"ticker", "author", "tier", "mentioned_at"}).
+			AddRow("NEW", "whale1", 1, now).
+			AddRow("OLD", "whale1", 1, now.Add(-6*7*24*time.Hour)))
+
+	// Only the six week old shill loses its High Alpha rank, rescored again
+	// from its locked row
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT ticker_symbol, category, mindshare_score, last_mentioned_at .* FOR UPDATE").
+		WithArgs("OLD").
+		WillReturnRows(sqlmock.NewRows([]string{"ticker_symbol", "category", "mindshare_score", "last_mentioned_at"}).
+			AddRow("OLD", "High Alpha", 614.0, now.Add(-6*7*24*time.Hour)))
+	mock.ExpectQuery("SELECT author, tier, tweet_link").
+		WithArgs("OLD").
+		WillReturnRows(sqlmock.NewRows(mentionDetailColumns).
+			AddRow("whale1", 1, "", "", "", "tweet-1", now.Add(-6*7*24*time.Hour), 1))
+	mock.ExpectExec("UPDATE Tickers_1_0").
+		WithArgs(sqlmock.AnyArg(), "Trenches", "OLD").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	publisher := &recordingPublisher{}
+	s.SetEventPublisher(publisher)
+
+	updated, err := s.RescoreTickers(now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, updated)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, events.CategoryChanged, publisher.events[0].Type)
+	assert.Equal(t, "OLD", publisher.events[0].Ticker)
+	assert.Equal(t, "High Alpha", publisher.events[0].PreviousCategory)
+	assert.Equal(t, "Trenches", publisher.events[0].Category)
+}
+
+func TestRescoreTickersKeepsConcurrentMentions(t *testing.T) {
+	s, mock := newMockStorer(t)
+	defer s.db.Close()
+
+	previousHalfLife := mindshare.DecayHalfLife
+	mindshare.DecayHalfLife = 72 * time.Hour
+	defer func() { mindshare.DecayHalfLife = previousHalfLife }()
+
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	old := now.Add(-6 * 7 * 24 * time.Hour)
+	fresh, err := mindshare.CalculateMindshareAt(ticker.MentionDetails{Influencers: map[string]ticker.MentionDetail{
+		"whale1": {Tier: 1, MentionedAt: now},
+	}}, now)
+	require.NoError(t, err)
+
+	mock.ExpectQuery("SELECT ticker_symbol, category, mindshare_score FROM Tickers_1_0").
+		WillReturnRows(sqlmock.NewRows([]string{"ticker_symbol", "category", "mindshare_score"}).
+			AddRow("OLD", "High Alpha", 614.0))
+	mock.ExpectQuery("SELECT ticker, author, tier, mentioned_at").
+		WillReturnRows(sqlmock.NewRows([]string{"ticker", "author", "tier", "mentioned_at"}).
+			AddRow("OLD", "whale1", 1, old))
+
+	// A mention stored before the lock was taken already scored the ticker
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT ticker_symbol, category, mindshare_score, last_mentioned_at .* FOR UPDATE").
+		WithArgs("OLD").
+		WillReturnRows(sqlmock.NewRows([]string{"ticker_symbol", "category", "mindshare_score", "last_mentioned_at"}).
+			AddRow("OLD", fresh.Category, fresh.Score, now))
+	mock.ExpectQuery("SELECT author, tier, tweet_link").
+		WithArgs("OLD").
+		WillReturnRows(sqlmock.NewRows(mentionDetailColumns).
+			AddRow("whale1", 1, "", "", "", "tweet-2", now, 2))
+	mock.ExpectCommit()
+
+	publisher := &recordingPublisher{}
+	s.SetEventPublisher(publisher)
+
+	updated, err := s.RescoreTickers(now)
+	assert.NoError(t, err)
+	assert.Zero(t, updated)
+	assert.Empty(t, publisher.events)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAllMentionDetailsKeepsEveryMention(t *testing.T) {
+	s, mock := newMockStorer(t)
+	defer s.db.Close()
+
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT ticker, author, tier, mentioned_at").
+		WillReturnRows(sqlmock.NewRows([]string{"ticker", "author", "tier", "mentioned_at"}).
+			AddRow("AIXBT", "whale1", 1, now).
+			AddRow("AIXBT", "whale1", 1, now.Add(-time.Hour)).
+			AddRow("AIXBT", "degen", 3, now.Add(-2*time.Hour)))
+
+	mentions, err := s.getAllMentionDetails()
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// Every mention decays on its own, so none is dropped
+	influencers := mentions["AIXBT"].Influencers
+	require.Len(t, influencers, 2)
+	assert.Equal(t, now, influencers["whale1"].MentionedAt)
+	assert.Equal(t, []time.Time{now, now.Add(-time.Hour)}, influencers["whale1"].MentionTimes)
+	assert.Len(t, influencers["degen"].MentionTimes, 1)
+}
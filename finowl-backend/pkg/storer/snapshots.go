@@ -0,0 +1,119 @@
+package storer
+
+import (
+	"fmt"
+	"time"
+)
+
+// Snapshot bucket sizes of the ticker_snapshots table
+const (
+	SnapshotHourly = "hour"
+	SnapshotDaily  = "day"
+)
+
+// SnapshotHourlyRetention is how long hourly snapshots are kept. Daily ones
+// are kept forever.
+const SnapshotHourlyRetention = 90 * 24 * time.Hour
+
+// SnapshotTickers records the current score and category of the tickers in
+// the hourly and daily buckets containing now, along with the mentions of the
+// bucket so far. Only tickers mentioned during the bucket, or whose score or
+// category differ from their latest snapshot, are recorded: a bucket missing
+// from the history of a ticker holds no mention and the score of the bucket
+// before it. Later snapshots of the same bucket overwrite earlier ones.
+// The mention counts of the previous buckets are refreshed as well so that
+// mentions stored after their last snapshot are not lost. Hourly buckets
+// older than SnapshotHourlyRetention are deleted.
+func (s *Storer) SnapshotTickers(now time.Time) error {
+	now = now.UTC()
+
+	// Expired buckets go first: tickers whose last snapshot expires are
+	// snapshotted again, and so keep a score in the retained history
+	cutoff, _ := snapshotBucket(SnapshotHourly, now.Add(-SnapshotHourlyRetention))
+	if _, err := s.db.Exec(buildDeleteSnapshotsQuery(), SnapshotHourly, cutoff); err != nil {
+		return fmt.Errorf("failed to delete expired snapshots: %w", err)
+	}
+
+	for _, interval := range []string{SnapshotHourly, SnapshotDaily} {
+		start, end := snapshotBucket(interval, now)
+
+		if _, err := s.db.Exec(buildInsertSnapshotsQuery(), interval, start, end, now); err != nil {
+			return fmt.Errorf("failed to snapshot tickers (%s): %w", interval, err)
+		}
+
+		previousStart, _ := snapshotBucket(interval, start.Add(-time.Nanosecond))
+		if _, err := s.db.Exec(buildRefreshSnapshotCountsQuery(), interval, previousStart, start, now); err != nil {
+			return fmt.Errorf("failed to refresh previous snapshots (%s): %w", interval, err)
+		}
+	}
+
+	return nil
+}
+
+// snapshotBucket returns the [start, end) bounds of the bucket containing at
+func snapshotBucket(interval string, at time.Time) (time.Time, time.Time) {
+	if interval == SnapshotDaily {
+		start := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 0, 1)
+	}
+	start := at.Truncate(time.Hour)
+	return start, start.Add(time.Hour)
+}
+
+// buildInsertSnapshotsQuery constructs the SQL query upserting the snapshot of the tickers
+// mentioned or rescored during a bucket. Tickers already snapshotted in the bucket are kept up to date.
+func buildInsertSnapshotsQuery() string {
+	return `INSERT INTO ticker_snapshots (ticker, bucket_interval, bucket_start, mindshare_score, category,
+                                          mention_count, influencer_count, recorded_at)
+            SELECT t.ticker_symbol, $1, $2, t.mindshare_score, t.category,
+                   COUNT(m.id), COUNT(DISTINCT m.author), $4
+            FROM Tickers_1_0 t
+            LEFT JOIN ticker_mentions m
+                   ON m.ticker = t.ticker_symbol AND m.mentioned_at >= $2 AND m.mentioned_at < $3
+            LEFT JOIN LATERAL (
+                SELECT p.bucket_start, p.mindshare_score, p.category
+                FROM ticker_snapshots p
+                WHERE p.ticker = t.ticker_symbol AND p.bucket_interval = $1 AND p.bucket_start <= $2
+                ORDER BY p.bucket_start DESC
+                LIMIT 1
+            ) last ON TRUE
+            WHERE t.mindshare_score IS NOT NULL AND t.category IS NOT NULL
+            GROUP BY t.ticker_symbol, t.mindshare_score, t.category,
+                     last.bucket_start, last.mindshare_score, last.category
+            HAVING COUNT(m.id) > 0
+                OR last.bucket_start IS NULL
+                OR last.bucket_start = $2
+                OR (last.mindshare_score, last.category) IS DISTINCT FROM (t.mindshare_score, t.category)
+            ON CONFLICT (ticker, bucket_interval, bucket_start) DO UPDATE
+            SET mindshare_score = EXCLUDED.mindshare_score,
+                category = EXCLUDED.category,
+                mention_count = EXCLUDED.mention_count,
+                influencer_count = EXCLUDED.influencer_count,
+                recorded_at = EXCLUDED.recorded_at`
+}
+
+// buildRefreshSnapshotCountsQuery constructs the SQL query recounting the mentions of a closed bucket.
+// Tickers first mentioned in it after its last snapshot are recorded with their current score.
+func buildRefreshSnapshotCountsQuery() string {
+	return `INSERT INTO ticker_snapshots (ticker, bucket_interval, bucket_start, mindshare_score, category,
+                                          mention_count, influencer_count, recorded_at)
+            SELECT c.ticker, $1, $2, t.mindshare_score, t.category, c.mention_count, c.influencer_count, $4
+            FROM (
+                SELECT ticker, COUNT(*) AS mention_count, COUNT(DISTINCT author) AS influencer_count
+                FROM ticker_mentions
+                WHERE mentioned_at >= $2 AND mentioned_at < $3
+                GROUP BY ticker
+            ) c
+            JOIN Tickers_1_0 t ON t.ticker_symbol = c.ticker
+            WHERE t.mindshare_score IS NOT NULL AND t.category IS NOT NULL
+            ON CONFLICT (ticker, bucket_interval, bucket_start) DO UPDATE
+            SET mention_count = EXCLUDED.mention_count,
+                influencer_count = EXCLUDED.influencer_count
+            WHERE (ticker_snapshots.mention_count, ticker_snapshots.influencer_count)
+                  <> (EXCLUDED.mention_count, EXCLUDED.influencer_count)`
+}
+
+// buildDeleteSnapshotsQuery constructs the SQL query deleting the snapshots of an interval started before a time.
+func buildDeleteSnapshotsQuery() string {
+	return `DELETE FROM ticker_snapshots WHERE bucket_interval = $1 AND bucket_start < $2`
+}
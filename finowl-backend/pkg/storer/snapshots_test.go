@@ -0,0 +1,89 @@
+package storer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotTickers(t *testing.T) {
+	s, mock := newMockStorer(t)
+	defer s.db.Close()
+
+	now := time.Date(2025, 3, 1, 14, 35, 0, 0, time.UTC)
+	hour := time.Date(2025, 3, 1, 14, 0, 0, 0, time.UTC)
+	day := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec("DELETE FROM ticker_snapshots").
+		WithArgs(SnapshotHourly, hour.Add(-SnapshotHourlyRetention)).
+		WillReturnResult(sqlmock.NewResult(0, 40))
+	mock.ExpectExec("INSERT INTO ticker_snapshots .* HAVING COUNT\\(m.id\\) > 0").
+		WithArgs(SnapshotHourly, hour, hour.Add(time.Hour), now).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO ticker_snapshots .* FROM \\( SELECT ticker, COUNT").
+		WithArgs(SnapshotHourly, hour.Add(-time.Hour), hour, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO ticker_snapshots .* HAVING COUNT\\(m.id\\) > 0").
+		WithArgs(SnapshotDaily, day, day.AddDate(0, 0, 1), now).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO ticker_snapshots .* FROM \\( SELECT ticker, COUNT").
+		WithArgs(SnapshotDaily, day.AddDate(0, 0, -1), day, now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, s.SnapshotTickers(now))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSnapshotTickersPostgres(t *testing.T) {
+	s := newPostgresStorer(t)
+
+	start := time.Date(2025, 3, 1, 14, 0, 0, 0, time.UTC)
+	_, err := s.db.Exec(`INSERT INTO Tickers_1_0 (ticker_symbol, category, mindshare_score, last_mentioned_at, first_mentioned_at)
+		VALUES ('AIXBT', 'Trenches', 17.6, $1, $1), ('QUIET', 'Trenches', 5, $1, $1)`, start)
+	require.NoError(t, err)
+	_, err = s.db.Exec(`INSERT INTO ticker_mentions (ticker, author, tier, mentioned_at)
+		VALUES ('AIXBT', 'Stats', 3, $1)`, start.Add(10*time.Minute))
+	require.NoError(t, err)
+
+	hourly := func(ticker string) []time.Time {
+		rows, err := s.db.Query(`SELECT bucket_start FROM ticker_snapshots
+			WHERE ticker = $1 AND bucket_interval = 'hour' ORDER BY bucket_start`, ticker)
+		require.NoError(t, err)
+		defer rows.Close()
+		var buckets []time.Time
+		for rows.Next() {
+			var bucket time.Time
+			require.NoError(t, rows.Scan(&bucket))
+			buckets = append(buckets, bucket.UTC())
+		}
+		require.NoError(t, rows.Err())
+		return buckets
+	}
+
+	// Every ticker is recorded once, then only when mentioned or rescored
+	for i := 0; i < 3; i++ {
+		require.NoError(t, s.SnapshotTickers(start.Add(time.Duration(i)*time.Hour+30*time.Minute)))
+	}
+	assert.Equal(t, []time.Time{start}, hourly("AIXBT"))
+	assert.Equal(t, []time.Time{start}, hourly("QUIET"))
+
+	_, err = s.db.Exec(`UPDATE Tickers_1_0 SET mindshare_score = 4.2 WHERE ticker_symbol = 'QUIET'`)
+	require.NoError(t, err)
+	require.NoError(t, s.SnapshotTickers(start.Add(3*time.Hour+30*time.Minute)))
+	assert.Equal(t, []time.Time{start, start.Add(3 * time.Hour)}, hourly("QUIET"))
+	assert.Equal(t, []time.Time{start}, hourly("AIXBT"))
+
+	// Expired hourly buckets are deleted, daily ones kept, and quiet tickers
+	// snapshotted again
+	later := start.Add(SnapshotHourlyRetention + 4*time.Hour)
+	require.NoError(t, s.SnapshotTickers(later))
+	assert.Equal(t, []time.Time{later.Truncate(time.Hour)}, hourly("AIXBT"))
+
+	var daily int
+	require.NoError(t, s.db.QueryRow(`SELECT COUNT(*) FROM ticker_snapshots
+		WHERE ticker = 'AIXBT' AND bucket_interval = 'day'`).Scan(&daily))
+	assert.Equal(t, 1, daily)
+}
@@ -4,8 +4,11 @@ package storer
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"finowl-backend/pkg/analyzer"
+	"finowl-backend/pkg/events"
 	"finowl-backend/pkg/mindshare"
+	"finowl-backend/pkg/ticker"
 	"fmt"
 	"log"
 	"time"
@@ -17,15 +20,42 @@ import (
 type Tweet struct {
 	ID        string   `json:"id"`
 	Author    string   `json:"author"`
+	Handle    string   `json:"handle"` // Twitter handle, when the relay shows it
 	Timestamp string   `json:"timestamp"`
 	Content   string   `json:"content"`
 	Links     []string `json:"links"`
 	Tickers   []string `json:"tickers"`
+	Category  string   `json:"category"`
+	MessageID string   `json:"discord_message_id"` // Discord message the tweet was relayed in
+	Source    string   `json:"source"`             // Where the tweet came from, SourceDiscord when empty
 }
 
+// SourceDiscord is the source of the tweets relayed in the collected channels
+const SourceDiscord = "discord"
+
 // Storer handles database operations for tweets
 type Storer struct {
-	db *sql.DB
+	db     *sql.DB
+	events EventPublisher
+	alerts AlertEvaluator
+}
+
+// EventPublisher receives the ticker changes made by the storer
+type EventPublisher interface {
+	Publish(e events.Event)
+}
+
+// SetEventPublisher makes the storer publish an event for every stored
+// mention, created ticker and category change
+func (s *Storer) SetEventPublisher(publisher EventPublisher) {
+	s.events = publisher
+}
+
+// publish forwards an event to the publisher, if any
+func (s *Storer) publish(e events.Event) {
+	if s.events != nil {
+		s.events.Publish(e)
+	}
 }
 
 // NewStorer creates a new Storer instance and connects to the database
@@ -50,23 +80,113 @@ func (s *Storer) Close() {
 	}
 }
 
-// InsertTweet inserts a new tweet into the database
+var (
+	// ErrTweetExists is returned when inserting the tweet of a Discord message
+	// that was already stored, e.g. when backfilled messages overlap live ones
+	ErrTweetExists = errors.New("tweet already stored")
+
+	// ErrDuplicateTweet is returned when inserting a tweet another message
+	// already relayed. It wraps ErrTweetExists.
+	ErrDuplicateTweet = fmt.Errorf("%w from another message", ErrTweetExists)
+)
+
+// InsertTweet inserts a new tweet into the database. Tweets whose message or
+// dedup key was stored before are skipped with ErrTweetExists or ErrDuplicateTweet.
 func (s *Storer) InsertTweet(tweet Tweet) error {
+	return s.StoreTweet(tweet, nil)
+}
+
+// StoreTweet inserts a tweet along with its ticker mentions in a single
+// transaction, so that a tweet is never stored without its mentions. Like
+// InsertTweet it returns ErrTweetExists or ErrDuplicateTweet, without
+// recording any mention, when the tweet was stored before. The message of a
+// duplicate is still recorded as a relay of the stored tweet.
+func (s *Storer) StoreTweet(tweet Tweet, tickers []ticker.Ticker) error {
+	var duplicate error
+	err := s.inTx(func(tx *sql.Tx, fx *txEffects) error {
+		duplicate = nil
+		if err := insertTweet(tx, tweet); err != nil {
+			if errors.Is(err, ErrDuplicateTweet) {
+				duplicate = err
+				return nil
+			}
+			return err
+		}
+		return upsertTickers(tx, fx, tickers)
+	})
+	if err != nil {
+		return err
+	}
+	return duplicate
+}
+
+// insertTweet inserts a tweet and records its message, unless the message or
+// the dedup key was stored before
+func insertTweet(q querier, tweet Tweet) error {
 	query := buildInsertTweetQuery()
 	linksJSON, _ := json.Marshal(tweet.Links)
 	tickersJSON, _ := json.Marshal(tweet.Tickers)
-	_, err := s.db.Exec(query, tweet.ID, tweet.Author, tweet.Timestamp, tweet.Content, linksJSON, tickersJSON)
+	source := tweet.Source
+	if source == "" {
+		source = SourceDiscord
+	}
+	key := dedupKey(tweet)
+	result, err := q.Exec(query, tweet.ID, tweet.Author, tweet.Timestamp, tweet.Content, linksJSON, tickersJSON, tweet.Category, nullIfEmpty(tweet.MessageID), tweet.Handle, source, key)
 	if err != nil {
 		return fmt.Errorf("failed to insert tweet: %w", err)
 	}
+
+	if inserted, err := result.RowsAffected(); err == nil && inserted == 0 {
+		return insertConflict(q, tweet, key)
+	}
+
+	if tweet.MessageID != "" {
+		if _, err := q.Exec(buildInsertTweetMessageQuery(), tweet.MessageID, tweet.ID); err != nil {
+			return fmt.Errorf("failed to record message %s: %w", tweet.MessageID, err)
+		}
+	}
 	return nil
 }
 
-// buildInsertTweetQuery constructs the SQL query for inserting a tweet.
+// insertConflict tells why a tweet was not inserted: its message was stored
+// before, or another message relayed the same tweet. The message of the
+// latter is recorded as a relay of the stored tweet.
+func insertConflict(q querier, tweet Tweet, key string) error {
+	if tweet.MessageID != "" {
+		var seen bool
+		if err := q.QueryRow(`SELECT EXISTS (SELECT 1 FROM tweet_messages WHERE discord_message_id = $1)`, tweet.MessageID).Scan(&seen); err != nil {
+			return fmt.Errorf("failed to check stored message %s: %w", tweet.MessageID, err)
+		}
+		if seen {
+			return fmt.Errorf("%w for message %s", ErrTweetExists, tweet.MessageID)
+		}
+
+		if _, err := q.Exec(buildInsertRelayMessageQuery(), tweet.MessageID, key); err != nil {
+			return fmt.Errorf("failed to record message %s: %w", tweet.MessageID, err)
+		}
+	}
+	return fmt.Errorf("%w (%s)", ErrDuplicateTweet, key)
+}
+
+// buildInsertTweetQuery constructs the SQL query for inserting a tweet. A
+// conflict on the message ID or on the dedup key skips the tweet.
 func buildInsertTweetQuery() string {
 	return `
-		INSERT INTO tweets (id, author, timestamp, content, links, tickers)
-		VALUES ($1, $2, $3, $4, $5, $6)`
+		INSERT INTO tweets (id, author, timestamp, content, links, tickers, category, discord_message_id, handle, source, dedup_key)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT DO NOTHING`
+}
+
+// buildInsertTweetMessageQuery constructs the SQL query recording the message a tweet was stored from.
+func buildInsertTweetMessageQuery() string {
+	return `INSERT INTO tweet_messages (discord_message_id, tweet_id) VALUES ($1, $2)`
+}
+
+// buildInsertRelayMessageQuery constructs the SQL query recording a message relaying the tweet of a dedup key.
+func buildInsertRelayMessageQuery() string {
+	return `INSERT INTO tweet_messages (discord_message_id, tweet_id)
+            SELECT $1, id FROM tweets WHERE dedup_key = $2
+            ON CONFLICT DO NOTHING`
 }
 
 func (s *Storer) DB() *sql.DB {
@@ -78,6 +198,7 @@ func TransformToStorerTweet(input analyzer.Tweet) Tweet {
 	return Tweet{
 		ID:        input.ID,
 		Author:    input.Author,
+		Handle:    input.Handle,
 		Timestamp: input.Timestamp.Format(time.RFC3339), // Convert to ISO 8601 format
 		Content:   input.Content,
 		Links:     input.Links,
@@ -85,73 +206,48 @@ func TransformToStorerTweet(input analyzer.Tweet) Tweet {
 	}
 }
 
-// CreateTables handles the creation of necessary tables in the database.
-func CreateTables(storer *Storer) error {
-	if err := createTweetsTable(storer); err != nil {
-		return err
-	}
-	if err := createTickersTable(storer); err != nil {
-		return err
-	}
-	if err := createSummariesTable(storer); err != nil {
-		return err
+// InsertSummary inserts a new summary and its parsed ticker bullets into the database
+func (s *Storer) InsertSummary(summary *mindshare.Summary) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to insert summary: %w", err)
 	}
-	return nil
-}
+	defer tx.Rollback()
 
-// createTweetsTable creates the 'tweets' table if it doesn't exist.
-func createTweetsTable(storer *Storer) error {
-	_, err := storer.db.Exec(`
-		CREATE TABLE IF NOT EXISTS tweets (
-			id UUID PRIMARY KEY,
-			author VARCHAR(255),
-			timestamp TIMESTAMP,
-			content TEXT,
-			links JSONB,
-			tickers JSONB
-		)`)
-	if err != nil {
-		return fmt.Errorf("failed to create tweets table: %w", err)
+	var featuredTickers, keyInsights, marketSentiment interface{}
+	if summary.Sections != nil {
+		featuredTickers = summary.Sections.FeaturedTickers
+		keyInsights = summary.Sections.KeyInsights
+		marketSentiment = summary.Sections.MarketSentiment
 	}
-	return nil
-}
 
-// createTickersTable creates the 'Tickers' table if it doesn't exist.
-func createTickersTable(storer *Storer) error {
-	_, err := storer.db.Exec(`
-		CREATE TABLE IF NOT EXISTS Tickers_1_0 (
-			ticker_symbol VARCHAR(10) PRIMARY KEY,
-			category VARCHAR(20) CHECK (category IN ('High Alpha', 'Alpha', 'Trenches')),
-			mindshare_score DECIMAL(10,2),
-			last_mentioned_at TIMESTAMP,
-			first_mentioned_at TIMESTAMP,
-			mention_details JSONB
-		)`)
+	query := buildInsertSummaryQuery()
+	err = tx.QueryRow(query,
+		summary.Time,
+		summary.Content,
+		summary.Category,
+		nullIfEmpty(summary.FirstTweetID),
+		nullIfEmpty(summary.LastTweetID),
+		summary.TweetCount,
+		summary.WindowStart,
+		summary.WindowEnd,
+		featuredTickers,
+		keyInsights,
+		marketSentiment,
+	).Scan(&summary.ID)
 	if err != nil {
-		return fmt.Errorf("failed to create Tickers table: %w", err)
+		return fmt.Errorf("failed to insert summary: %w", err)
 	}
-	return nil
-}
 
-// createSummariesTable creates the 'Summaries' table if it doesn't exist.
-func createSummariesTable(storer *Storer) error {
-	_, err := storer.db.Exec(`
-		CREATE TABLE IF NOT EXISTS Summaries (
-			id SERIAL PRIMARY KEY,
-			timestamp TIMESTAMP NOT NULL,
-			content TEXT NOT NULL
-		)`)
-	if err != nil {
-		return fmt.Errorf("failed to create Summaries table: %w", err)
+	if summary.Sections != nil {
+		for i, t := range summary.Sections.Tickers {
+			if _, err := tx.Exec(buildInsertSummaryTickerQuery(), summary.ID, i, t.TickerSymbol, t.ProjectName, t.Description); err != nil {
+				return fmt.Errorf("failed to insert summary ticker %s: %w", t.TickerSymbol, err)
+			}
+		}
 	}
-	return nil
-}
 
-// InsertSummary inserts a new summary into the database
-func (s *Storer) InsertSummary(summary *mindshare.Summary) error {
-	query := buildInsertSummaryQuery()
-	_, err := s.db.Exec(query, summary.Time, summary.Content)
-	if err != nil {
+	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to insert summary: %w", err)
 	}
 	return nil
@@ -160,6 +256,23 @@ func (s *Storer) InsertSummary(summary *mindshare.Summary) error {
 // buildInsertSummaryQuery constructs the SQL query for inserting a summary.
 func buildInsertSummaryQuery() string {
 	return `
-        INSERT INTO Summaries (timestamp, content)
-        VALUES ($1, $2)`
+        INSERT INTO Summaries (timestamp, content, category, first_tweet_id, last_tweet_id, tweet_count, window_start, window_end,
+                               featured_tickers, key_insights, market_sentiment)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+        RETURNING id`
+}
+
+// buildInsertSummaryTickerQuery constructs the SQL query for inserting a parsed summary ticker.
+func buildInsertSummaryTickerQuery() string {
+	return `
+        INSERT INTO summary_tickers (summary_id, position, ticker_symbol, project_name, description)
+        VALUES ($1, $2, $3, $4, $5)`
+}
+
+// nullIfEmpty maps an empty string to a SQL NULL
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
 }
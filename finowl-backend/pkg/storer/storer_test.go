@@ -0,0 +1,85 @@
+package storer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"finowl-backend/pkg/mindshare"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertSummary(t *testing.T) {
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	windowStart := at.Add(-time.Hour)
+
+	t.Run("stores the summary and its tickers", func(t *testing.T) {
+		s, mock := newMockStorer(t)
+		defer s.db.Close()
+
+		summary := &mindshare.Summary{
+			Time: at, Content: "summary", Category: "MacroNews",
+			FirstTweetID: "a", LastTweetID: "c", TweetCount: 3,
+			WindowStart: &windowStart, WindowEnd: &at,
+			Sections: &mindshare.SummarySections{
+				FeaturedTickers: "featured", KeyInsights: "insights", MarketSentiment: "sentiment",
+				Tickers: []mindshare.SummaryTicker{
+					{TickerSymbol: "ABC", ProjectName: "Alphabet", Description: "trending"},
+					{TickerSymbol: "XYZ", ProjectName: "Xyz", Description: "quiet"},
+				},
+			},
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT INTO Summaries").
+			WithArgs(at, "summary", "MacroNews", "a", "c", 3, windowStart, at, "featured", "insights", "sentiment").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+		mock.ExpectExec("INSERT INTO summary_tickers").
+			WithArgs(7, 0, "ABC", "Alphabet", "trending").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO summary_tickers").
+			WithArgs(7, 1, "XYZ", "Xyz", "quiet").
+			WillReturnResult(sqlmock.NewResult(2, 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, s.InsertSummary(summary))
+		assert.Equal(t, 7, summary.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("stores unparsed summaries without sections", func(t *testing.T) {
+		s, mock := newMockStorer(t)
+		defer s.db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT INTO Summaries").
+			WithArgs(at, "raw", "", nil, nil, 0, nil, nil, nil, nil, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(8))
+		mock.ExpectCommit()
+
+		require.NoError(t, s.InsertSummary(&mindshare.Summary{Time: at, Content: "raw"}))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("keeps no summary without its tickers", func(t *testing.T) {
+		s, mock := newMockStorer(t)
+		defer s.db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT INTO Summaries").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+		mock.ExpectExec("INSERT INTO summary_tickers").
+			WillReturnError(errors.New("connection reset"))
+		mock.ExpectRollback()
+
+		err := s.InsertSummary(&mindshare.Summary{
+			Time: at, Content: "summary",
+			Sections: &mindshare.SummarySections{Tickers: []mindshare.SummaryTicker{{TickerSymbol: "ABC"}}},
+		})
+		assert.ErrorContains(t, err, "failed to insert summary ticker ABC")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
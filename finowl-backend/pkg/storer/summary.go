@@ -1,13 +1,21 @@
 package storer
 
 import (
+	"database/sql"
+	"errors"
 	"finowl-backend/pkg/mindshare"
 	"fmt"
 )
 
+// ErrSummaryNotFound is returned when the requested summary does not exist
+var ErrSummaryNotFound = errors.New("no summary found")
+
 // GetAllSummaries retrieves all summaries from the database
 func (s *Storer) GetAllSummaries() ([]mindshare.Summary, error) {
-	query := `SELECT id, timestamp, content FROM Summaries`
+	query := `SELECT id, timestamp, content, category,
+                     COALESCE(first_tweet_id::text, ''), COALESCE(last_tweet_id::text, ''),
+                     tweet_count, window_start, window_end
+              FROM Summaries`
 	rows, err := s.db.Query(query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve summaries: %w", err)
@@ -17,7 +25,11 @@ func (s *Storer) GetAllSummaries() ([]mindshare.Summary, error) {
 	var summaries []mindshare.Summary
 	for rows.Next() {
 		var summary mindshare.Summary
-		if err := rows.Scan(&summary.ID, &summary.Time, &summary.Content); err != nil {
+		if err := rows.Scan(
+			&summary.ID, &summary.Time, &summary.Content, &summary.Category,
+			&summary.FirstTweetID, &summary.LastTweetID,
+			&summary.TweetCount, &summary.WindowStart, &summary.WindowEnd,
+		); err != nil {
 			return nil, err
 		}
 		summaries = append(summaries, summary)
@@ -29,3 +41,40 @@ func (s *Storer) GetAllSummaries() ([]mindshare.Summary, error) {
 
 	return summaries, nil
 }
+
+// GetLatestSummary returns the most recent summary, of any category when category is empty
+func (s *Storer) GetLatestSummary(category string) (*mindshare.Summary, error) {
+	return s.getSummary(`SELECT id, timestamp, content, category,
+                                COALESCE(first_tweet_id::text, ''), COALESCE(last_tweet_id::text, ''),
+                                tweet_count, window_start, window_end
+                         FROM Summaries
+                         WHERE ($1 = '' OR category = $1)
+                         ORDER BY timestamp DESC
+                         LIMIT 1`, category)
+}
+
+// GetSummary returns the summary with the given ID
+func (s *Storer) GetSummary(id int) (*mindshare.Summary, error) {
+	return s.getSummary(`SELECT id, timestamp, content, category,
+                                COALESCE(first_tweet_id::text, ''), COALESCE(last_tweet_id::text, ''),
+                                tweet_count, window_start, window_end
+                         FROM Summaries
+                         WHERE id = $1`, id)
+}
+
+// getSummary runs a query returning at most one summary row
+func (s *Storer) getSummary(query string, args ...any) (*mindshare.Summary, error) {
+	var summary mindshare.Summary
+	err := s.db.QueryRow(query, args...).Scan(
+		&summary.ID, &summary.Time, &summary.Content, &summary.Category,
+		&summary.FirstTweetID, &summary.LastTweetID,
+		&summary.TweetCount, &summary.WindowStart, &summary.WindowEnd,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSummaryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve summary: %w", err)
+	}
+	return &summary, nil
+}
@@ -3,136 +3,206 @@ package storer
 
 import (
 	"database/sql"
-	"encoding/json"
+	"errors"
+	"finowl-backend/pkg/alerts"
+	"finowl-backend/pkg/events"
 	"finowl-backend/pkg/mindshare"
 	"finowl-backend/pkg/ticker"
 	"fmt"
-	"log"
+	"slices"
+	"strings"
+	"time"
 
 	_ "github.com/lib/pq" // Import the PostgreSQL driver
 )
 
-// InsertTickersBatch processes a batch of tickers, inserting or updating each one into the database.
-// It will call InsertTicker for each ticker and handle errors appropriately.
+// ErrTickerNotFound is returned when looking up a ticker that was never mentioned
+var ErrTickerNotFound = errors.New("no ticker found")
+
+// InsertTickersBatch inserts or updates every ticker of a batch in a single
+// transaction: either all of their mentions are recorded or none is.
 func (s *Storer) InsertTickersBatch(tickers []ticker.Ticker) error {
 	// Early exit if no tickers are provided
 	if len(tickers) == 0 {
 		return fmt.Errorf("no tickers provided for batch insert")
 	}
 
-	// Iterate over each ticker in the batch and process it individually
-	for i, ticker := range tickers {
-		// Call InsertTicker for each ticker and handle errors
-		if err := s.InsertTicker(ticker); err != nil {
-			// Log error with context for better troubleshooting
-			log.Printf("Error inserting/updating ticker at index %d (Ticker Symbol: %s): %v", i, ticker.TickerSymbol, err)
-			// Return the error to stop further processing
-			return fmt.Errorf("failed to insert/update ticker at index %d (Ticker Symbol: %s): %w", i, ticker.TickerSymbol, err)
+	return s.inTx(func(tx *sql.Tx, fx *txEffects) error {
+		return upsertTickers(tx, fx, tickers)
+	})
+}
+
+// InsertTicker inserts or updates a single ticker. Every mention is recorded
+// in ticker_mentions and the ticker is rescored from them.
+func (s *Storer) InsertTicker(t ticker.Ticker) error {
+	return s.inTx(func(tx *sql.Tx, fx *txEffects) error {
+		return upsertTicker(tx, fx, t)
+	})
+}
+
+// upsertTickers records the tickers in symbol order, so that transactions
+// mentioning the same tickers lock their rows in the same order and cannot
+// deadlock each other
+func upsertTickers(q querier, fx *txEffects, tickers []ticker.Ticker) error {
+	sorted := slices.Clone(tickers)
+	slices.SortFunc(sorted, func(a, b ticker.Ticker) int {
+		return strings.Compare(a.TickerSymbol, b.TickerSymbol)
+	})
+
+	for _, t := range sorted {
+		if err := upsertTicker(q, fx, t); err != nil {
+			return fmt.Errorf("failed to insert/update ticker %s: %w", t.TickerSymbol, err)
 		}
 	}
-
-	// Successfully processed all tickers
 	return nil
 }
 
-// InsertTicker handles the main flow of ticker insertion/update
-func (s *Storer) InsertTicker(ticker ticker.Ticker) error {
-	existing, err := s.getExistingTicker(ticker.TickerSymbol)
-	if err != nil && err != sql.ErrNoRows {
-		return fmt.Errorf("failed to check existing ticker: %w", err)
+// upsertTicker creates a ticker on its first mention, or locks its row and
+// rescores it from all of its mentions. Concurrent mentions of the same
+// ticker wait for each other instead of racing on its primary key.
+func upsertTicker(q querier, fx *txEffects, t ticker.Ticker) error {
+	created, err := createNewTicker(q, t)
+	if err != nil {
+		return fmt.Errorf("failed to create ticker: %w", err)
+	}
+
+	if created {
+		mentions, err := insertMentions(q, t)
+		if err != nil {
+			return err
+		}
+
+		fx.publish(events.Event{Type: events.TickerCreated, Ticker: t.TickerSymbol, Category: t.Category, MindshareScore: t.MindshareScore})
+		publishMentions(fx, t.TickerSymbol, t.Category, t.MindshareScore, mentions)
+		fx.evaluateAlerts(alerts.Change{
+			Ticker:      t.TickerSymbol,
+			Created:     true,
+			NewScore:    t.MindshareScore,
+			NewCategory: t.Category,
+			Mentions:    mentions,
+			At:          time.Now().UTC(),
+		})
+		return nil
+	}
+
+	existing, err := lockTicker(q, t.TickerSymbol)
+	if err != nil {
+		return fmt.Errorf("failed to lock existing ticker: %w", err)
 	}
 
-	if err == sql.ErrNoRows {
-		return s.createNewTicker(ticker)
+	mentions, err := insertMentions(q, t)
+	if err != nil {
+		return err
 	}
 
-	return s.updateExistingTicker(existing, ticker)
+	mindShare, err := updateExistingTicker(q, existing, t)
+	if err != nil {
+		return err
+	}
+
+	publishMentions(fx, t.TickerSymbol, mindShare.Category, mindShare.Score, mentions)
+	if mindShare.Category != existing.Category {
+		fx.publish(events.Event{
+			Type:             events.CategoryChanged,
+			Ticker:           t.TickerSymbol,
+			Category:         mindShare.Category,
+			PreviousCategory: existing.Category,
+			MindshareScore:   mindShare.Score,
+		})
+	}
+
+	// Both scores are known here, which the score based rules need
+	fx.evaluateAlerts(alerts.Change{
+		Ticker:      t.TickerSymbol,
+		OldScore:    existing.MindshareScore,
+		NewScore:    mindShare.Score,
+		OldCategory: existing.Category,
+		NewCategory: mindShare.Category,
+		Mentions:    mentions,
+		At:          time.Now().UTC(),
+	})
+	return nil
 }
 
-// getExistingTicker retrieves an existing ticker from the database
-func (s *Storer) getExistingTicker(symbol string) (*ticker.Ticker, error) {
+// publishMentions publishes an event for every newly stored mention of a ticker
+func publishMentions(fx *txEffects, symbol, category string, score float64, mentions []ticker.Mention) {
+	for i := range mentions {
+		fx.publish(events.Event{
+			Type:           events.MentionStored,
+			Ticker:         symbol,
+			Time:           mentions[i].MentionedAt,
+			Category:       category,
+			MindshareScore: score,
+			Mention:        &mentions[i],
+		})
+	}
+}
+
+// lockTicker retrieves an existing ticker and locks its row until the end of the transaction
+func lockTicker(q querier, symbol string) (*ticker.Ticker, error) {
 	var ticker ticker.Ticker
-	var mentionDetailsString string
 
-	query := buildGetExistingTickerQuery()
-	err := s.db.QueryRow(query, symbol).Scan(
+	query := buildLockTickerQuery()
+	err := q.QueryRow(query, symbol).Scan(
 		&ticker.TickerSymbol,
 		&ticker.Category,
 		&ticker.MindshareScore,
 		&ticker.LastMentionedAt,
-		&mentionDetailsString,
 	)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if err := json.Unmarshal([]byte(mentionDetailsString), &ticker.MentionDetails); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal mention details: %w", err)
-	}
-
 	return &ticker, nil
 }
 
-// createNewTicker inserts a new ticker into the database
-func (s *Storer) createNewTicker(ticker ticker.Ticker) error {
-	mentionDetailsJSON, err := json.Marshal(ticker.MentionDetails)
-	if err != nil {
-		return fmt.Errorf("failed to marshal mention details: %w", err)
-	}
-
+// createNewTicker inserts a ticker unless it exists and reports whether it was created
+func createNewTicker(q querier, ticker ticker.Ticker) (bool, error) {
 	query := buildInsertNewTickerQuery()
-	_, err = s.db.Exec(query,
+	result, err := q.Exec(query,
 		ticker.TickerSymbol,
 		ticker.Category,
 		ticker.MindshareScore,
 		ticker.LastMentionedAt,
 		ticker.FirstMentionedAt,
-		mentionDetailsJSON,
 	)
-
-	return err
-}
-
-// TotalTierCounts represents the total number of influencers per tier
-var TotalTierCounts = map[int]int{
-	1: 5,  // CZ, Sreeram, Balaji, cygaar, YQ
-	2: 15, // Andrew Kang, overdose, crash, Bull.BnB, etc.
-	3: 33, // All tier 3 influencers
-}
-
-// updateExistingTicker updates an existing ticker with new information
-func (s *Storer) updateExistingTicker(existing *ticker.Ticker, newTicker ticker.Ticker) error {
-	// Merge mention details
-	for influencer, detail := range newTicker.MentionDetails.Influencers {
-		existing.MentionDetails.Influencers[influencer] = detail
+	if err != nil {
+		return false, err
 	}
 
-	mindShare, err := mindshare.CalculateMindshare(existing.MentionDetails, newTicker.MentionDetails)
+	inserted, err := result.RowsAffected()
 	if err != nil {
-		fmt.Printf("failed to calculate mindShare: %v", err)
-		return nil
+		return false, err
 	}
+	return inserted > 0, nil
+}
 
-	// Here we'll add the mindshare calculation later
-	// existing.MindshareScore = mindshare.Calculate(existing.MentionDetails)
-	// existing.Category = mindshare.DetermineCategory(existing.MindshareScore)
+// updateExistingTicker rescores an existing ticker from all of its recorded mentions
+// and returns its new mindshare
+func updateExistingTicker(q querier, existing *ticker.Ticker, newTicker ticker.Ticker) (*mindshare.Mindshare, error) {
+	mentionDetails, err := getMentionDetails(q, existing.TickerSymbol)
+	if err != nil {
+		return nil, err
+	}
 
-	mentionDetailsJSON, err := json.Marshal(existing.MentionDetails)
+	mindShare, err := mindshare.CalculateMindshare(mentionDetails, ticker.MentionDetails{})
 	if err != nil {
-		return fmt.Errorf("failed to marshal updated mention details: %w", err)
+		return nil, fmt.Errorf("failed to calculate mindshare of %s: %w", existing.TickerSymbol, err)
 	}
 
 	query := buildUpdateExistingTickerQuery()
-	_, err = s.db.Exec(query,
+	_, err = q.Exec(query,
 		newTicker.LastMentionedAt,
-		mentionDetailsJSON,
 		mindShare.Score,    // Updated score
 		mindShare.Category, // Updated category
 		existing.TickerSymbol,
 	)
+	if err != nil {
+		return nil, err
+	}
 
-	return err
+	return mindShare, nil
 }
 
 // GetTicker retrieves a ticker from the database based on its symbol
@@ -141,46 +211,100 @@ func (s *Storer) GetTicker(tickerSymbol string) (*ticker.Ticker, error) {
 	row := s.db.QueryRow(query, tickerSymbol)
 
 	var ticker ticker.Ticker
-	var mentionDetailsJSON string
 
-	if err := row.Scan(&ticker.TickerSymbol, &ticker.Category, &ticker.MindshareScore, &ticker.LastMentionedAt, &mentionDetailsJSON); err != nil {
+	if err := row.Scan(&ticker.TickerSymbol, &ticker.Category, &ticker.MindshareScore, &ticker.LastMentionedAt, &ticker.FirstMentionedAt); err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("no ticker found with symbol: %s", tickerSymbol)
+			return nil, fmt.Errorf("%w with symbol: %s", ErrTickerNotFound, tickerSymbol)
 		}
 		return nil, fmt.Errorf("failed to retrieve ticker: %w", err)
 	}
 
-	// Unmarshal the JSONB field into the MentionDetails struct
-	if err := json.Unmarshal([]byte(mentionDetailsJSON), &ticker.MentionDetails); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal mention details: %w", err)
+	mentionDetails, err := getMentionDetails(s.db, tickerSymbol)
+	if err != nil {
+		return nil, err
 	}
+	ticker.MentionDetails = mentionDetails
 
 	return &ticker, nil
 }
 
-// buildGetExistingTickerQuery constructs the SQL query for retrieving an existing ticker.
-func buildGetExistingTickerQuery() string {
-	return `SELECT ticker_symbol, category, mindshare_score, last_mentioned_at, mention_details 
-            FROM Tickers_1_0 WHERE ticker_symbol = $1`
+// GetTopTickers returns the tickers with the highest mindshare score. A non
+// empty category only keeps tickers mentioned in that channel category.
+func (s *Storer) GetTopTickers(category string, limit int) ([]ticker.Ticker, error) {
+	return s.queryTickers(buildGetTopTickersQuery(), category, limit)
+}
+
+// GetFreshTickers returns the tickers first mentioned since the given time, highest score first
+func (s *Storer) GetFreshTickers(since time.Time, limit int) ([]ticker.Ticker, error) {
+	return s.queryTickers(buildGetFreshTickersQuery(), since, limit)
+}
+
+// queryTickers runs a query returning ticker rows, without their mention details
+func (s *Storer) queryTickers(query string, args ...any) ([]ticker.Ticker, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve tickers: %w", err)
+	}
+	defer rows.Close()
+
+	var tickers []ticker.Ticker
+	for rows.Next() {
+		var t ticker.Ticker
+		if err := rows.Scan(&t.TickerSymbol, &t.Category, &t.MindshareScore, &t.LastMentionedAt, &t.FirstMentionedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan ticker: %w", err)
+		}
+		tickers = append(tickers, t)
+	}
+
+	return tickers, rows.Err()
+}
+
+// buildLockTickerQuery constructs the SQL query for retrieving and locking an existing ticker.
+func buildLockTickerQuery() string {
+	return `SELECT ticker_symbol, category, mindshare_score, last_mentioned_at
+            FROM Tickers_1_0 WHERE ticker_symbol = $1
+            FOR UPDATE`
 }
 
 // buildInsertNewTickerQuery constructs the SQL query for inserting a new ticker.
+// A ticker created meanwhile by another transaction is left untouched.
 func buildInsertNewTickerQuery() string {
-	return `INSERT INTO Tickers_1_0 (ticker_symbol, category, mindshare_score, last_mentioned_at, first_mentioned_at, mention_details)
-            VALUES ($1, $2, $3, $4, $5, $6)`
+	return `INSERT INTO Tickers_1_0 (ticker_symbol, category, mindshare_score, last_mentioned_at, first_mentioned_at)
+            VALUES ($1, $2, $3, $4, $5)
+            ON CONFLICT (ticker_symbol) DO NOTHING`
 }
 
 // buildUpdateExistingTickerQuery constructs the SQL query for updating an existing ticker.
 func buildUpdateExistingTickerQuery() string {
 	return `UPDATE Tickers_1_0
-            SET last_mentioned_at = $1, 
-                mention_details = $2,
-                mindshare_score = $3,
-                category = $4
-            WHERE ticker_symbol = $5`
+            SET last_mentioned_at = GREATEST(last_mentioned_at, $1),
+                first_mentioned_at = LEAST(first_mentioned_at, $1),
+                mindshare_score = $2,
+                category = $3
+            WHERE ticker_symbol = $4`
 }
 
 // buildGetTickerQuery constructs the SQL query for retrieving a ticker.
 func buildGetTickerQuery() string {
-	return `SELECT ticker_symbol, category, mindshare_score, last_mentioned_at, mention_details FROM Tickers_1_0 WHERE ticker_symbol = $1`
+	return `SELECT ticker_symbol, category, mindshare_score, last_mentioned_at, first_mentioned_at FROM Tickers_1_0 WHERE ticker_symbol = $1`
+}
+
+// buildGetTopTickersQuery constructs the SQL query for retrieving the highest scored tickers.
+func buildGetTopTickersQuery() string {
+	return `SELECT ticker_symbol, category, mindshare_score, last_mentioned_at, first_mentioned_at
+            FROM Tickers_1_0
+            WHERE ($1 = '' OR EXISTS (
+                SELECT 1 FROM ticker_mentions
+                WHERE ticker = Tickers_1_0.ticker_symbol AND category = $1))
+            ORDER BY mindshare_score DESC
+            LIMIT $2`
+}
+
+// buildGetFreshTickersQuery constructs the SQL query for retrieving recently discovered tickers.
+func buildGetFreshTickersQuery() string {
+	return `SELECT ticker_symbol, category, mindshare_score, last_mentioned_at, first_mentioned_at
+            FROM Tickers_1_0
+            WHERE first_mentioned_at >= $1
+            ORDER BY mindshare_score DESC
+            LIMIT $2`
 }
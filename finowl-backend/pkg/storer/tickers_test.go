@@ -0,0 +1,199 @@
+package storer
+
+import (
+	"database/sql"
+	"log"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"finowl-backend/pkg/events"
+	"finowl-backend/pkg/mindshare"
+	"finowl-backend/pkg/ticker"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	// Score with the default model and the tiers of influencers.yaml
+	if err := mindshare.Configure(mindshare.DefaultScoringConfig(), map[int]int{1: 5, 2: 15, 3: 33}); err != nil {
+		log.Fatalf("failed to configure scoring: %v", err)
+	}
+	os.Exit(m.Run())
+}
+
+func newMockStorer(t *testing.T) (*Storer, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	return &Storer{db: db}, mock
+}
+
+// recordingPublisher keeps every event published by the storer
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []events.Event
+}
+
+func (p *recordingPublisher) Publish(e events.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, e)
+}
+
+func sampleMentionTicker(author, tweetID string, at time.Time) ticker.Ticker {
+	return ticker.Ticker{
+		TickerSymbol:     "AIXBT",
+		Category:         "Trenches",
+		MindshareScore:   17.6,
+		LastMentionedAt:  at,
+		FirstMentionedAt: at,
+		MentionDetails: ticker.MentionDetails{
+			Influencers: map[string]ticker.MentionDetail{
+				author: {Tier: 3, TweetID: tweetID, MentionedAt: at, MentionCount: 1},
+			},
+		},
+	}
+}
+
+func TestInsertTickerNew(t *testing.T) {
+	s, mock := newMockStorer(t)
+	defer s.db.Close()
+
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tk := sampleMentionTicker("Stats", "e33fbc74-74e8-447b-9c0b-d02771ff7495", at)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO Tickers_1_0 .* ON CONFLICT \\(ticker_symbol\\) DO NOTHING").
+		WithArgs("AIXBT", "Trenches", 17.6, at, at).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO ticker_mentions").
+		WithArgs("AIXBT", "e33fbc74-74e8-447b-9c0b-d02771ff7495", "Stats", 3, at, "", "", "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	publisher := &recordingPublisher{}
+	s.SetEventPublisher(publisher)
+
+	assert.NoError(t, s.InsertTicker(tk))
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	require.Len(t, publisher.events, 2)
+	assert.Equal(t, events.TickerCreated, publisher.events[0].Type)
+	assert.Equal(t, events.MentionStored, publisher.events[1].Type)
+	assert.Equal(t, "Stats", publisher.events[1].Mention.Author)
+	assert.Equal(t, at, publisher.events[1].Time)
+}
+
+func TestInsertTickerDuplicateMentionPublishesNothing(t *testing.T) {
+	s, mock := newMockStorer(t)
+	defer s.db.Close()
+
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tk := sampleMentionTicker("Stats", "e33fbc74-74e8-447b-9c0b-d02771ff7495", at)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO Tickers_1_0").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT ticker_symbol, category, mindshare_score, last_mentioned_at FROM Tickers_1_0 .* FOR UPDATE").
+		WithArgs("AIXBT").
+		WillReturnRows(sqlmock.NewRows([]string{"ticker_symbol", "category", "mindshare_score", "last_mentioned_at"}).
+			AddRow("AIXBT", "Trenches", 17.6, at))
+
+	// The tweet was already recorded
+	mock.ExpectExec("INSERT INTO ticker_mentions").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectQuery("SELECT author, tier, tweet_link").
+		WithArgs("AIXBT").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"author", "tier", "tweet_link", "content", "category", "tweet_id", "mentioned_at", "count",
+		}).AddRow("Stats", 3, "", "", "", "e33fbc74-74e8-447b-9c0b-d02771ff7495", at, 1))
+
+	mock.ExpectExec("UPDATE Tickers_1_0").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	publisher := &recordingPublisher{}
+	s.SetEventPublisher(publisher)
+
+	assert.NoError(t, s.InsertTicker(tk))
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.Empty(t, publisher.events)
+}
+
+func TestInsertTickerRepeatMention(t *testing.T) {
+	s, mock := newMockStorer(t)
+	defer s.db.Close()
+
+	first := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	second := first.Add(6 * time.Hour)
+	tk := sampleMentionTicker("Stats", "0b5c1f9e-6f57-4d43-9d36-43c1d1f2c0aa", second)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO Tickers_1_0").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT ticker_symbol, category, mindshare_score, last_mentioned_at FROM Tickers_1_0 .* FOR UPDATE").
+		WithArgs("AIXBT").
+		WillReturnRows(sqlmock.NewRows([]string{"ticker_symbol", "category", "mindshare_score", "last_mentioned_at"}).
+			AddRow("AIXBT", "Trenches", 17.6, first))
+
+	// The second call is recorded as its own row instead of overwriting the first
+	mock.ExpectExec("INSERT INTO ticker_mentions").
+		WithArgs("AIXBT", "0b5c1f9e-6f57-4d43-9d36-43c1d1f2c0aa", "Stats", 3, second, "", "", "").
+		WillReturnResult(sqlmock.NewResult(2, 1))
+
+	mock.ExpectQuery("SELECT author, tier, tweet_link").
+		WithArgs("AIXBT").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"author", "tier", "tweet_link", "content", "category", "tweet_id", "mentioned_at", "count",
+		}).AddRow("Stats", 3, "", "", "", "0b5c1f9e-6f57-4d43-9d36-43c1d1f2c0aa", second, 2))
+
+	mock.ExpectExec("UPDATE Tickers_1_0").
+		WithArgs(second, sqlmock.AnyArg(), "Trenches", "AIXBT").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	evaluator := &recordingEvaluator{}
+	s.SetAlertEvaluator(evaluator)
+
+	assert.NoError(t, s.InsertTicker(tk))
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// The alert rules see the score before and after the mention
+	require.Len(t, evaluator.changes, 1)
+	change := evaluator.changes[0]
+	assert.False(t, change.Created)
+	assert.Equal(t, 17.6, change.OldScore)
+	assert.Equal(t, "Trenches", change.OldCategory)
+	assert.Equal(t, "Trenches", change.NewCategory)
+	require.Len(t, change.Mentions, 1)
+	assert.Equal(t, second, change.Mentions[0].MentionedAt)
+}
+
+func TestGetTopTickers(t *testing.T) {
+	s, mock := newMockStorer(t)
+	defer s.db.Close()
+
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT ticker_symbol, category, mindshare_score, last_mentioned_at, first_mentioned_at").
+		WithArgs("EarlyAlpha", 10).
+		WillReturnRows(sqlmock.NewRows([]string{"ticker_symbol", "category", "mindshare_score", "last_mentioned_at", "first_mentioned_at"}).
+			AddRow("AIXBT", "High Alpha", 312.5, at, at.Add(-time.Hour)))
+
+	tickers, err := s.GetTopTickers("EarlyAlpha", 10)
+	require.NoError(t, err)
+	require.Len(t, tickers, 1)
+	assert.Equal(t, "AIXBT", tickers[0].TickerSymbol)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTickerNotFound(t *testing.T) {
+	s, mock := newMockStorer(t)
+	defer s.db.Close()
+
+	mock.ExpectQuery("SELECT ticker_symbol, category, mindshare_score, last_mentioned_at, first_mentioned_at FROM Tickers_1_0").
+		WithArgs("NOPE").WillReturnError(sql.ErrNoRows)
+
+	_, err := s.GetTicker("NOPE")
+	assert.ErrorIs(t, err, ErrTickerNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,106 @@
+package storer
+
+import (
+	"database/sql"
+	"errors"
+	"finowl-backend/pkg/alerts"
+	"finowl-backend/pkg/events"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// querier runs queries on the database or within a transaction
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// txAttempts bounds how many times a transaction is run when it conflicts
+// with a concurrent one
+const txAttempts = 4
+
+// txRetryDelay is the wait before the first retry, doubled after each attempt
+var txRetryDelay = 50 * time.Millisecond
+
+// txEffects collects the events and alert changes of a transaction. They are
+// only handed out once it committed, a rolled back change is never announced.
+type txEffects struct {
+	events  []events.Event
+	changes []alerts.Change
+}
+
+func (fx *txEffects) publish(e events.Event) {
+	fx.events = append(fx.events, e)
+}
+
+func (fx *txEffects) evaluateAlerts(c alerts.Change) {
+	fx.changes = append(fx.changes, c)
+}
+
+// apply publishes the events and evaluates the alerts of a committed transaction
+func (s *Storer) apply(fx *txEffects) {
+	for _, e := range fx.events {
+		s.publish(e)
+	}
+	for _, c := range fx.changes {
+		s.evaluateAlerts(c)
+	}
+}
+
+// inTx runs fn in a transaction and commits it. Transactions aborted by a
+// serialization failure or a deadlock are retried with an exponential
+// backoff, any other error rolls back and is returned as is.
+func (s *Storer) inTx(fn func(tx *sql.Tx, fx *txEffects) error) error {
+	delay := txRetryDelay
+	for attempt := 1; ; attempt++ {
+		fx := &txEffects{}
+		err := s.runTx(fx, fn)
+		if err == nil {
+			s.apply(fx)
+			return nil
+		}
+		if !isRetryable(err) || attempt == txAttempts {
+			return err
+		}
+
+		log.Printf("Retrying transaction in %v after attempt %d failed: %v", delay, attempt, err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// runTx runs a single attempt of a transaction
+func (s *Storer) runTx(fx *txEffects, fn func(tx *sql.Tx, fx *txEffects) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx, fx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// isRetryable reports whether an error aborted a transaction that succeeds
+// when run again
+func isRetryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	switch pqErr.Code {
+	case "40001", // serialization_failure
+		"40P01": // deadlock_detected
+		return true
+	}
+	return false
+}
@@ -0,0 +1,227 @@
+package storer
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"finowl-backend/pkg/events"
+	"finowl-backend/pkg/ticker"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleTweet(id string) Tweet {
+	return Tweet{ID: id, Author: "Stats", Timestamp: "2025-01-01T12:00:00Z", Content: "$AIXBT " + id, MessageID: "msg-" + id}
+}
+
+func TestStoreTweetNewTicker(t *testing.T) {
+	s, mock := newMockStorer(t)
+	defer s.db.Close()
+
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tweetID := "e33fbc74-74e8-447b-9c0b-d02771ff7495"
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO tweets").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO tweet_messages").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO Tickers_1_0").
+		WithArgs("AIXBT", "Trenches", 17.6, at, at).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO ticker_mentions").
+		WithArgs("AIXBT", tweetID, "Stats", 3, at, "", "", "").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	publisher := &recordingPublisher{}
+	s.SetEventPublisher(publisher)
+
+	require.NoError(t, s.StoreTweet(sampleTweet(tweetID), []ticker.Ticker{sampleMentionTicker("Stats", tweetID, at)}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+	require.Len(t, publisher.events, 2)
+	assert.Equal(t, events.TickerCreated, publisher.events[0].Type)
+}
+
+func TestStoreTweetRollsBackOnFailure(t *testing.T) {
+	s, mock := newMockStorer(t)
+	defer s.db.Close()
+
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tweetID := "e33fbc74-74e8-447b-9c0b-d02771ff7495"
+
+	// The tweet is not kept without its mentions
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO tweets").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO tweet_messages").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO Tickers_1_0").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO ticker_mentions").
+		WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	publisher := &recordingPublisher{}
+	s.SetEventPublisher(publisher)
+
+	err := s.StoreTweet(sampleTweet(tweetID), []ticker.Ticker{sampleMentionTicker("Stats", tweetID, at)})
+	assert.ErrorContains(t, err, "failed to insert/update ticker AIXBT")
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.Empty(t, publisher.events)
+
+	// Nor when the ticker it mentions cannot be rescored
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO tweets").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO tweet_messages").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO Tickers_1_0").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows([]string{"ticker_symbol", "category", "mindshare_score", "last_mentioned_at"}).
+			AddRow("AIXBT", "Trenches", 17.6, at))
+	mock.ExpectExec("INSERT INTO ticker_mentions").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT author, tier, tweet_link").
+		WillReturnRows(sqlmock.NewRows(mentionDetailColumns).AddRow("Stats", 9, "", "", "", tweetID, at, 1))
+	mock.ExpectRollback()
+
+	err = s.StoreTweet(sampleTweet(tweetID), []ticker.Ticker{sampleMentionTicker("Stats", tweetID, at)})
+	assert.ErrorContains(t, err, "failed to calculate mindshare of AIXBT")
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.Empty(t, publisher.events)
+
+	// A stored tweet records no mention
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO tweets").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT EXISTS").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	err = s.StoreTweet(sampleTweet(tweetID), []ticker.Ticker{sampleMentionTicker("Stats", tweetID, at)})
+	assert.ErrorIs(t, err, ErrTweetExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStoreTweetRetriesConflicts(t *testing.T) {
+	s, mock := newMockStorer(t)
+	defer s.db.Close()
+
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tweetID := "e33fbc74-74e8-447b-9c0b-d02771ff7495"
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO tweets").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO tweet_messages").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO Tickers_1_0").
+		WillReturnError(&pq.Error{Code: "40P01", Message: "deadlock detected"})
+	mock.ExpectRollback()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO tweets").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO tweet_messages").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO Tickers_1_0").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO ticker_mentions").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	publisher := &recordingPublisher{}
+	s.SetEventPublisher(publisher)
+
+	require.NoError(t, s.StoreTweet(sampleTweet(tweetID), []ticker.Ticker{sampleMentionTicker("Stats", tweetID, at)}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// Only the committed attempt is announced
+	assert.Len(t, publisher.events, 2)
+}
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, isRetryable(&pq.Error{Code: "40001"}))
+	assert.True(t, isRetryable(fmt.Errorf("failed to insert/update ticker AIXBT: %w", &pq.Error{Code: "40P01"})))
+	assert.False(t, isRetryable(&pq.Error{Code: "23505"}))
+	assert.False(t, isRetryable(errors.New("connection refused")))
+}
+
+func TestStoreTweetConcurrentMentions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	s := &Storer{db: db}
+
+	// Transactions run concurrently, their statements interleave
+	mock.MatchExpectationsInOrder(false)
+
+	const tweets = 20
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Every tweet mentions AIXBT for the first time: one of them creates it,
+	// the others lock its row and rescore it
+	for i := 0; i < tweets; i++ {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO tweets").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO tweet_messages").WillReturnResult(sqlmock.NewResult(0, 1))
+		created := int64(0)
+		if i == 0 {
+			created = 1
+		} else {
+			mock.ExpectQuery("FOR UPDATE").
+				WillReturnRows(sqlmock.NewRows([]string{"ticker_symbol", "category", "mindshare_score", "last_mentioned_at"}).
+					AddRow("AIXBT", "Trenches", 17.6, at))
+			mock.ExpectQuery("SELECT author, tier, tweet_link").WillReturnRows(sqlmock.NewRows(mentionDetailColumns).
+				AddRow("Stats", 3, "", "", "", "tweet-0", at, tweets))
+			mock.ExpectExec("UPDATE Tickers_1_0").WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+		mock.ExpectExec("INSERT INTO Tickers_1_0").WillReturnResult(sqlmock.NewResult(0, created))
+		mock.ExpectExec("INSERT INTO ticker_mentions").
+			WithArgs("AIXBT", fmt.Sprintf("tweet-%d", i), "Stats", 3, at, "", "", "").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+	}
+
+	publisher := &recordingPublisher{}
+	s.SetEventPublisher(publisher)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, tweets)
+	for i := 0; i < tweets; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id := fmt.Sprintf("tweet-%d", i)
+			errs <- s.StoreTweet(sampleTweet(id), []ticker.Ticker{sampleMentionTicker("Stats", id, at)})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// Every mention was recorded and announced, the ticker created once
+	stored, created := 0, 0
+	for _, e := range publisher.events {
+		switch e.Type {
+		case events.MentionStored:
+			stored++
+		case events.TickerCreated:
+			created++
+		}
+	}
+	assert.Equal(t, tweets, stored)
+	assert.Equal(t, 1, created)
+}
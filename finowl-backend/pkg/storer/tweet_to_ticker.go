@@ -56,9 +56,13 @@ func createMentionDetails(author string, tier int, tweet Tweet) ticker.MentionDe
 	mentionDetails := ticker.MentionDetails{
 		Influencers: map[string]ticker.MentionDetail{
 			author: {
-				Tier:      tier,                      // Default tier value
-				TweetLink: getFirstLink(tweet.Links), // Get the first link from the tweet's links
-				Content:   tweet.Content,
+				Tier:         tier,                      // Default tier value
+				TweetLink:    getFirstLink(tweet.Links), // Get the first link from the tweet's links
+				Content:      tweet.Content,
+				Category:     tweet.Category,
+				TweetID:      tweet.ID,
+				MentionedAt:  parseTimestamp(tweet.Timestamp),
+				MentionCount: 1,
 			},
 		},
 	}
@@ -16,14 +16,77 @@ type Ticker struct {
 	MentionDetails   MentionDetails `json:"mention_details"`
 }
 
-// MentionDetail represents the details of a mention for a specific influencer
+// MentionDetail represents the details of a mention for a specific influencer.
+// When an influencer mentioned a ticker several times it describes the latest
+// mention, and MentionCount holds the total.
 type MentionDetail struct {
-	Tier      int    `json:"tier"`
-	TweetLink string `json:"tweet_link"`
-	Content   string `json:"content"`
+	Tier         int       `json:"tier"`
+	TweetLink    string    `json:"tweet_link"`
+	Content      string    `json:"content"`
+	Category     string    `json:"category"` // Channel category the mention was collected from
+	TweetID      string    `json:"tweet_id"`
+	MentionedAt  time.Time `json:"mentioned_at"`
+	MentionCount int       `json:"mention_count"`
+
+	// MentionTimes holds when every mention was made, each one decays on its
+	// own. Scoring falls back to MentionedAt when it is empty.
+	MentionTimes []time.Time `json:"-"`
+}
+
+// AddMention folds a mention of an influencer into the details, which keep
+// describing the latest mention of every influencer
+func (m MentionDetails) AddMention(author string, mention MentionDetail) {
+	existing, ok := m.Influencers[author]
+	times := append(existing.MentionTimes, mention.MentionedAt)
+	if !ok || mention.MentionedAt.After(existing.MentionedAt) {
+		existing = mention
+	}
+	existing.MentionTimes = times
+	m.Influencers[author] = existing
 }
 
 // MentionDetails represents the overall mention details structure
 type MentionDetails struct {
 	Influencers map[string]MentionDetail `json:"influencers"`
 }
+
+// Mention is a single mention of a ticker by an influencer
+type Mention struct {
+	Author      string    `json:"author"`
+	Tier        int       `json:"tier"`
+	TweetLink   string    `json:"tweet_link"`
+	Content     string    `json:"content"`
+	Category    string    `json:"category"` // Channel category the mention was collected from
+	TweetID     string    `json:"tweet_id"`
+	MentionedAt time.Time `json:"mentioned_at"`
+}
+
+// TierBreakdown counts the influencers of one tier that mentioned a ticker and their mentions
+type TierBreakdown struct {
+	Tier        int `json:"tier"`
+	Influencers int `json:"influencers"`
+	Mentions    int `json:"mentions"`
+}
+
+// Momentum compares the mentions of a ticker in a trailing window with the
+// preceding window. Velocities are in mentions per hour.
+type Momentum struct {
+	Mentions            int     `json:"mentions"`
+	Influencers         int     `json:"influencers"`
+	PreviousMentions    int     `json:"previous_mentions"`
+	PreviousInfluencers int     `json:"previous_influencers"`
+	Velocity            float64 `json:"velocity"`
+	PreviousVelocity    float64 `json:"previous_velocity"`
+	Acceleration        float64 `json:"acceleration"`
+	Score               float64 `json:"score"`
+}
+
+// Snapshot is the state of a ticker over one history bucket: its score and
+// category as last recorded in the bucket, and the mentions made during it
+type Snapshot struct {
+	BucketStart     time.Time `json:"bucket_start"`
+	MindshareScore  float64   `json:"mindshare_score"`
+	Category        string    `json:"category"`
+	MentionCount    int       `json:"mention_count"`
+	InfluencerCount int       `json:"influencer_count"`
+}